@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/cassette"
+)
+
+// setupCassette makes providers record their HTTP traffic to recordFile or
+// answer requests from replayFile. It does nothing if neither is set.
+func setupCassette(recordFile, replayFile string) error {
+	if recordFile != "" && replayFile != "" {
+		return newUsageError(fmt.Errorf("--record and --replay cannot be used together"))
+	}
+	switch {
+	case recordFile != "":
+		recorder, err := cassette.NewRecorder(recordFile, nil)
+		if err != nil {
+			return err
+		}
+		llmc.SetTransportOverride(recorder)
+	case replayFile != "":
+		replayer, err := cassette.NewReplayer(replayFile)
+		if err != nil {
+			return err
+		}
+		llmc.SetTransportOverride(replayer)
+	}
+	return nil
+}
@@ -4,12 +4,18 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
+	"time"
 
+	"github.com/chzyer/readline"
 	"github.com/longkey1/llmc/internal/llmc"
 	"github.com/longkey1/llmc/internal/llmc/config"
 	promptpkg "github.com/longkey1/llmc/internal/llmc/prompt"
@@ -28,6 +34,39 @@ var (
 	newSession      bool
 	sessionName     string
 	ignoreThreshold bool
+	noFallback      bool
+	allowEmptyInput bool
+	stdinAs         string
+	outputFormat    string
+	onlyCode        bool
+	firstBlock      bool
+	autoSummarize   bool
+	echoRequest     bool
+	newlineArgs     bool
+	listArgs        bool
+	stdinTemplate   bool
+	seedRole        string
+	prefill         string
+	includePrefill  bool
+	showUsage       bool
+	noNewline       bool
+	pretty          bool
+	toolsFile       string
+	inputFile       string
+	useClipboard    bool
+	chatJSON        bool
+	renderOutput    bool
+	systemFlag      string
+	systemFile      string
+	repeatCount     int
+	pickBest        bool
+	reapplyTemplate bool
+)
+
+// Output formats for the chat command
+const (
+	outputText   = "text"   // Print the response text when complete
+	outputNDJSON = "ndjson" // Stream response events as JSON lines
 )
 
 // chatCmd represents the chat command
@@ -40,7 +79,12 @@ This command performs a one-time API call to the specified LLM provider.
 For interactive multi-turn conversations, use 'llmc sessions start' instead.
 
 If no message is provided as an argument, it reads from stdin.
+With --stdin-template, the {{placeholders}} in the message read from stdin are
+replaced with the --arg values, without a prompt template file.
+Multiple arguments are joined with spaces (with newlines when --newline-args is set).
+Empty input is rejected unless a prompt template is used with --allow-empty-input.
 If --editor flag is set, it opens the default editor (from EDITOR environment variable) to compose the message.
+With --clipboard, the message is read from the system clipboard (pbpaste, wl-paste, xclip or xsel).
 
 You can specify the provider, model, and prompt using flags.
 If not specified, the values will be taken from the configuration file.
@@ -49,213 +93,409 @@ The prompt file should be in TOML format with the following structure:
 system = "System prompt with optional {{input}} placeholder"
 user = "User prompt with optional {{input}} placeholder"
 model = "optional-model-name"  # Optional: overrides the default model for this prompt
-web_search = true  # Optional: enables web search for this prompt"`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Load configuration from file
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+web_search = true  # Optional: enables web search for this prompt
+temperature = 0.2  # Optional: sampling parameters (also top_p and max_tokens), overriding the config"`,
+	RunE: runChat,
+}
+
+// runChat sends the message to the LLM and prints the response.
+// It is shared by chat and prompts run.
+// With --json, an error is also written to stdout as a JSON object.
+func runChat(cmd *cobra.Command, args []string) error {
+	err := sendChat(cmd, args)
+	if err != nil && chatJSON {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		if writeErr := writeChatJSONError(os.Stdout, err); writeErr != nil {
+			return writeErr
 		}
+	}
+	return err
+}
+
+// sendChat implements runChat
+func sendChat(cmd *cobra.Command, args []string) error {
+	// Load configuration from file
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
 
-		// Validate session flags
-		if sessionID != "" && newSession {
-			return fmt.Errorf("cannot specify both --session and --new-session")
+	// List the template arguments instead of sending a message
+	if listArgs {
+		if prompt == "" {
+			return newUsageError(fmt.Errorf("--list-args requires --prompt"))
 		}
+		return listPromptArgs(os.Stdout, prompt, cfg.PromptDirs)
+	}
 
-		// Cannot use prompt with existing session
-		if sessionID != "" && prompt != "" {
-			return fmt.Errorf("cannot use --prompt with existing session")
+	// Validate output format
+	if outputFormat != outputText && outputFormat != outputNDJSON {
+		return newUsageError(fmt.Errorf("invalid --output '%s' (must be %s or %s)", outputFormat, outputText, outputNDJSON))
+	}
+	if (onlyCode || firstBlock) && outputFormat == outputNDJSON {
+		return newUsageError(fmt.Errorf("--only-code cannot be used with --output %s", outputNDJSON))
+	}
+	if noNewline && outputFormat == outputNDJSON {
+		return newUsageError(fmt.Errorf("--no-newline cannot be used with --output %s", outputNDJSON))
+	}
+	if pretty && outputFormat == outputNDJSON {
+		return newUsageError(fmt.Errorf("--pretty cannot be used with --output %s", outputNDJSON))
+	}
+	if toolsFile != "" && outputFormat == outputNDJSON {
+		return newUsageError(fmt.Errorf("--tools cannot be used with --output %s", outputNDJSON))
+	}
+	if chatJSON && (outputFormat == outputNDJSON || pretty) {
+		return newUsageError(fmt.Errorf("--json cannot be used with --output %s or --pretty", outputNDJSON))
+	}
+	if renderOutput && (outputFormat == outputNDJSON || chatJSON || onlyCode || firstBlock) {
+		return newUsageError(fmt.Errorf("--render cannot be used with --output %s, --json or --only-code", outputNDJSON))
+	}
+	samplingFlags, err := samplingFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Validate repeat flags (repeated requests are independent one-off messages)
+	if repeatCount < 1 {
+		return newUsageError(fmt.Errorf("invalid --repeat %d (must be at least 1)", repeatCount))
+	}
+	if pickBest && repeatCount < 2 {
+		return newUsageError(fmt.Errorf("--best requires --repeat with 2 or more"))
+	}
+	if repeatCount > 1 {
+		if sessionID != "" || newSession {
+			return newUsageError(fmt.Errorf("--repeat cannot be used with --session or --new-session"))
 		}
+		if outputFormat == outputNDJSON || toolsFile != "" || pretty || noNewline {
+			return newUsageError(fmt.Errorf("--repeat cannot be used with --output %s, --tools, --pretty or --no-newline", outputNDJSON))
+		}
+	}
 
-		// Get message from arguments, editor, or stdin
-		var message string
-		if useEditor {
-			message, err = getMessageFromEditor()
-			if err != nil {
-				return fmt.Errorf("getting message from editor: %w", err)
-			}
-		} else if len(args) > 0 {
-			message = strings.Join(args, " ")
-		} else {
-			// Read from stdin
-			input, err := io.ReadAll(os.Stdin)
-			if err != nil {
-				return fmt.Errorf("reading from stdin: %w", err)
-			}
-			message = strings.TrimSpace(string(input))
+	// Validate prefill flags
+	if includePrefill && prefill == "" {
+		return newUsageError(fmt.Errorf("--include-prefill requires --prefill"))
+	}
+	if includePrefill && outputFormat == outputNDJSON {
+		return newUsageError(fmt.Errorf("--include-prefill cannot be used with --output %s", outputNDJSON))
+	}
+
+	// Validate system prompt flags
+	if systemFlag != "" || systemFile != "" {
+		if systemFlag != "" && systemFile != "" {
+			return newUsageError(fmt.Errorf("cannot specify both --system and --system-file"))
 		}
+		if prompt != "" || sessionID != "" {
+			return newUsageError(fmt.Errorf("--system and --system-file cannot be used with --prompt or --session (they have their own system prompt)"))
+		}
+	}
 
-		// Determine session mode
-		var sess *session.Session
-		var systemPrompt string
-		var isNewSession bool
+	// Validate session flags
+	if sessionID != "" && newSession {
+		return newUsageError(fmt.Errorf("cannot specify both --session and --new-session"))
+	}
 
-		if sessionID != "" {
-			// Load existing session
-			sess, err = session.FindSessionByPrefix(sessionID)
-			if err != nil {
-				return fmt.Errorf("finding session: %w", err)
-			}
+	// Cannot use prompt with existing session
+	if sessionID != "" && prompt != "" {
+		return newUsageError(fmt.Errorf("cannot use --prompt with existing session"))
+	}
 
-			// Check message threshold
-			threshold := cfg.SessionMessageThreshold
-			if threshold > 0 && sess.MessageCount() >= threshold && !ignoreThreshold {
-				fmt.Fprintf(os.Stderr, "\nWarning: Session %s has %d messages (threshold: %d).\n",
-					sess.GetShortID(), sess.MessageCount(), threshold)
-				fmt.Fprintf(os.Stderr, "Long sessions may impact performance and token usage.\n")
-				fmt.Fprintf(os.Stderr, "\nOptions:\n")
-				fmt.Fprintf(os.Stderr, "  1. Continue anyway with --ignore-threshold flag\n")
-				fmt.Fprintf(os.Stderr, "  2. Summarize session: llmc sessions summarize %s\n", sess.GetShortID())
-				fmt.Fprintf(os.Stderr, "  3. Start a new session: llmc chat --new-session\n\n")
-
-				// Ask for confirmation
-				fmt.Fprint(os.Stderr, "Continue with this session? [y/N]: ")
-				var response string
-				fmt.Scanln(&response)
-
-				if response != "y" && response != "Y" {
-					fmt.Fprintln(os.Stderr, "Cancelled.")
-					return nil
-				}
-			}
+	// Reapplying the template wraps a turn of an existing session
+	if reapplyTemplate && sessionID == "" {
+		return newUsageError(fmt.Errorf("--reapply-template requires --session"))
+	}
 
-			// Use session's system prompt and model
-			systemPrompt = sess.SystemPrompt
-			cfg.Model = sess.Model
+	// Auto-summarize retries a turn of an existing session
+	if autoSummarize && sessionID == "" {
+		return newUsageError(fmt.Errorf("--auto-summarize requires --session"))
+	}
+	if autoSummarize && outputFormat == outputNDJSON {
+		return newUsageError(fmt.Errorf("--auto-summarize cannot be used with --output %s", outputNDJSON))
+	}
 
-			if verbose {
-				fmt.Fprintf(os.Stderr, "Continuing session: %s\n", sess.GetShortID())
-				fmt.Fprintf(os.Stderr, "Model: %s\n", sess.Model)
-				if systemPrompt != "" {
-					fmt.Fprintf(os.Stderr, "System prompt: %s\n", systemPrompt)
-				}
-			}
-		} else if newSession {
-			// Create new session
-			isNewSession = true
-
-			// Format message with prompt if specified
-			var formattedMessage string
-			var promptModel *string
-			var promptWebSearch *bool
-			if prompt != "" {
-				formattedMessage, promptModel, promptWebSearch, err = promptpkg.FormatMessage(message, prompt, cfg.PromptDirs, argFlags)
-				if err != nil {
-					return fmt.Errorf("formatting message with prompt: %w", err)
-				}
+	// A message with another role than user only seeds a new session
+	if seedRole != "user" {
+		if seedRole != "assistant" {
+			return newUsageError(fmt.Errorf("invalid --role '%s' (must be user or assistant)", seedRole))
+		}
+		if !newSession {
+			return newUsageError(fmt.Errorf("--role %s requires --new-session", seedRole))
+		}
+		if prompt != "" || outputFormat == outputNDJSON {
+			return newUsageError(fmt.Errorf("--role %s cannot be used with --prompt or --output %s", seedRole, outputNDJSON))
+		}
+	}
 
-				// Extract system prompt from formatted message
-				if strings.HasPrefix(formattedMessage, "System: ") {
-					parts := strings.SplitN(formattedMessage, "\n\nUser: ", 2)
-					if len(parts) == 2 {
-						systemPrompt = strings.TrimPrefix(parts[0], "System: ")
-						message = parts[1] // Use formatted user message
-					}
-				}
+	// The message template is read from stdin
+	if stdinTemplate {
+		if prompt != "" || stdinAs != "" {
+			return newUsageError(fmt.Errorf("--stdin-template cannot be used with --prompt or --stdin-as"))
+		}
+		if useEditor || len(args) > 0 || inputFile != "" || useClipboard {
+			return newUsageError(fmt.Errorf("--stdin-template reads the message from stdin and cannot be used with --editor, --input-file, --clipboard or a message argument"))
+		}
+	}
+	if useEditor && inputFile != "" {
+		return newUsageError(fmt.Errorf("cannot specify both --editor and --input-file"))
+	}
+	if useClipboard && (useEditor || inputFile != "") {
+		return newUsageError(fmt.Errorf("--clipboard cannot be used with --editor or --input-file"))
+	}
 
-				// Apply model from prompt template
-				if promptModel != nil {
-					if _, _, err := llmc.ParseModelString(*promptModel); err != nil {
-						return fmt.Errorf("invalid model from prompt file: %w", err)
-					}
-					cfg.Model = *promptModel
-					if verbose {
-						fmt.Fprintf(os.Stderr, "Using model from prompt file: %s\n", cfg.Model)
-					}
-				}
+	// Get message from arguments, editor, input file, clipboard, or stdin (in this order of precedence)
+	var message string
+	if useEditor {
+		message, err = getMessageFromEditor()
+		if err != nil {
+			return fmt.Errorf("getting message from editor: %w", err)
+		}
+	} else if len(args) > 0 {
+		message = joinArgs(args, newlineArgs)
+	} else if inputFile != "" {
+		message, err = readInputFile(inputFile)
+		if err != nil {
+			return err
+		}
+	} else if useClipboard {
+		message, err = readClipboard(clipboardCommands())
+		if err != nil {
+			return err
+		}
+	} else if stdinAs == "" && !stdinTemplate {
+		// Read from stdin
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading from stdin: %w", err)
+		}
+		message = strings.TrimSpace(string(input))
+	}
 
-				// Apply web search from prompt template
-				if promptWebSearch != nil && !cmd.Flags().Changed("web-search") {
-					cfg.EnableWebSearch = *promptWebSearch
-				}
-			}
+	// Parse template arguments, binding piped stdin to a named key if requested
+	templateArgs, err := promptpkg.ParseArgs(argFlags)
+	if err != nil {
+		return newUsageError(fmt.Errorf("parsing arguments: %w", err))
+	}
+	if stdinAs != "" {
+		if err := bindStdinArg(os.Stdin, stdinAs, prompt, templateArgs); err != nil {
+			return err
+		}
+	}
+	if stdinTemplate {
+		message, err = applyStdinTemplate(os.Stdin, templateArgs)
+		if err != nil {
+			return err
+		}
+	}
+	flagSystemPrompt, err := resolveSystemPrompt(systemFlag, systemFile, templateArgs)
+	if err != nil {
+		return err
+	}
 
-			// Apply model with priority: flag > env > prompt template > config file
-			envModel := os.Getenv("LLMC_MODEL")
-			if cmd.Flags().Changed("model") {
-				if _, _, err := llmc.ParseModelString(model); err != nil {
-					return fmt.Errorf("invalid model from flag: %w", err)
-				}
-				cfg.Model = model
-			} else if envModel != "" {
-				if _, _, err := llmc.ParseModelString(envModel); err != nil {
-					return fmt.Errorf("invalid model from environment: %w", err)
-				}
-				cfg.Model = envModel
+	// Reject empty input before contacting the provider
+	// ({{input}} can be intentionally empty when stdin is bound to another key)
+	if err := validateMessage(message, prompt, allowEmptyInput || stdinAs != ""); err != nil {
+		return err
+	}
+
+	// Determine session mode
+	var sess *session.Session
+	var systemPrompt string
+	var isNewSession bool
+
+	if sessionID != "" {
+		// Load existing session
+		sess, err = session.FindSessionByPrefix(sessionID)
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		// Check message threshold
+		threshold := cfg.SessionMessageThreshold
+		if threshold > 0 && sess.MessageCount() >= threshold && !ignoreThreshold {
+			fmt.Fprintf(os.Stderr, "\nWarning: Session %s has %d messages (threshold: %d).\n",
+				sess.GetShortID(), sess.MessageCount(), threshold)
+			fmt.Fprintf(os.Stderr, "Long sessions may impact performance and token usage.\n")
+			fmt.Fprintf(os.Stderr, "\nOptions:\n")
+			fmt.Fprintf(os.Stderr, "  1. Continue anyway with --ignore-threshold flag\n")
+			fmt.Fprintf(os.Stderr, "  2. Summarize session: llmc sessions summarize %s\n", sess.GetShortID())
+			fmt.Fprintf(os.Stderr, "  3. Start a new session: llmc chat --new-session\n\n")
+
+			// Ask for confirmation
+			fmt.Fprint(os.Stderr, "Continue with this session? [y/N]: ")
+			var response string
+			fmt.Scanln(&response)
+
+			if response != "y" && response != "Y" {
+				fmt.Fprintln(os.Stderr, "Cancelled.")
+				return nil
 			}
+		}
 
-			// Create new session
-			sess = session.NewSession(cfg.Model)
-			sess.Name = sessionName
-			sess.TemplateName = prompt
-			sess.SystemPrompt = systemPrompt
-
-			if verbose {
-				fmt.Fprintf(os.Stderr, "Creating new session: %s\n", sess.GetShortID())
-				fmt.Fprintf(os.Stderr, "Model: %s\n", sess.Model)
-				if systemPrompt != "" {
-					fmt.Fprintf(os.Stderr, "System prompt: %s\n", systemPrompt)
-				}
+		// Use session's system prompt and model (or a replacement when the stored one is invalid)
+		systemPrompt = sess.SystemPrompt
+		modelOverride := ""
+		if cmd.Flags().Changed("model") {
+			modelOverride = cfg.QualifyModel(model)
+		}
+		cfg.Model, err = resolveSessionModel(sess, modelOverride, os.Stdin, os.Stderr, readline.IsTerminal(int(os.Stdin.Fd())))
+		if err != nil {
+			return err
+		}
+
+		// Apply session default arguments, overridden by per-turn --arg values.
+		// With --reapply-template, the message is wrapped in the session's template like the first turn.
+		sessionArgs := promptpkg.MergeArgs(sess.DefaultArgs, templateArgs)
+		if reapplyTemplate && sess.TemplateName != "" {
+			message, err = templateUserMessage(message, sess.TemplateName, cfg.PromptDirs, sessionArgs)
+			if err != nil {
+				return fmt.Errorf("reapplying template: %w", err)
 			}
 		} else {
-			// Single-shot mode (no session)
-			formattedMessage, promptModel, promptWebSearch, err := promptpkg.FormatMessage(message, prompt, cfg.PromptDirs, argFlags)
+			if reapplyTemplate {
+				fmt.Fprintf(os.Stderr, "Notice: session %s was not created from a template, --reapply-template is ignored\n", sess.GetShortID())
+			}
+			message = promptpkg.ApplyArgs(message, sessionArgs)
+		}
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Continuing session: %s\n", sess.GetShortID())
+			fmt.Fprintf(os.Stderr, "Model: %s\n", cfg.Model)
+			if systemPrompt != "" {
+				fmt.Fprintf(os.Stderr, "System prompt: %s\n", systemPrompt)
+			}
+		}
+	} else if newSession {
+		// Create new session
+		isNewSession = true
+
+		// Format message with prompt if specified
+		var formattedMessage string
+		var promptModel *string
+		var promptWebSearch *bool
+		var promptSampling llmc.SamplingParams
+		if prompt != "" {
+			formattedMessage, promptModel, promptWebSearch, promptSampling, err = promptpkg.FormatMessageWithArgs(message, prompt, cfg.PromptDirs, templateArgs)
 			if err != nil {
 				return fmt.Errorf("formatting message with prompt: %w", err)
 			}
 
-			// Apply model priority
-			envModel := os.Getenv("LLMC_MODEL")
-			if cmd.Flags().Changed("model") {
-				if _, _, err := llmc.ParseModelString(model); err != nil {
-					return fmt.Errorf("invalid model from flag: %w", err)
-				}
-				cfg.Model = model
-			} else if envModel != "" {
-				if _, _, err := llmc.ParseModelString(envModel); err != nil {
-					return fmt.Errorf("invalid model from environment: %w", err)
-				}
-				cfg.Model = envModel
-			} else if promptModel != nil {
+			// Extract system prompt from formatted message
+			if system, user, ok := splitFormattedMessage(formattedMessage); ok {
+				systemPrompt = system
+				message = user // Use formatted user message
+			}
+
+			// Apply model from prompt template
+			if promptModel != nil {
 				if _, _, err := llmc.ParseModelString(*promptModel); err != nil {
 					return fmt.Errorf("invalid model from prompt file: %w", err)
 				}
 				cfg.Model = *promptModel
+				if verbose {
+					fmt.Fprintf(os.Stderr, "Using model from prompt file: %s\n", cfg.Model)
+				}
 			}
 
-			// Select provider
-			llmProvider, err := newProvider(cfg)
-			if err != nil {
-				return fmt.Errorf("creating provider: %w", err)
+			// Apply web search from prompt template
+			if promptWebSearch != nil && !cmd.Flags().Changed("web-search") {
+				cfg.EnableWebSearch = *promptWebSearch
 			}
+		}
+
+		if flagSystemPrompt != "" {
+			systemPrompt = flagSystemPrompt
+		}
 
-			// Configure web search
-			enableWebSearch := cfg.EnableWebSearch
-			envWebSearch := os.Getenv("LLMC_ENABLE_WEB_SEARCH")
-			if cmd.Flags().Changed("web-search") {
-				enableWebSearch = webSearch
-			} else if envWebSearch != "" {
-				enableWebSearch = envWebSearch == "true" || envWebSearch == "1"
-			} else if promptWebSearch != nil {
-				enableWebSearch = *promptWebSearch
+		// Apply model with priority: flag > env > prompt template > config file
+		envModel := cfg.QualifyModel(os.Getenv("LLMC_MODEL"))
+		if cmd.Flags().Changed("model") {
+			model = cfg.QualifyModel(model)
+			if _, _, err := llmc.ParseModelString(model); err != nil {
+				return fmt.Errorf("invalid model from flag: %w", err)
 			}
-			llmProvider.SetWebSearch(enableWebSearch)
-			llmProvider.SetDebug(verbose)
+			cfg.Model = model
+		} else if envModel != "" {
+			if _, _, err := llmc.ParseModelString(envModel); err != nil {
+				return fmt.Errorf("invalid model from environment: %w", err)
+			}
+			cfg.Model = envModel
+		}
+
+		// Create new session
+		sess = session.NewSession(cfg.Model)
+		sess.Name = sessionName
+		sess.TemplateName = prompt
+		sess.SystemPrompt = systemPrompt
+
+		// Keep the sampling parameters so that later turns reuse them (flags > prompt template > config file)
+		sampling := resolveSampling(cfg, nil, promptSampling.Merge(samplingFlags))
+		sess.Sampling = &sampling
 
-			// Send message and print response
-			response, err := llmProvider.Chat(formattedMessage)
+		// Remember template arguments so that later turns can reuse them
+		if prompt != "" && len(argFlags) > 0 {
+			sess.DefaultArgs, err = promptpkg.ParseArgs(argFlags)
 			if err != nil {
-				return fmt.Errorf("chat request failed: %w", err)
+				return fmt.Errorf("parsing arguments: %w", err)
 			}
-			fmt.Println(response)
+		}
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Creating new session: %s\n", sess.GetShortID())
+			fmt.Fprintf(os.Stderr, "Model: %s\n", sess.Model)
+			if systemPrompt != "" {
+				fmt.Fprintf(os.Stderr, "System prompt: %s\n", systemPrompt)
+			}
+		}
+
+		// Save the message as the first turn without sending it
+		if seedRole != "user" {
+			if err := seedSession(sess, cfg, seedRole, message); err != nil {
+				return newUsageError(err)
+			}
+			if err := session.SaveSession(sess); err != nil {
+				return fmt.Errorf("saving session: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Session created with an %s message: %s\n", seedRole, sess.GetShortID())
+			fmt.Fprintf(os.Stderr, "\nContinue with:\n  llmc chat -s %s \"your message\"\n", sess.GetShortID())
 			return nil
 		}
+	} else {
+		// Single-shot mode (no session)
+		formattedMessage, promptModel, promptWebSearch, promptSampling, err := promptpkg.FormatMessageWithArgs(message, prompt, cfg.PromptDirs, templateArgs)
+		if err != nil {
+			return fmt.Errorf("formatting message with prompt: %w", err)
+		}
+
+		// Apply model priority
+		envModel := cfg.QualifyModel(os.Getenv("LLMC_MODEL"))
+		if cmd.Flags().Changed("model") {
+			model = cfg.QualifyModel(model)
+			if _, _, err := llmc.ParseModelString(model); err != nil {
+				return fmt.Errorf("invalid model from flag: %w", err)
+			}
+			cfg.Model = model
+		} else if envModel != "" {
+			if _, _, err := llmc.ParseModelString(envModel); err != nil {
+				return fmt.Errorf("invalid model from environment: %w", err)
+			}
+			cfg.Model = envModel
+		} else if promptModel != nil {
+			if _, _, err := llmc.ParseModelString(*promptModel); err != nil {
+				return fmt.Errorf("invalid model from prompt file: %w", err)
+			}
+			cfg.Model = *promptModel
+		}
 
 		// Select provider
-		llmProvider, err := newProvider(cfg)
+		llmProvider, err := newChatProvider(cfg, noFallback)
 		if err != nil {
 			return fmt.Errorf("creating provider: %w", err)
 		}
+		if echoRequest {
+			if llmProvider, err = withEcho(cfg, llmProvider, os.Stderr); err != nil {
+				return fmt.Errorf("creating provider: %w", err)
+			}
+		}
 
 		// Configure web search
 		enableWebSearch := cfg.EnableWebSearch
@@ -264,44 +504,209 @@ web_search = true  # Optional: enables web search for this prompt"`,
 			enableWebSearch = webSearch
 		} else if envWebSearch != "" {
 			enableWebSearch = envWebSearch == "true" || envWebSearch == "1"
+		} else if promptWebSearch != nil {
+			enableWebSearch = *promptWebSearch
 		}
 		llmProvider.SetWebSearch(enableWebSearch)
 		llmProvider.SetDebug(verbose)
+		llmProvider.SetTimeout(resolveTimeout(cfg))
+		llmProvider.SetSampling(resolveSampling(cfg, nil, promptSampling.Merge(samplingFlags)))
+		if err := applyPrefill(llmProvider, cfg); err != nil {
+			return err
+		}
+		if err := applyTools(llmProvider, cfg); err != nil {
+			return err
+		}
 
-		// Session mode: add message to session
-		sess.AddMessage("user", message)
+		// Ctrl+C aborts the request instead of killing the process
+		ctx, stop := interruptContext()
+		defer stop()
 
-		// Send message with history (exclude the last message which was just added)
-		historyMessages := sess.Messages[:len(sess.Messages)-1]
+		// Send the same message several times and print all responses or the best one
+		if repeatCount > 1 {
+			results, err := repeatChat(ctx, llmProvider, flagSystemPrompt, formattedMessage, cfg.Model, repeatCount)
+			if err != nil {
+				return chatError(err)
+			}
+			if !pickBest {
+				return writeRepeatedResponses(os.Stdout, results)
+			}
+			best, err := pickBestResponse(ctx, llmProvider, formattedMessage, results)
+			if err != nil {
+				return chatError(fmt.Errorf("picking the best response: %w", err))
+			}
+			fmt.Fprintf(os.Stderr, "Picked response %d of %d\n", best+1, len(results))
+			if chatJSON {
+				results[best].Response = extractResponse(results[best].Response)
+				return writeJSON(os.Stdout, results[best], compactJSON)
+			}
+			printResponse(os.Stdout, results[best].Response)
+			return nil
+		}
 
-		response, err := llmProvider.ChatWithHistory(sess.SystemPrompt, historyMessages, message)
+		// Stream response events
+		if outputFormat == outputNDJSON {
+			if _, err := streamNDJSON(ctx, os.Stdout, llmProvider, flagSystemPrompt, nil, formattedMessage); err != nil {
+				return chatError(err)
+			}
+			if showUsage {
+				writeUsage(os.Stderr, llmProvider)
+			}
+			return nil
+		}
 
+		// Send message and print response
+		start := time.Now()
+		var response string
+		if flagSystemPrompt != "" {
+			response, err = llmProvider.ChatWithHistoryContext(ctx, flagSystemPrompt, nil, formattedMessage)
+		} else {
+			response, err = llmProvider.ChatContext(ctx, formattedMessage)
+		}
 		if err != nil {
-			return fmt.Errorf("chat request failed: %w", err)
+			return chatError(err)
+		}
+		if chatJSON {
+			if err := writeChatJSON(os.Stdout, withPrefill(response), llmc.ResponseModel(llmProvider, cfg.Model), llmProvider, ""); err != nil {
+				return err
+			}
+		} else if calls := llmc.LastToolCalls(llmProvider); len(calls) > 0 {
+			if err := writeJSON(os.Stdout, calls, compactJSON); err != nil {
+				return err
+			}
+		} else {
+			writeResponse(os.Stdout, withPrefill(response), llmc.ResponseModel(llmProvider, cfg.Model), time.Since(start))
+		}
+		if showUsage {
+			writeUsage(os.Stderr, llmProvider)
 		}
+		return nil
+	}
 
-		// Add assistant response to session
-		sess.AddMessage("assistant", response)
+	// Select provider
+	llmProvider, err := newChatProvider(cfg, noFallback)
+	if err != nil {
+		return fmt.Errorf("creating provider: %w", err)
+	}
+	if echoRequest {
+		if llmProvider, err = withEcho(cfg, llmProvider, os.Stderr); err != nil {
+			return fmt.Errorf("creating provider: %w", err)
+		}
+	}
 
-		// Save session
-		if err := session.SaveSession(sess); err != nil {
-			return fmt.Errorf("saving session: %w", err)
+	// Configure web search
+	enableWebSearch := cfg.EnableWebSearch
+	envWebSearch := os.Getenv("LLMC_ENABLE_WEB_SEARCH")
+	if cmd.Flags().Changed("web-search") {
+		enableWebSearch = webSearch
+	} else if envWebSearch != "" {
+		enableWebSearch = envWebSearch == "true" || envWebSearch == "1"
+	}
+	llmProvider.SetWebSearch(enableWebSearch)
+	llmProvider.SetDebug(verbose)
+	llmProvider.SetTimeout(resolveTimeout(cfg))
+	llmProvider.SetSampling(resolveSampling(cfg, sess, samplingFlags))
+	if err := applyPrefill(llmProvider, cfg); err != nil {
+		return err
+	}
+	if err := applyTools(llmProvider, cfg); err != nil {
+		return err
+	}
+
+	// Ctrl+C aborts the request instead of killing the process (the session is not changed)
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Session mode: send message with history
+	var response string
+	parentSess := sess
+	start := time.Now()
+	if outputFormat == outputNDJSON {
+		response, err = streamNDJSON(ctx, os.Stdout, llmProvider, sess.SystemPrompt, sess.Messages, message)
+	} else {
+		response, sess, err = chatWithAutoSummarize(ctx, llmProvider, sess, message, autoSummarize)
+	}
+
+	if err != nil {
+		return chatError(err)
+	}
+	response = withPrefill(response)
+	toolCalls := llmc.LastToolCalls(llmProvider)
+	if len(toolCalls) > 0 {
+		// The response is empty when the model calls tools; keep the calls in the session instead
+		data, err := json.Marshal(toolCalls)
+		if err != nil {
+			return fmt.Errorf("encoding tool calls: %w", err)
 		}
+		response = string(data)
+	}
+
+	// Add the turn to the session (the summarized session if the context was exceeded)
+	addTurn(sess, message, response, llmProvider, cfg.Model)
 
-		// Print response
-		fmt.Println(response)
+	// Save session
+	if err := session.SaveSession(sess); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
 
-		// If new session, print session info
-		if isNewSession {
-			fmt.Fprintf(os.Stderr, "\nSession created: %s\n", sess.GetShortID())
-			sessionDir, _ := session.GetSessionDir()
-			fmt.Fprintf(os.Stderr, "Path: %s/%s.json\n", sessionDir, sess.ID)
-			fmt.Fprintf(os.Stderr, "\nNext time, use:\n  llmc chat -s %s \"your message\"\n", sess.GetShortID())
-			fmt.Fprintf(os.Stderr, "For interactive mode, use:\n  llmc sessions start %s\n", sess.GetShortID())
+	// Print response (already streamed in ndjson mode)
+	if chatJSON {
+		if err := writeChatJSON(os.Stdout, response, llmc.ResponseModel(llmProvider, cfg.Model), llmProvider, sess.ID); err != nil {
+			return err
+		}
+	} else if len(toolCalls) > 0 {
+		if err := writeJSON(os.Stdout, toolCalls, compactJSON); err != nil {
+			return err
 		}
+	} else if outputFormat != outputNDJSON {
+		writeResponse(os.Stdout, response, llmc.ResponseModel(llmProvider, cfg.Model), time.Since(start))
+	}
+	if showUsage {
+		writeUsage(os.Stderr, llmProvider)
+	}
 
-		return nil
-	},
+	// If the session was summarized, print the session to continue with
+	if sess != parentSess {
+		fmt.Fprintf(os.Stderr, "\nSession %s exceeded the context window and was summarized into %s\n", parentSess.GetShortID(), sess.GetShortID())
+		fmt.Fprintf(os.Stderr, "\nNext time, use:\n  llmc chat -s %s \"your message\"\n", sess.GetShortID())
+	}
+
+	// If new session, print session info
+	if isNewSession {
+		fmt.Fprintf(os.Stderr, "\nSession created: %s\n", sess.GetShortID())
+		sessionDir, _ := session.GetSessionDir()
+		fmt.Fprintf(os.Stderr, "Path: %s/%s.json\n", sessionDir, sess.ID)
+		fmt.Fprintf(os.Stderr, "\nNext time, use:\n  llmc chat -s %s \"your message\"\n", sess.GetShortID())
+		fmt.Fprintf(os.Stderr, "For interactive mode, use:\n  llmc sessions start %s\n", sess.GetShortID())
+	}
+
+	return nil
+}
+
+// splitFormattedMessage splits a message formatted with a prompt template
+// ("System: ...\n\nUser: ...") into its system prompt and user message
+func splitFormattedMessage(formatted string) (systemPrompt, userMessage string, ok bool) {
+	if !strings.HasPrefix(formatted, "System: ") {
+		return "", "", false
+	}
+	parts := strings.SplitN(formatted, "\n\nUser: ", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimPrefix(parts[0], "System: "), parts[1], true
+}
+
+// templateUserMessage returns message wrapped in the user prompt of the template
+// promptName with args, as the first turn of a session created from the template
+func templateUserMessage(message, promptName string, promptDirs []string, args map[string]string) (string, error) {
+	formatted, _, _, _, err := promptpkg.FormatMessageWithArgs(message, promptName, promptDirs, args)
+	if err != nil {
+		return "", err
+	}
+	if _, user, ok := splitFormattedMessage(formatted); ok {
+		return user, nil
+	}
+	return formatted, nil
 }
 
 // getMessageFromEditor opens the default editor and returns the edited message
@@ -337,6 +742,337 @@ func getMessageFromEditor() (string, error) {
 	return strings.TrimSpace(string(content)), nil
 }
 
+// streamNDJSON streams the response from provider to w as JSON lines, one per event.
+// A failed request is reported as a final error event and returned.
+func streamNDJSON(ctx context.Context, w io.Writer, provider llmc.Provider, systemPrompt string, history []llmc.Message, message string) (string, error) {
+	streamer, ok := provider.(llmc.StreamingProvider)
+	if !ok {
+		return "", fmt.Errorf("--output %s: %w", outputNDJSON, llmc.ErrStreamingNotSupported)
+	}
+
+	enc := json.NewEncoder(w)
+	response, err := streamer.ChatStream(ctx, systemPrompt, history, message, func(e llmc.StreamEvent) error {
+		return enc.Encode(e)
+	})
+	if err != nil {
+		if !errors.Is(err, llmc.ErrStreamingNotSupported) {
+			enc.Encode(llmc.StreamEvent{Type: llmc.StreamEventError, Error: err.Error()})
+		}
+		return "", err
+	}
+	return response, nil
+}
+
+// chatWithAutoSummarize sends message with the history of sess and returns the response
+// and the session the turn belongs to.
+// With autoSummarize, a context length error makes it summarize sess into a new saved
+// session and retry the message there once; the new session is returned in that case.
+func chatWithAutoSummarize(ctx context.Context, provider llmc.Provider, sess *session.Session, message string, autoSummarize bool) (string, *session.Session, error) {
+	response, err := provider.ChatWithHistoryContext(ctx, sess.SystemPrompt, sess.Messages, message)
+	if err == nil || !autoSummarize || sess.MessageCount() == 0 || llmc.ErrorKindOf(err) != llmc.ErrorKindContextLength {
+		return response, sess, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Context window exceeded, summarizing session %s...\n", sess.GetShortID())
+	summarized, summarizeErr := summarizeSession(ctx, sess, provider)
+	if summarizeErr != nil {
+		return "", sess, fmt.Errorf("%w (auto-summarize failed: %v)", err, summarizeErr)
+	}
+	if err := session.SaveSession(summarized); err != nil {
+		return "", sess, fmt.Errorf("saving summarized session: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Retrying in summarized session %s\n", summarized.GetShortID())
+
+	response, err = provider.ChatWithHistoryContext(ctx, summarized.SystemPrompt, summarized.Messages, message)
+	return response, summarized, err
+}
+
+// errCancelled is returned when a request is aborted with Ctrl+C
+var errCancelled = fmt.Errorf("request cancelled: %w", context.Canceled)
+
+// interruptContext returns a context that is cancelled on Ctrl+C (SIGINT) while
+// a request is in flight, instead of the process being killed.
+// stop restores the default handling of SIGINT.
+func interruptContext() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// chatError wraps an error of a chat request, reporting a cancelled request plainly
+func chatError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return errCancelled
+	}
+	return fmt.Errorf("chat request failed: %w", err)
+}
+
+// writeUsage writes the token usage of the last response of provider (for --usage)
+func writeUsage(w io.Writer, provider llmc.Provider) {
+	if usage := llmc.LastUsage(provider); usage != nil {
+		fmt.Fprintf(w, "Tokens: %s\n", usage)
+		return
+	}
+	fmt.Fprintln(w, "Tokens: not reported by the provider")
+}
+
+// applyPrefill sets --prefill on provider. It is a usage error if the model
+// (or a fallback model) does not support prefill.
+func applyPrefill(provider llmc.Provider, cfg *config.Config) error {
+	if prefill == "" {
+		return nil
+	}
+	if err := llmc.SetPrefill(provider, strings.TrimRight(prefill, " \t\r\n")); err != nil {
+		return newUsageError(fmt.Errorf("--prefill is not supported by %s (only Anthropic and Anthropic-compatible models can prefill responses)", cfg.Model))
+	}
+	return nil
+}
+
+// applyTools loads the tool definitions of --tools and offers them to the model of provider.
+// Tool calls of the model are printed as JSON for the caller to execute instead of a response.
+func applyTools(provider llmc.Provider, cfg *config.Config) error {
+	if toolsFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(toolsFile)
+	if err != nil {
+		return fmt.Errorf("reading tools file: %w", err)
+	}
+	tools, err := llmc.ParseTools(data)
+	if err != nil {
+		return newUsageError(fmt.Errorf("%s: %w", toolsFile, err))
+	}
+	if err := llmc.SetTools(provider, tools); err != nil {
+		return newUsageError(fmt.Errorf("--tools is not supported by %s (only OpenAI and OpenAI-compatible models can call tools)", cfg.Model))
+	}
+	return nil
+}
+
+// withPrefill returns response with --prefill in front of it if --include-prefill is set
+func withPrefill(response string) string {
+	if !includePrefill {
+		return response
+	}
+	return strings.TrimRight(prefill, " \t\r\n") + response
+}
+
+// printResponse writes the part of response selected by extractResponse to w,
+// followed by a newline unless --no-newline is given
+func printResponse(w io.Writer, response string) {
+	fmt.Fprint(w, extractResponse(response))
+	if !noNewline {
+		fmt.Fprintln(w)
+	}
+}
+
+// writeResponse prints response to w, framed with the model and the elapsed time
+// of the request with --pretty and styled with --render when w is a terminal, and
+// as plain text otherwise
+func writeResponse(w io.Writer, response, model string, elapsed time.Duration) {
+	if isTerminal(w) && renderOutput {
+		response = llmc.RenderMarkdown(response)
+	}
+	if prettyEnabled(w) {
+		writePrettyResponse(w, extractResponse(response), model, elapsed)
+		return
+	}
+	printResponse(w, response)
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && readline.IsTerminal(int(f.Fd()))
+}
+
+// chatResult is the result of a chat request written with --json
+type chatResult struct {
+	Model     string          `json:"model"`
+	Response  string          `json:"response"`
+	Usage     *llmc.Usage     `json:"usage"`                // null if not reported by the provider
+	ToolCalls []llmc.ToolCall `json:"tool_calls,omitempty"` // Tool calls requested instead of a response
+	Session   string          `json:"session,omitempty"`    // ID of the session the turn was added to
+}
+
+// writeChatJSON writes the response of provider as a chatResult (--json).
+// If the model called tools, the calls are written instead of the response text.
+func writeChatJSON(w io.Writer, response, model string, provider llmc.Provider, sessionID string) error {
+	result := chatResult{
+		Model:     model,
+		Response:  extractResponse(response),
+		Usage:     llmc.LastUsage(provider),
+		ToolCalls: llmc.LastToolCalls(provider),
+		Session:   sessionID,
+	}
+	if len(result.ToolCalls) > 0 {
+		result.Response = ""
+	}
+	return writeJSON(w, result, compactJSON)
+}
+
+// writeChatJSONError writes err as a JSON object {"error": "..."} (--json)
+func writeChatJSONError(w io.Writer, err error) error {
+	return writeJSON(w, struct {
+		Error string `json:"error"`
+	}{Error: err.Error()}, compactJSON)
+}
+
+// prettyEnabled reports whether the --pretty layout is used for w.
+// It is suppressed when w is not a terminal, so piped output stays raw.
+func prettyEnabled(w io.Writer) bool {
+	return pretty && isTerminal(w)
+}
+
+// writePrettyResponse writes response in a subtle frame whose header shows the model and elapsed time
+func writePrettyResponse(w io.Writer, response, model string, elapsed time.Duration) {
+	fmt.Fprintf(w, "╭─ %s · %s\n", model, elapsed.Round(100*time.Millisecond))
+	for _, line := range strings.Split(strings.TrimRight(response, "\n"), "\n") {
+		if line == "" {
+			fmt.Fprintln(w, "│")
+			continue
+		}
+		fmt.Fprintf(w, "│ %s\n", line)
+	}
+	fmt.Fprintln(w, "╰─")
+}
+
+// extractResponse returns the part of response to print: the contents of its
+// fenced code blocks with --only-code (only the first with --first-block),
+// or the full response otherwise or when there is no code block
+func extractResponse(response string) string {
+	if !onlyCode && !firstBlock {
+		return response
+	}
+
+	blocks := llmc.ExtractCodeBlocks(response)
+	if len(blocks) == 0 {
+		fmt.Fprintln(os.Stderr, "Notice: no code block found in the response, printing the full response")
+		return response
+	}
+	if firstBlock {
+		return blocks[0]
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// joinArgs joins the message arguments with spaces, or with newlines when newline is set
+// (e.g. for code or poetry given one line per argument). Newlines inside an argument are kept.
+func joinArgs(args []string, newline bool) string {
+	if newline {
+		return strings.Join(args, "\n")
+	}
+	return strings.Join(args, " ")
+}
+
+// bindStdinArg reads r and stores its content in args under key,
+// so that piped input is available as {{key}} in the prompt template
+func bindStdinArg(r io.Reader, key, promptName string, args map[string]string) error {
+	if promptName == "" {
+		return newUsageError(fmt.Errorf("--stdin-as requires --prompt"))
+	}
+	if key == "input" {
+		return newUsageError(fmt.Errorf("--stdin-as cannot be 'input' (stdin is bound to {{input}} by default)"))
+	}
+	if _, ok := args[key]; ok {
+		return newUsageError(fmt.Errorf("'%s' is given by both --stdin-as and --arg", key))
+	}
+
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading from stdin: %w", err)
+	}
+	content := strings.TrimSpace(string(input))
+	if content == "" {
+		return newUsageError(fmt.Errorf("no input provided on stdin for --stdin-as %s", key))
+	}
+
+	args[key] = content
+	return nil
+}
+
+// seedSession adds message with role as the first message of the new session sess,
+// after checking that the API of the session's provider accepts a conversation starting with it
+func seedSession(sess *session.Session, cfg *config.Config, role, message string) error {
+	api := sess.GetProvider()
+	if api == llmc.CustomProviderName {
+		api = cfg.APIStyle
+	}
+	if err := llmc.CheckFirstRole(api, role); err != nil {
+		return fmt.Errorf("cannot seed session: %w", err)
+	}
+	sess.AddMessage(role, message)
+	return nil
+}
+
+// applyStdinTemplate reads the message from r and replaces its {{placeholders}} with args.
+// Unlike a prompt template there is no {{input}}: every placeholder must be given with --arg.
+func applyStdinTemplate(r io.Reader, args map[string]string) (string, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("reading from stdin: %w", err)
+	}
+	template := strings.TrimSpace(string(input))
+
+	if missing := missingPlaceholders(template, args); len(missing) > 0 {
+		return "", newUsageError(fmt.Errorf("missing arguments for the stdin template: %s\nUse --arg key:value to set them", strings.Join(missing, ", ")))
+	}
+
+	return promptpkg.ApplyArgs(template, args), nil
+}
+
+// resolveSystemPrompt returns the --system text or the contents of the --system-file
+// path ($VARS are expanded), with its {{placeholders}} replaced by the --arg values
+// ("" if neither is given)
+func resolveSystemPrompt(text, file string, args map[string]string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(os.ExpandEnv(file))
+		if err != nil {
+			return "", fmt.Errorf("reading system prompt file: %w", err)
+		}
+		text = strings.TrimSpace(string(data))
+	}
+
+	if missing := missingPlaceholders(text, args); len(missing) > 0 {
+		return "", newUsageError(fmt.Errorf("missing arguments for the system prompt: %s\nUse --arg key:value to set them", strings.Join(missing, ", ")))
+	}
+	return promptpkg.ApplyArgs(text, args), nil
+}
+
+// missingPlaceholders returns the placeholders of text that are not given in args
+func missingPlaceholders(text string, args map[string]string) []string {
+	var missing []string
+	for _, name := range promptpkg.Placeholders(text) {
+		if _, ok := args[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// validateMessage checks that there is something to send.
+// An empty message is only accepted with a prompt template and --allow-empty-input,
+// since the template itself can supply the content.
+func validateMessage(message, promptName string, allowEmpty bool) error {
+	if strings.TrimSpace(message) != "" {
+		return nil
+	}
+	if promptName != "" {
+		if allowEmpty {
+			return nil
+		}
+		return newUsageError(fmt.Errorf("no input provided\nUse --allow-empty-input to send the prompt template without input"))
+	}
+	return newUsageError(fmt.Errorf("no input provided\nUsage: llmc chat \"message\", echo \"message\" | llmc chat, llmc chat --input-file FILE, or llmc chat --editor"))
+}
+
+// readInputFile reads the message from the --input-file path, in which environment
+// variables such as $HOME are expanded
+func readInputFile(path string) (string, error) {
+	data, err := os.ReadFile(os.ExpandEnv(path))
+	if err != nil {
+		return "", fmt.Errorf("reading input file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
 func init() {
 	rootCmd.AddCommand(chatCmd)
 
@@ -345,11 +1081,39 @@ func init() {
 	chatCmd.Flags().StringVarP(&prompt, "prompt", "p", "", "Name of the prompt template (without .toml extension)")
 	chatCmd.Flags().StringArrayVar(&argFlags, "arg", []string{}, "Key-value pairs for prompt template (format: key:value)")
 	chatCmd.Flags().BoolVarP(&useEditor, "editor", "e", false, "Use default editor (from EDITOR environment variable) to compose message")
+	chatCmd.Flags().StringVar(&inputFile, "input-file", "", "Read the message from a file instead of stdin ($VARS in the path are expanded; a message argument takes precedence)")
+	chatCmd.Flags().BoolVar(&useClipboard, "clipboard", false, "Read the message from the system clipboard (with --prompt, it becomes {{input}}; a message argument takes precedence)")
+	chatCmd.Flags().StringVar(&systemFlag, "system", "", "System prompt for a one-off message or a new session ({{placeholders}} are replaced by --arg values)")
+	chatCmd.Flags().StringVar(&systemFile, "system-file", "", "Read the system prompt from a file, like --system ($VARS in the path are expanded)")
 	chatCmd.Flags().BoolVar(&webSearch, "web-search", false, "Enable web search for real-time information")
+	chatCmd.Flags().BoolVar(&newlineArgs, "newline-args", false, "Join multiple message arguments with newlines instead of spaces")
+	chatCmd.Flags().BoolVar(&listArgs, "list-args", false, "List the arguments ({{placeholder}} names) the prompt template expects and exit")
+	chatCmd.Flags().BoolVar(&stdinTemplate, "stdin-template", false, "Treat stdin as the message with {{placeholders}} replaced by --arg values (no template file)")
+	chatCmd.Flags().BoolVar(&allowEmptyInput, "allow-empty-input", false, "Allow empty input when using a prompt template")
+	chatCmd.Flags().StringVar(&stdinAs, "stdin-as", "", "Bind piped stdin to a named prompt template argument (e.g., --stdin-as diff for {{diff}})")
+	chatCmd.Flags().StringVar(&outputFormat, "output", outputText, "Output format: text or ndjson (stream response events as JSON lines)")
+	chatCmd.Flags().BoolVar(&onlyCode, "only-code", false, "Print only the contents of fenced code blocks in the response")
+	chatCmd.Flags().BoolVar(&echoRequest, "echo", false, "Print the system prompt and message sent to the model to stderr (credentials are redacted)")
+	chatCmd.Flags().BoolVar(&firstBlock, "first-block", false, "Print only the first fenced code block in the response (implies --only-code)")
+	addSamplingFlags(chatCmd)
+	chatCmd.Flags().BoolVar(&noFallback, "no-fallback", false, "Disable falling back to fallback_models when the model is unavailable")
 
 	// Session flags
 	chatCmd.Flags().StringVarP(&sessionID, "session", "s", "", "Session ID (short or full UUID, or 'latest' for most recent session)")
 	chatCmd.Flags().BoolVarP(&newSession, "new-session", "n", false, "Create a new session")
+	chatCmd.Flags().StringVar(&prefill, "prefill", "", "Start the response with this text so the model continues from it (Anthropic models only)")
+	chatCmd.Flags().BoolVar(&includePrefill, "include-prefill", false, "Include the --prefill text at the start of the printed and saved response")
+	chatCmd.Flags().BoolVar(&noNewline, "no-newline", false, "Do not print a newline after the response (like echo -n, e.g. for VAR=$(llmc chat ...))")
+	chatCmd.Flags().StringVar(&toolsFile, "tools", "", "JSON file of function tools offered to the model; tool calls are printed as JSON (OpenAI models only)")
+	chatCmd.Flags().BoolVar(&chatJSON, "json", false, "Print the model, response and token usage as a JSON object (errors as {\"error\": ...})")
+	chatCmd.Flags().BoolVar(&renderOutput, "render", false, "Style Markdown in the response (headings, bold, code) when printing to a terminal (plain when piped)")
+	chatCmd.Flags().BoolVar(&pretty, "pretty", false, "Frame the response with the model and elapsed time when printing to a terminal (plain when piped)")
+	chatCmd.Flags().IntVar(&repeatCount, "repeat", 1, "Send the message N times as independent requests and print all responses numbered (one-off messages only)")
+	chatCmd.Flags().BoolVar(&pickBest, "best", false, "With --repeat, ask the model to pick the best response and print only that one")
+	chatCmd.Flags().BoolVar(&showUsage, "usage", false, "Print the input and output token counts of the response to stderr")
+	chatCmd.Flags().StringVar(&seedRole, "role", "user", "Role of the message: user, or assistant to seed a new session with an assistant turn without sending it")
 	chatCmd.Flags().StringVar(&sessionName, "session-name", "", "Name for the new session (optional)")
+	chatCmd.Flags().BoolVar(&reapplyTemplate, "reapply-template", false, "Wrap the message in the prompt template of the session (with its stored arguments), like the first turn")
 	chatCmd.Flags().BoolVar(&ignoreThreshold, "ignore-threshold", false, "Ignore session message threshold warning")
+	chatCmd.Flags().BoolVar(&autoSummarize, "auto-summarize", false, "Summarize the session into a new one and retry when the context window is exceeded")
 }
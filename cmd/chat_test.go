@@ -0,0 +1,763 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+	promptpkg "github.com/longkey1/llmc/internal/llmc/prompt"
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+// fakeProvider is a non-streaming provider for tests
+type fakeProvider struct{}
+
+func (p *fakeProvider) Chat(message string) (string, error) { return "", nil }
+func (p *fakeProvider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return "", nil
+}
+func (p *fakeProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	return "", nil
+}
+func (p *fakeProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return "", nil
+}
+func (p *fakeProvider) SetWebSearch(enabled bool)              {}
+func (p *fakeProvider) SetIgnoreWebSearchErrors(enabled bool)  {}
+func (p *fakeProvider) SetDebug(enabled bool)                  {}
+func (p *fakeProvider) SetTimeout(timeout time.Duration)       {}
+func (p *fakeProvider) SetSampling(params llmc.SamplingParams) {}
+func (p *fakeProvider) ListModels() ([]llmc.ModelInfo, error)  { return nil, nil }
+func (p *fakeProvider) HealthCheck(ctx context.Context) error  { return nil }
+
+// fakeStreamingProvider emits events as a streaming provider would
+type fakeStreamingProvider struct {
+	fakeProvider
+	events []llmc.StreamEvent
+	err    error
+}
+
+func (p *fakeStreamingProvider) ChatStream(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string, onEvent func(llmc.StreamEvent) error) (string, error) {
+	var text strings.Builder
+	for _, e := range p.events {
+		text.WriteString(e.Text)
+		if err := onEvent(e); err != nil {
+			return "", err
+		}
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	return text.String(), nil
+}
+
+func TestChatTimeoutFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", args: []string{}, want: 0},
+		{name: "seconds", args: []string{"--timeout", "30s"}, want: 30 * time.Second},
+		{name: "minutes", args: []string{"--timeout", "2m"}, want: 2 * time.Minute},
+		{name: "invalid", args: []string{"--timeout", "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestTimeout = 0
+			flag := rootCmd.PersistentFlags().Lookup("timeout")
+			flag.Changed = false
+			defer func() {
+				requestTimeout = 0
+				flag.Changed = false
+			}()
+
+			err := chatCmd.ParseFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if requestTimeout != tt.want {
+				t.Errorf("requestTimeout = %v, want %v", requestTimeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTimeout(t *testing.T) {
+	flag := rootCmd.PersistentFlags().Lookup("timeout")
+	defer func() {
+		requestTimeout = 0
+		flag.Changed = false
+	}()
+
+	cfg := &config.Config{RequestTimeoutSeconds: 120}
+	if got := resolveTimeout(cfg); got != 120*time.Second {
+		t.Errorf("resolveTimeout() = %v, want request_timeout_seconds (2m0s)", got)
+	}
+
+	// --timeout overrides the config, and 0 disables the timeout
+	if err := rootCmd.PersistentFlags().Set("timeout", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := resolveTimeout(cfg); got != 0 {
+		t.Errorf("resolveTimeout() with --timeout 0 = %v, want 0", got)
+	}
+}
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		promptName string
+		allowEmpty bool
+		wantErr    bool
+	}{
+		{name: "message", message: "hello", wantErr: false},
+		{name: "empty stdin", message: "", wantErr: true},
+		{name: "whitespace only", message: " \n\t", wantErr: true},
+		{name: "empty stdin with allow flag but no template", message: "", allowEmpty: true, wantErr: true},
+		{name: "empty input with template", message: "", promptName: "translate", wantErr: true},
+		{name: "empty input with template and allow flag", message: "", promptName: "translate", allowEmpty: true, wantErr: false},
+		{name: "message with template", message: "hello", promptName: "translate", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMessage(tt.message, tt.promptName, tt.allowEmpty)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadInputFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LLMC_TEST_INPUT_DIR", dir)
+	if err := os.WriteFile(filepath.Join(dir, "message.txt"), []byte("\nSummarize this file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := readInputFile("$LLMC_TEST_INPUT_DIR/message.txt")
+	if err != nil {
+		t.Fatalf("readInputFile() error = %v", err)
+	}
+	if message != "Summarize this file" {
+		t.Errorf("readInputFile() = %q, want %q", message, "Summarize this file")
+	}
+
+	if _, err := readInputFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("readInputFile() error = nil for a missing file")
+	}
+
+	// An empty file is rejected like empty stdin
+	if err := os.WriteFile(filepath.Join(dir, "empty.txt"), []byte(" \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	message, err = readInputFile(filepath.Join(dir, "empty.txt"))
+	if err != nil {
+		t.Fatalf("readInputFile() error = %v", err)
+	}
+	if err := validateMessage(message, "", false); err == nil {
+		t.Error("validateMessage() error = nil for an empty input file")
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		newline bool
+		want    string
+	}{
+		{name: "single arg", args: []string{"hello"}, want: "hello"},
+		{name: "space join", args: []string{"roses are red", "violets are blue"}, want: "roses are red violets are blue"},
+		{name: "newline join", args: []string{"roses are red", "violets are blue"}, newline: true, want: "roses are red\nviolets are blue"},
+		{name: "embedded newline kept with space join", args: []string{"line 1\nline 2", "line 3"}, want: "line 1\nline 2 line 3"},
+		{name: "embedded newline kept with newline join", args: []string{"line 1\nline 2", "line 3"}, newline: true, want: "line 1\nline 2\nline 3"},
+		{name: "single arg with newline join", args: []string{"hello world"}, newline: true, want: "hello world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinArgs(tt.args, tt.newline); got != tt.want {
+				t.Errorf("joinArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeedSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		apiStyle string
+		role     string
+		wantErr  bool
+	}{
+		{name: "assistant first on openai", model: "openai:gpt-4o", role: "assistant"},
+		{name: "user first on anthropic", model: "anthropic:claude-3-5-sonnet", role: "user"},
+		{name: "assistant first on anthropic", model: "anthropic:claude-3-5-sonnet", role: "assistant", wantErr: true},
+		{name: "assistant first on gemini", model: "gemini:gemini-2.0-flash", role: "assistant", wantErr: true},
+		{name: "assistant first on custom openai", model: "custom:llama", apiStyle: "openai", role: "assistant"},
+		{name: "assistant first on custom gemini", model: "custom:gemma", apiStyle: "gemini", role: "assistant", wantErr: true},
+		{name: "invalid role", model: "openai:gpt-4o", role: "system", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := session.NewSession(tt.model)
+			cfg := &config.Config{APIStyle: tt.apiStyle}
+			err := seedSession(sess, cfg, tt.role, "I am a terse code reviewer.")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("seedSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if sess.MessageCount() != 0 {
+					t.Errorf("seedSession() added a message despite the error")
+				}
+				return
+			}
+			if sess.MessageCount() != 1 || sess.Messages[0].Role != tt.role {
+				t.Errorf("seedSession() messages = %v, want one %s message", sess.Messages, tt.role)
+			}
+		})
+	}
+
+}
+
+func TestResolveSystemPrompt(t *testing.T) {
+	args := map[string]string{"lang": "French", "tone": "formal"}
+
+	got, err := resolveSystemPrompt("Answer in {{lang}}, keeping a {{tone}} tone. Use {{lang}} only.", "", args)
+	if err != nil {
+		t.Fatalf("resolveSystemPrompt() error = %v", err)
+	}
+	if want := "Answer in French, keeping a formal tone. Use French only."; got != want {
+		t.Errorf("resolveSystemPrompt() = %q, want %q", got, want)
+	}
+
+	dir := t.TempDir()
+	t.Setenv("LLMC_TEST_SYSTEM_DIR", dir)
+	if err := os.WriteFile(filepath.Join(dir, "system.txt"), []byte("You review {{lang}} text.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = resolveSystemPrompt("", "$LLMC_TEST_SYSTEM_DIR/system.txt", args)
+	if err != nil {
+		t.Fatalf("resolveSystemPrompt() error = %v", err)
+	}
+	if want := "You review French text."; got != want {
+		t.Errorf("resolveSystemPrompt() from file = %q, want %q", got, want)
+	}
+
+	// Unresolved placeholders are a usage error
+	_, err = resolveSystemPrompt("Answer in {{lang}} for {{audience}}", "", args)
+	if err == nil || !strings.Contains(err.Error(), "audience") || exitCode(err) != exitUsage {
+		t.Errorf("resolveSystemPrompt() error = %v, want a usage error naming audience", err)
+	}
+
+	// Without --system the system prompt is empty
+	if got, err := resolveSystemPrompt("", "", args); got != "" || err != nil {
+		t.Errorf("resolveSystemPrompt() = %q, %v; want empty", got, err)
+	}
+}
+
+func TestApplyStdinTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		args    map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "args substituted",
+			stdin: "Translate into {{lang}}:\n\n{{text}}\n\nKeep the {{lang}} formal.\n",
+			args:  map[string]string{"lang": "Japanese", "text": "Good morning"},
+			want:  "Translate into Japanese:\n\nGood morning\n\nKeep the Japanese formal.",
+		},
+		{
+			name:  "no placeholders",
+			stdin: "Hello",
+			args:  map[string]string{},
+			want:  "Hello",
+		},
+		{
+			name:    "missing arg",
+			stdin:   "Translate into {{lang}}: {{text}}",
+			args:    map[string]string{"lang": "Japanese"},
+			wantErr: true,
+		},
+		{
+			name:    "input is not filled implicitly",
+			stdin:   "Summarize {{input}}",
+			args:    map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyStdinTemplate(strings.NewReader(tt.stdin), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyStdinTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("applyStdinTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindStdinArg(t *testing.T) {
+	tests := []struct {
+		name       string
+		stdin      string
+		key        string
+		promptName string
+		args       map[string]string
+		want       map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "binds stdin to named key",
+			stdin:      "diff --git a/main.go b/main.go\n",
+			key:        "diff",
+			promptName: "review",
+			args:       map[string]string{},
+			want:       map[string]string{"diff": "diff --git a/main.go b/main.go"},
+		},
+		{
+			name:       "keeps other args",
+			stdin:      "package main",
+			key:        "code",
+			promptName: "review",
+			args:       map[string]string{"lang": "Go"},
+			want:       map[string]string{"lang": "Go", "code": "package main"},
+		},
+		{
+			name:       "content with colons and quotes is kept as is",
+			stdin:      `key\: "value"`,
+			key:        "text",
+			promptName: "review",
+			args:       map[string]string{},
+			want:       map[string]string{"text": `key\: "value"`},
+		},
+		{
+			name:    "requires prompt",
+			stdin:   "hello",
+			key:     "diff",
+			args:    map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:       "input is reserved",
+			stdin:      "hello",
+			key:        "input",
+			promptName: "review",
+			args:       map[string]string{},
+			wantErr:    true,
+		},
+		{
+			name:       "conflicts with --arg",
+			stdin:      "hello",
+			key:        "diff",
+			promptName: "review",
+			args:       map[string]string{"diff": "other"},
+			wantErr:    true,
+		},
+		{
+			name:       "empty stdin",
+			stdin:      "  \n",
+			key:        "diff",
+			promptName: "review",
+			args:       map[string]string{},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bindStdinArg(strings.NewReader(tt.stdin), tt.key, tt.promptName, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindStdinArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.args, tt.want) {
+				t.Errorf("args = %v, want %v", tt.args, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	usage := &llmc.Usage{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}
+	provider := &fakeStreamingProvider{events: []llmc.StreamEvent{
+		{Type: llmc.StreamEventDelta, Text: "Hel"},
+		{Type: llmc.StreamEventDelta, Text: "lo"},
+		{Type: llmc.StreamEventDone, Usage: usage, FinishReason: "stop"},
+	}}
+
+	var buf bytes.Buffer
+	response, err := streamNDJSON(context.Background(), &buf, provider, "", nil, "hi")
+	if err != nil {
+		t.Fatalf("streamNDJSON() error = %v", err)
+	}
+	if response != "Hello" {
+		t.Errorf("streamNDJSON() response = %q, want %q", response, "Hello")
+	}
+
+	want := []string{
+		`{"type":"delta","text":"Hel"}`,
+		`{"type":"delta","text":"lo"}`,
+		`{"type":"done","usage":{"input_tokens":5,"output_tokens":2,"total_tokens":7},"finish_reason":"stop"}`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("streamNDJSON() output = %q, want %q", got, want)
+	}
+}
+
+func TestStreamNDJSONError(t *testing.T) {
+	provider := &fakeStreamingProvider{
+		events: []llmc.StreamEvent{{Type: llmc.StreamEventDelta, Text: "partial"}},
+		err:    errors.New("connection reset"),
+	}
+
+	var buf bytes.Buffer
+	if _, err := streamNDJSON(context.Background(), &buf, provider, "", nil, "hi"); err == nil {
+		t.Fatal("streamNDJSON() error = nil, want error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("streamNDJSON() wrote %d lines, want 2", len(lines))
+	}
+	var last llmc.StreamEvent
+	if err := json.Unmarshal([]byte(lines[1]), &last); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", lines[1], err)
+	}
+	if last.Type != llmc.StreamEventError || last.Error != "connection reset" {
+		t.Errorf("last event = %+v, want error event", last)
+	}
+}
+
+func TestStreamNDJSONNotSupported(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := streamNDJSON(context.Background(), &buf, &fakeProvider{}, "", nil, "hi")
+	if !errors.Is(err, llmc.ErrStreamingNotSupported) {
+		t.Errorf("streamNDJSON() error = %v, want ErrStreamingNotSupported", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("streamNDJSON() wrote %q, want nothing", buf.String())
+	}
+}
+
+func TestExtractResponse(t *testing.T) {
+	response := "Two scripts:\n```sh\necho 1\n```\nand\n```sh\necho 2\n```"
+
+	tests := []struct {
+		name       string
+		onlyCode   bool
+		firstBlock bool
+		response   string
+		want       string
+	}{
+		{name: "disabled", response: response, want: response},
+		{name: "all blocks", onlyCode: true, response: response, want: "echo 1\necho 2"},
+		{name: "first block", onlyCode: true, firstBlock: true, response: response, want: "echo 1"},
+		{name: "first block implies only code", firstBlock: true, response: response, want: "echo 1"},
+		{name: "no block falls back to full response", onlyCode: true, response: "No code here.", want: "No code here."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onlyCode, firstBlock = tt.onlyCode, tt.firstBlock
+			defer func() { onlyCode, firstBlock = false, false }()
+
+			if got := extractResponse(tt.response); got != tt.want {
+				t.Errorf("extractResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintResponse(t *testing.T) {
+	response := "Here:\n```sh\necho 1\n```"
+
+	tests := []struct {
+		name      string
+		onlyCode  bool
+		noNewline bool
+		want      string
+	}{
+		{name: "default newline", want: response + "\n"},
+		{name: "no newline", noNewline: true, want: response},
+		{name: "only code with newline", onlyCode: true, want: "echo 1\n"},
+		{name: "only code without newline", onlyCode: true, noNewline: true, want: "echo 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onlyCode, noNewline = tt.onlyCode, tt.noNewline
+			defer func() { onlyCode, noNewline = false, false }()
+
+			var buf bytes.Buffer
+			printResponse(&buf, response)
+			if buf.String() != tt.want {
+				t.Errorf("printResponse() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettySuppressedWithoutTerminal(t *testing.T) {
+	pretty = true
+	defer func() { pretty = false }()
+
+	var buf bytes.Buffer
+	writeResponse(&buf, "hello", "openai:gpt-4", time.Second)
+	if buf.String() != "hello\n" {
+		t.Errorf("writeResponse() = %q, want the plain response", buf.String())
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if prettyEnabled(f) {
+		t.Error("prettyEnabled() = true for a file, want false")
+	}
+}
+
+func TestRenderSuppressedWithoutTerminal(t *testing.T) {
+	renderOutput = true
+	defer func() { renderOutput = false }()
+
+	var buf bytes.Buffer
+	writeResponse(&buf, "# Title\n**bold**", "openai:gpt-4", time.Second)
+	if buf.String() != "# Title\n**bold**\n" {
+		t.Errorf("writeResponse() = %q, want the raw Markdown", buf.String())
+	}
+}
+
+func TestWritePrettyResponse(t *testing.T) {
+	var buf bytes.Buffer
+	writePrettyResponse(&buf, "line 1\n\nline 2\n", "openai:gpt-4", 1234*time.Millisecond)
+	want := "╭─ openai:gpt-4 · 1.2s\n│ line 1\n│\n│ line 2\n╰─\n"
+	if buf.String() != want {
+		t.Errorf("writePrettyResponse() = %q, want %q", buf.String(), want)
+	}
+}
+
+// usageProvider reports a fixed token usage
+type usageProvider struct {
+	fakeProvider
+	usage *llmc.Usage
+}
+
+func (p *usageProvider) LastUsage() *llmc.Usage { return p.usage }
+
+func TestWriteChatJSON(t *testing.T) {
+	var buf bytes.Buffer
+	provider := &usageProvider{usage: &llmc.Usage{InputTokens: 12, OutputTokens: 3, TotalTokens: 15}}
+	if err := writeChatJSON(&buf, "The answer", "openai:gpt-4", provider, "550e8400"); err != nil {
+		t.Fatalf("writeChatJSON() error = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]interface{}{
+		"model":    "openai:gpt-4",
+		"response": "The answer",
+		"usage":    map[string]interface{}{"input_tokens": 12.0, "output_tokens": 3.0, "total_tokens": 15.0},
+		"session":  "550e8400",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeChatJSON() = %v, want %v", got, want)
+	}
+
+	// Usage that is not reported is null
+	buf.Reset()
+	if err := writeChatJSON(&buf, "ok", "gemini:gemini-2.0-flash", &fakeProvider{}, ""); err != nil {
+		t.Fatalf("writeChatJSON() error = %v", err)
+	}
+	got = nil
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if usage, ok := got["usage"]; !ok || usage != nil {
+		t.Errorf("usage = %v (present: %v), want null", usage, ok)
+	}
+	if _, ok := got["session"]; ok {
+		t.Errorf("session = %v, want it omitted without a session", got["session"])
+	}
+}
+
+func TestWriteChatJSONError(t *testing.T) {
+	var buf bytes.Buffer
+	err := llmc.NewAPIError("openai", 429, "rate_limit_exceeded", "API error: rate limited")
+	if writeErr := writeChatJSONError(&buf, chatError(err)); writeErr != nil {
+		t.Fatalf("writeChatJSONError() error = %v", writeErr)
+	}
+
+	var got struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(got.Error, "rate limited") {
+		t.Errorf("error = %q, want the request error", got.Error)
+	}
+	if code := exitCode(chatError(err)); code != exitRateLimited {
+		t.Errorf("exitCode() = %d, want %d", code, exitRateLimited)
+	}
+}
+
+// contextLimitProvider fails requests whose history is longer than limit
+// with a context length error
+type contextLimitProvider struct {
+	fakeProvider
+	limit int
+	calls int
+}
+
+func (p *contextLimitProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	return "the summary", nil
+}
+func (p *contextLimitProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	p.calls++
+	if len(messages) > p.limit {
+		return "", llmc.NewAPIError("openai", 400, "context_length_exceeded", "API error: context window exceeded")
+	}
+	return "the answer", nil
+}
+
+func TestChatWithAutoSummarize(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	newSess := func() *session.Session {
+		sess := session.NewSession("openai:gpt-4")
+		for i := 0; i < 4; i++ {
+			sess.AddMessage("user", "question")
+			sess.AddMessage("assistant", "answer")
+		}
+		return sess
+	}
+
+	t.Run("retries in summarized session", func(t *testing.T) {
+		sess := newSess()
+		provider := &contextLimitProvider{limit: 2}
+
+		response, active, err := chatWithAutoSummarize(context.Background(), provider, sess, "next", true)
+		if err != nil {
+			t.Fatalf("chatWithAutoSummarize() error = %v", err)
+		}
+		if response != "the answer" {
+			t.Errorf("chatWithAutoSummarize() response = %q, want %q", response, "the answer")
+		}
+		if provider.calls != 2 {
+			t.Errorf("ChatWithHistory called %d times, want 2", provider.calls)
+		}
+		if active == sess || active.ParentID != sess.ID {
+			t.Fatalf("chatWithAutoSummarize() session parent = %q, want a new session with parent %q", active.ParentID, sess.ID)
+		}
+		if len(active.Messages) != 1 || !strings.Contains(active.Messages[0].Content, "the summary") {
+			t.Errorf("summarized session messages = %v, want the summary only", active.Messages)
+		}
+		if _, err := session.LoadSession(active.ID); err != nil {
+			t.Errorf("summarized session was not saved: %v", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sess := newSess()
+		provider := &contextLimitProvider{limit: 2}
+
+		_, active, err := chatWithAutoSummarize(context.Background(), provider, sess, "next", false)
+		if llmc.ErrorKindOf(err) != llmc.ErrorKindContextLength {
+			t.Errorf("chatWithAutoSummarize() error = %v, want context length error", err)
+		}
+		if active != sess || provider.calls != 1 {
+			t.Errorf("chatWithAutoSummarize() retried with auto-summarize disabled")
+		}
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		sess := newSess()
+		provider := &failingProvider{err: llmc.NewAPIError("openai", 400, "", "bad request")}
+
+		_, active, err := chatWithAutoSummarize(context.Background(), provider, sess, "next", true)
+		if err == nil || active != sess {
+			t.Errorf("chatWithAutoSummarize() = %v, %v, want the original error and session", active, err)
+		}
+	})
+}
+
+// failingProvider fails every request with err
+type failingProvider struct {
+	fakeProvider
+	err error
+}
+
+func (p *failingProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	return "", p.err
+}
+func (p *failingProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return "", p.err
+}
+
+func TestTemplateUserMessageMatchesFirstTurn(t *testing.T) {
+	dir := t.TempDir()
+	template := "system = \"You review {{lang}} code\"\nuser = \"Review this {{lang}} code:\\n{{input}}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "review.toml"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := map[string]string{"lang": "Go"}
+
+	// The first turn of a session created with --prompt review --arg lang:Go
+	formatted, _, _, _, err := promptpkg.FormatMessageWithArgs("a := 1", "review", []string{dir}, args)
+	if err != nil {
+		t.Fatalf("FormatMessageWithArgs() error = %v", err)
+	}
+	systemPrompt, first, ok := splitFormattedMessage(formatted)
+	if !ok {
+		t.Fatalf("splitFormattedMessage(%q) ok = false", formatted)
+	}
+	if systemPrompt != "You review Go code" {
+		t.Errorf("system prompt = %q, want %q", systemPrompt, "You review Go code")
+	}
+
+	// A follow-up with --reapply-template uses the stored arguments
+	followUp, err := templateUserMessage("b := 2", "review", []string{dir}, promptpkg.MergeArgs(args, map[string]string{}))
+	if err != nil {
+		t.Fatalf("templateUserMessage() error = %v", err)
+	}
+	if want := strings.Replace(first, "a := 1", "b := 2", 1); followUp != want {
+		t.Errorf("follow-up = %q, want %q (wrapped like the first turn %q)", followUp, want, first)
+	}
+
+	if _, err := templateUserMessage("b := 2", "missing", []string{dir}, args); err == nil {
+		t.Error("templateUserMessage() error = nil for a missing template, want an error")
+	}
+}
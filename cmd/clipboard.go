@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands returns the commands that print the system clipboard on this
+// platform, in order of preference
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}}
+	}
+	commands := [][]string{
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+	}
+	// Prefer wl-paste in a Wayland session, where xclip only sees XWayland clients
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append([][]string{{"wl-paste", "--no-newline"}}, commands...)
+	}
+	return commands
+}
+
+// readClipboard returns the output of the first of commands that is installed,
+// with surrounding whitespace trimmed
+func readClipboard(commands [][]string) (string, error) {
+	var names []string
+	for _, command := range commands {
+		names = append(names, command[0])
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+
+		out, err := exec.Command(path, command[1:]...).Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				return "", fmt.Errorf("reading clipboard with %s: %w: %s", command[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return "", fmt.Errorf("reading clipboard with %s: %w", command[0], err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", fmt.Errorf("no clipboard tool found (install one of: %s)", strings.Join(names, ", "))
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeStubCommand writes an executable shell script named name to dir
+func writeStubCommand(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadClipboard(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stub commands are shell scripts")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	writeStubCommand(t, dir, "stub-paste", `printf '  copied code\n\n'`)
+	writeStubCommand(t, dir, "stub-fail", `echo "no clipboard owner" >&2; exit 1`)
+
+	t.Run("first installed command is used", func(t *testing.T) {
+		got, err := readClipboard([][]string{{"missing-paste"}, {"stub-paste"}, {"stub-fail"}})
+		if err != nil {
+			t.Fatalf("readClipboard() error = %v", err)
+		}
+		if got != "copied code" {
+			t.Errorf("readClipboard() = %q, want %q", got, "copied code")
+		}
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		_, err := readClipboard([][]string{{"stub-fail"}, {"stub-paste"}})
+		if err == nil || !strings.Contains(err.Error(), "no clipboard owner") {
+			t.Errorf("readClipboard() error = %v, want the error output of stub-fail", err)
+		}
+	})
+
+	t.Run("no command installed", func(t *testing.T) {
+		_, err := readClipboard([][]string{{"missing-paste"}, {"other-paste", "-o"}})
+		if err == nil || !strings.Contains(err.Error(), "missing-paste, other-paste") {
+			t.Errorf("readClipboard() error = %v, want the tools to install", err)
+		}
+	})
+}
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/longkey1/llmc/internal/llmc/config"
+	"github.com/longkey1/llmc/internal/llmc/fsutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,7 +23,7 @@ var configCmd = &cobra.Command{
 This command shows all configuration values loaded from the config file and environment variables.
 
 If a field name is specified, only that field's value is displayed.
-Available fields: configfile, openai_base_url, gemini_base_url, anthropic_base_url, model, openai_token, gemini_token, anthropic_token, promptdirs, websearch, sessionretentiondays
+Available fields: configfile, openai_base_url, gemini_base_url, anthropic_base_url, cohere_base_url, openrouter_base_url, azure_endpoint, azure_api_version, custom_base_url, api_style, model, fallback_models, default_provider, openai_token, gemini_token, anthropic_token, cohere_token, openrouter_token, azure_token, custom_token, promptdirs, websearch, sessionretentiondays, max_sessions, time_format, timezone, user_label, assistant_label, sampling, redact, redact_patterns, max_idle_conns, idle_conn_timeout, max_display_lines, history_size, max_retries, timeout_retries, overloaded_retries, request_timeout_seconds, role_map
 
 Examples:
   llmc config                      # Show all configuration
@@ -25,9 +31,11 @@ Examples:
   llmc config openai_base_url     # Show only OpenAI base URL
   llmc config gemini_base_url     # Show only Gemini base URL
   llmc config anthropic_base_url  # Show only Anthropic base URL
+  llmc config cohere_base_url     # Show only Cohere base URL
   llmc config openai_token        # Show only OpenAI token
   llmc config gemini_token        # Show only Gemini token
   llmc config anthropic_token     # Show only Anthropic token
+  llmc config cohere_token        # Show only Cohere token
   llmc config promptdirs          # Show only prompt directories
   llmc config websearch           # Show only web search setting
   llmc config sessionretentiondays   # Show only session retention days setting`,
@@ -51,14 +59,38 @@ Examples:
 				fmt.Println(cfg.GeminiBaseURL)
 			case "anthropic_base_url", "anthropicbaseurl":
 				fmt.Println(cfg.AnthropicBaseURL)
+			case "cohere_base_url", "coherebaseurl":
+				fmt.Println(cfg.CohereBaseURL)
+			case "openrouter_base_url", "openrouterbaseurl":
+				fmt.Println(cfg.OpenRouterBaseURL)
+			case "azure_endpoint", "azureendpoint":
+				fmt.Println(cfg.AzureEndpoint)
+			case "azure_api_version", "azureapiversion":
+				fmt.Println(cfg.AzureAPIVersion)
+			case "custom_base_url", "custombaseurl":
+				fmt.Println(cfg.CustomBaseURL)
+			case "api_style", "apistyle":
+				fmt.Println(cfg.APIStyle)
 			case "model":
 				fmt.Println(cfg.Model)
+			case "fallback_models", "fallbackmodels":
+				fmt.Println(strings.Join(cfg.FallbackModels, ","))
+			case "default_provider", "defaultprovider":
+				fmt.Println(cfg.DefaultProvider)
 			case "openai_token", "openaitoken":
 				fmt.Println(resolveAndMaskToken(cfg, "openai"))
 			case "gemini_token", "geminitoken":
 				fmt.Println(resolveAndMaskToken(cfg, "gemini"))
 			case "anthropic_token", "anthropictoken":
 				fmt.Println(resolveAndMaskToken(cfg, "anthropic"))
+			case "cohere_token", "coheretoken":
+				fmt.Println(resolveAndMaskToken(cfg, "cohere"))
+			case "openrouter_token", "openroutertoken":
+				fmt.Println(resolveAndMaskToken(cfg, "openrouter"))
+			case "azure_token", "azuretoken":
+				fmt.Println(resolveAndMaskToken(cfg, "azure"))
+			case "custom_token", "customtoken":
+				fmt.Println(resolveAndMaskToken(cfg, "custom"))
 			case "promptdirs":
 				// PromptDirs are already absolute paths
 				fmt.Println(strings.Join(cfg.PromptDirs, ","))
@@ -66,8 +98,42 @@ Examples:
 				fmt.Println(cfg.EnableWebSearch)
 			case "sessionretentiondays":
 				fmt.Println(cfg.SessionRetentionDays)
+			case "max_sessions", "maxsessions":
+				fmt.Println(cfg.MaxSessions)
+			case "time_format", "timeformat":
+				fmt.Println(cfg.TimeFormat)
+			case "timezone":
+				fmt.Println(cfg.Timezone)
+			case "user_label", "userlabel":
+				fmt.Println(cfg.UserLabel)
+			case "assistant_label", "assistantlabel":
+				fmt.Println(cfg.AssistantLabel)
+			case "sampling":
+				fmt.Println(cfg.SamplingParams())
+			case "redact":
+				fmt.Println(cfg.RedactionEnabled())
+			case "redact_patterns", "redactpatterns":
+				fmt.Println(strings.Join(cfg.RedactPatterns, ","))
+			case "max_idle_conns", "maxidleconns":
+				fmt.Println(cfg.MaxIdleConns)
+			case "idle_conn_timeout", "idleconntimeout":
+				fmt.Println(cfg.IdleConnTimeout)
+			case "max_display_lines", "maxdisplaylines":
+				fmt.Println(cfg.MaxDisplayLines)
+			case "history_size", "historysize":
+				fmt.Println(cfg.HistorySize)
+			case "max_retries", "maxretries":
+				fmt.Println(cfg.MaxRetries)
+			case "timeout_retries", "timeoutretries":
+				fmt.Println(cfg.TimeoutRetries)
+			case "overloaded_retries", "overloadedretries":
+				fmt.Println(cfg.OverloadedRetries)
+			case "request_timeout_seconds", "requesttimeoutseconds":
+				fmt.Println(cfg.RequestTimeoutSeconds)
+			case "role_map", "rolemap":
+				fmt.Println(formatRoleMap(cfg.RoleMap))
 			default:
-				return fmt.Errorf("unknown field: %s\nAvailable fields: configfile, openai_base_url, gemini_base_url, anthropic_base_url, model, openai_token, gemini_token, anthropic_token, promptdirs, websearch, sessionretentiondays", args[0])
+				return fmt.Errorf("unknown field: %s\nAvailable fields: configfile, openai_base_url, gemini_base_url, anthropic_base_url, cohere_base_url, openrouter_base_url, azure_endpoint, azure_api_version, custom_base_url, api_style, model, fallback_models, default_provider, openai_token, gemini_token, anthropic_token, cohere_token, openrouter_token, azure_token, custom_token, promptdirs, websearch, sessionretentiondays, max_sessions, time_format, timezone, user_label, assistant_label, sampling, redact, redact_patterns, max_idle_conns, idle_conn_timeout, max_display_lines, history_size, max_retries, timeout_retries, overloaded_retries, request_timeout_seconds, role_map", args[0])
 			}
 			return nil
 		}
@@ -80,15 +146,64 @@ Examples:
 		fmt.Printf("%-20s: %s\n", "GeminiToken", resolveAndMaskToken(cfg, "gemini"))
 		fmt.Printf("%-20s: %s\n", "AnthropicBaseURL", cfg.AnthropicBaseURL)
 		fmt.Printf("%-20s: %s\n", "AnthropicToken", resolveAndMaskToken(cfg, "anthropic"))
+		fmt.Printf("%-20s: %s\n", "CohereBaseURL", cfg.CohereBaseURL)
+		fmt.Printf("%-20s: %s\n", "CohereToken", resolveAndMaskToken(cfg, "cohere"))
+		fmt.Printf("%-20s: %s\n", "OpenRouterBaseURL", cfg.OpenRouterBaseURL)
+		fmt.Printf("%-20s: %s\n", "OpenRouterToken", resolveAndMaskToken(cfg, "openrouter"))
+		if cfg.AzureEndpoint != "" {
+			fmt.Printf("%-20s: %s\n", "AzureEndpoint", cfg.AzureEndpoint)
+			fmt.Printf("%-20s: %s\n", "AzureAPIVersion", cfg.AzureAPIVersion)
+			fmt.Printf("%-20s: %s\n", "AzureToken", resolveAndMaskToken(cfg, "azure"))
+		}
+		if cfg.CustomBaseURL != "" || cfg.APIStyle != "" {
+			fmt.Printf("%-20s: %s\n", "CustomBaseURL", cfg.CustomBaseURL)
+			fmt.Printf("%-20s: %s\n", "CustomToken", resolveAndMaskToken(cfg, "custom"))
+			fmt.Printf("%-20s: %s\n", "APIStyle", cfg.APIStyle)
+		}
 		fmt.Printf("%-20s: %s\n", "Model", cfg.Model)
+		fmt.Printf("%-20s: %s\n", "FallbackModels", strings.Join(cfg.FallbackModels, ","))
+		if cfg.DefaultProvider != "" {
+			fmt.Printf("%-20s: %s\n", "DefaultProvider", cfg.DefaultProvider)
+		}
 		// PromptDirs are already absolute paths
 		fmt.Printf("%-20s: %s\n", "PromptDirectories", strings.Join(cfg.PromptDirs, ","))
 		fmt.Printf("%-20s: %v\n", "WebSearch", cfg.EnableWebSearch)
 		fmt.Printf("%-20s: %d\n", "SessionRetentionDays", cfg.SessionRetentionDays)
+		fmt.Printf("%-20s: %d\n", "MaxSessions", cfg.MaxSessions)
+		fmt.Printf("%-20s: %s\n", "TimeFormat", cfg.TimeFormat)
+		fmt.Printf("%-20s: %s\n", "Timezone", cfg.Timezone)
+		fmt.Printf("%-20s: %s\n", "UserLabel", cfg.UserLabel)
+		fmt.Printf("%-20s: %s\n", "AssistantLabel", cfg.AssistantLabel)
+		fmt.Printf("%-20s: %s\n", "Sampling", cfg.SamplingParams())
+		fmt.Printf("%-20s: %v\n", "Redact", cfg.RedactionEnabled())
+		fmt.Printf("%-20s: %s\n", "RedactPatterns", strings.Join(cfg.RedactPatterns, ","))
+		fmt.Printf("%-20s: %d\n", "MaxIdleConns", cfg.MaxIdleConns)
+		fmt.Printf("%-20s: %s\n", "IdleConnTimeout", cfg.IdleConnTimeout)
+		fmt.Printf("%-20s: %d\n", "MaxDisplayLines", cfg.MaxDisplayLines)
+		fmt.Printf("%-20s: %d\n", "HistorySize", cfg.HistorySize)
+		fmt.Printf("%-20s: %d\n", "MaxRetries", cfg.MaxRetries)
+		fmt.Printf("%-20s: %d\n", "TimeoutRetries", cfg.TimeoutRetries)
+		fmt.Printf("%-20s: %d\n", "OverloadedRetries", cfg.OverloadedRetries)
+		fmt.Printf("%-20s: %d\n", "RequestTimeout", cfg.RequestTimeoutSeconds)
+		if len(cfg.RoleMap) > 0 {
+			fmt.Printf("%-20s: %s\n", "RoleMap", formatRoleMap(cfg.RoleMap))
+		}
 		return nil
 	},
 }
 
+// formatRoleMap formats role_map as provider.role=wire_role pairs, sorted
+func formatRoleMap(roleMap map[string]map[string]string) string {
+	var pairs []string
+	for provider, roles := range roleMap {
+		for role, wireRole := range roles {
+			pairs = append(pairs, fmt.Sprintf("%s.%s=%s", provider, role, wireRole))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
 // maskToken returns a masked version of the token for security
 func maskToken(token string) string {
 	if len(token) <= 8 {
@@ -107,6 +222,83 @@ func resolveAndMaskToken(cfg *config.Config, provider string) string {
 	return maskToken(token)
 }
 
+// configMigrateCmd represents the config migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite a config file that uses legacy settings in the current format",
+	Long: `Rewrite the user config file ($HOME/.config/llmc/config.toml, or --config) in the current format.
+
+Older versions used a single provider, base_url and token. These are converted as follows:
+  token     -> <provider>_token (e.g. openai_token)
+  base_url  -> <provider>_base_url
+  model     -> provider:model (e.g. "gpt-4" becomes "openai:gpt-4")
+  provider  -> removed (the provider is part of model)
+
+Settings already given in the current format are kept. The original file is saved
+with a .bak suffix. Comments in the file are not preserved.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile := cfgFile
+		if configFile == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to get home directory: %v", err)
+			}
+			configFile = filepath.Join(home, ".config", "llmc", "config.toml")
+		}
+
+		changes, err := migrateConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			fmt.Printf("No legacy settings found in %s\n", configFile)
+			return nil
+		}
+		for _, change := range changes {
+			fmt.Printf("  - %s\n", change)
+		}
+		fmt.Printf("Migrated %s (original saved to %s.bak)\n", configFile, configFile)
+		return nil
+	},
+}
+
+// migrateConfigFile converts the legacy settings in the config file at path to the
+// current format, keeping the original as path.bak. It returns a description of
+// each change, or nil (leaving the file untouched) when there are no legacy settings.
+func migrateConfigFile(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	settings := make(map[string]interface{})
+	if _, err := toml.Decode(string(original), &settings); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	changes, err := config.MigrateLegacySettings(settings)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(settings); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %v", err)
+	}
+	if err := fsutil.WriteFileAtomic(path+".bak", original, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("saving backup of config file: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("writing config file: %w", err)
+	}
+	return changes, nil
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configMigrateCmd)
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+)
+
+// echoProvider wraps a provider and writes the system prompt and user message of every
+// request to w before sending it. Credentials in the echoed text are redacted.
+type echoProvider struct {
+	llmc.Provider
+	w        io.Writer
+	redactor *llmc.Redactor
+}
+
+// withEcho wraps provider so that the content of every request is echoed to w
+func withEcho(cfg *config.Config, provider llmc.Provider, w io.Writer) (llmc.Provider, error) {
+	redactor, err := llmc.NewRedactor(cfg.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+	return &echoProvider{Provider: provider, w: w, redactor: redactor}, nil
+}
+
+// Unwrap returns the wrapped provider
+func (e *echoProvider) Unwrap() llmc.Provider {
+	return e.Provider
+}
+
+// Chat echoes the message and sends it
+func (e *echoProvider) Chat(message string) (string, error) {
+	return e.ChatContext(context.Background(), message)
+}
+
+// ChatWithHistory echoes the system prompt and new message and sends them with the history
+func (e *echoProvider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return e.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatContext echoes the message and sends it, aborting when ctx is cancelled
+func (e *echoProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	e.echo("", 0, message)
+	return e.Provider.ChatContext(ctx, message)
+}
+
+// ChatWithHistoryContext echoes the system prompt and new message and sends them with
+// the history, aborting when ctx is cancelled
+func (e *echoProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	e.echo(systemPrompt, len(messages), newMessage)
+	return e.Provider.ChatWithHistoryContext(ctx, systemPrompt, messages, newMessage)
+}
+
+// ChatStream echoes the system prompt and new message and streams the response.
+// The wrapped provider must implement StreamingProvider.
+func (e *echoProvider) ChatStream(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string, onEvent func(llmc.StreamEvent) error) (string, error) {
+	streamer, ok := e.Provider.(llmc.StreamingProvider)
+	if !ok {
+		return "", llmc.ErrStreamingNotSupported
+	}
+	e.echo(systemPrompt, len(messages), newMessage)
+	return streamer.ChatStream(ctx, systemPrompt, messages, newMessage, onEvent)
+}
+
+// echo writes the request content to w
+func (e *echoProvider) echo(systemPrompt string, historyCount int, message string) {
+	if systemPrompt != "" {
+		text, _ := e.redactor.Redact(systemPrompt)
+		fmt.Fprintf(e.w, "--- system ---\n%s\n", text)
+	}
+	if historyCount > 0 {
+		fmt.Fprintf(e.w, "--- history: %d message(s) ---\n", historyCount)
+	}
+	text, _ := e.redactor.Redact(message)
+	fmt.Fprintf(e.w, "--- user ---\n%s\n--- end ---\n", text)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+)
+
+// recordingProvider records the content of the last request
+type recordingProvider struct {
+	fakeProvider
+	systemPrompt string
+	messages     []llmc.Message
+	newMessage   string
+}
+
+func (p *recordingProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	p.newMessage = message
+	return "ok", nil
+}
+
+func (p *recordingProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	p.systemPrompt, p.messages, p.newMessage = systemPrompt, messages, newMessage
+	return "ok", nil
+}
+
+func TestEchoMatchesRequest(t *testing.T) {
+	history := []llmc.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+	tests := []struct {
+		name         string
+		redact       bool
+		systemPrompt string
+		history      []llmc.Message
+		message      string
+		want         string
+	}{
+		{
+			name:    "message only",
+			message: "Review this diff",
+			want:    "--- user ---\nReview this diff\n--- end ---\n",
+		},
+		{
+			name:         "system prompt and history",
+			systemPrompt: "You are a reviewer",
+			history:      history,
+			message:      "And the tests?",
+			want:         "--- system ---\nYou are a reviewer\n--- history: 2 message(s) ---\n--- user ---\nAnd the tests?\n--- end ---\n",
+		},
+		{
+			name:    "secret redacted in echo",
+			message: "key sk-abcdefghijklmnopqrstuvwxyz123456",
+			want:    "--- user ---\nkey [REDACTED]\n--- end ---\n",
+		},
+		{
+			name:    "echo matches redacted request",
+			redact:  true,
+			message: "key sk-abcdefghijklmnopqrstuvwxyz123456",
+			want:    "--- user ---\nkey [REDACTED]\n--- end ---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Redact: tt.redact}
+			recorder := &recordingProvider{}
+			provider, err := withRedaction(cfg, recorder)
+			if err != nil {
+				t.Fatalf("withRedaction() error = %v", err)
+			}
+			var buf bytes.Buffer
+			provider, err = withEcho(cfg, provider, &buf)
+			if err != nil {
+				t.Fatalf("withEcho() error = %v", err)
+			}
+
+			if _, err := provider.ChatWithHistory(tt.systemPrompt, tt.history, tt.message); err != nil {
+				t.Fatalf("ChatWithHistory() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("echo = %q, want %q", got, tt.want)
+			}
+			if recorder.systemPrompt != tt.systemPrompt || len(recorder.messages) != len(tt.history) {
+				t.Errorf("request = (%q, %d messages), want (%q, %d messages)", recorder.systemPrompt, len(recorder.messages), tt.systemPrompt, len(tt.history))
+			}
+			if tt.redact {
+				want := "key " + llmc.RedactedText
+				if recorder.newMessage != want {
+					t.Errorf("sent message = %q, want %q", recorder.newMessage, want)
+				}
+			} else if recorder.newMessage != tt.message {
+				t.Errorf("sent message = %q, want %q", recorder.newMessage, tt.message)
+			}
+		})
+	}
+}
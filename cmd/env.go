@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// envVar describes an environment variable read by llmc
+type envVar struct {
+	Name        string
+	Description string
+	Secret      bool // Mask the value when printing
+}
+
+// envVars lists the environment variables read by llmc, in display order
+var envVars = []envVar{
+	{Name: "LLMC_MODEL", Description: "Model (overrides model in the config file)"},
+	{Name: "LLMC_DEFAULT_PROVIDER", Description: "Provider of model names given without one"},
+	{Name: "LLMC_OPENAI_TOKEN", Description: "OpenAI API token", Secret: true},
+	{Name: "LLMC_GEMINI_TOKEN", Description: "Gemini API token", Secret: true},
+	{Name: "LLMC_ANTHROPIC_TOKEN", Description: "Anthropic API token", Secret: true},
+	{Name: "LLMC_COHERE_TOKEN", Description: "Cohere API token", Secret: true},
+	{Name: "LLMC_OPENROUTER_TOKEN", Description: "OpenRouter API token", Secret: true},
+	{Name: "LLMC_AZURE_TOKEN", Description: "Azure OpenAI API key", Secret: true},
+	{Name: "LLMC_CUSTOM_TOKEN", Description: "Custom provider API token", Secret: true},
+	{Name: "LLMC_OPENAI_BASE_URL", Description: "OpenAI API base URL"},
+	{Name: "LLMC_GEMINI_BASE_URL", Description: "Gemini API base URL"},
+	{Name: "LLMC_ANTHROPIC_BASE_URL", Description: "Anthropic API base URL"},
+	{Name: "LLMC_COHERE_BASE_URL", Description: "Cohere API base URL"},
+	{Name: "LLMC_OPENROUTER_BASE_URL", Description: "OpenRouter API base URL"},
+	{Name: "LLMC_AZURE_ENDPOINT", Description: "Azure OpenAI resource endpoint"},
+	{Name: "LLMC_AZURE_API_VERSION", Description: "Azure OpenAI API version"},
+	{Name: "LLMC_CUSTOM_BASE_URL", Description: "Custom provider API base URL"},
+	{Name: "LLMC_API_STYLE", Description: "API style of the custom provider"},
+	{Name: "LLMC_PROMPT_DIRS", Description: "Prompt directories (comma-separated)"},
+	{Name: "LLMC_ENABLE_WEB_SEARCH", Description: "Enable web search (true or 1)"},
+	{Name: "LLMC_TEMPERATURE", Description: "Sampling temperature"},
+	{Name: "LLMC_TOP_P", Description: "Sampling top_p"},
+	{Name: "LLMC_MAX_TOKENS", Description: "Maximum output tokens"},
+	{Name: "LLMC_REDACT", Description: "Redact credentials in outgoing messages"},
+	{Name: "LLMC_SESSION_MESSAGE_THRESHOLD", Description: "Message count warning threshold for sessions"},
+	{Name: "LLMC_SESSION_RETENTION_DAYS", Description: "Days to keep sessions"},
+	{Name: "LLMC_MAX_SESSIONS", Description: "Number of sessions kept by sessions gc"},
+	{Name: "LLMC_TIME_FORMAT", Description: "Time display format"},
+	{Name: "LLMC_TIMEZONE", Description: "Time display timezone"},
+	{Name: "LLMC_USER_LABEL", Description: "Label of user messages"},
+	{Name: "LLMC_ASSISTANT_LABEL", Description: "Label of assistant messages"},
+	{Name: "LLMC_MAX_IDLE_CONNS", Description: "Maximum idle connections"},
+	{Name: "LLMC_IDLE_CONN_TIMEOUT", Description: "Idle connection timeout"},
+	{Name: "LLMC_MAX_RETRIES", Description: "Retries after rate limit and server errors"},
+	{Name: "LLMC_TIMEOUT_RETRIES", Description: "Retries after request timeouts"},
+	{Name: "LLMC_OVERLOADED_RETRIES", Description: "Retries after overloaded (HTTP 529) responses"},
+	{Name: "LLMC_REQUEST_TIMEOUT_SECONDS", Description: "Timeout of each provider request in seconds"},
+	{Name: "LLMC_MAX_DISPLAY_LINES", Description: "Lines of a response shown at once in interactive mode"},
+	{Name: "LLMC_HISTORY_SIZE", Description: "Entries kept in the interactive input history file"},
+	{Name: "EDITOR", Description: "Editor for --editor"},
+	{Name: "HTTPS_PROXY", Description: "Proxy for HTTPS requests", Secret: true},
+	{Name: "https_proxy", Description: "Proxy for HTTPS requests", Secret: true},
+	{Name: "HTTP_PROXY", Description: "Proxy for HTTP requests", Secret: true},
+	{Name: "http_proxy", Description: "Proxy for HTTP requests", Secret: true},
+	{Name: "NO_PROXY", Description: "Hosts that are not proxied"},
+	{Name: "no_proxy", Description: "Hosts that are not proxied"},
+}
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Show the environment variables llmc reads",
+	Long: `Show the environment variables llmc reads, whether they are set and their values.
+Environment variables take precedence over the config file, so this helps to find out
+why a configuration value does not take effect. Tokens are masked, and so are
+credentials in proxy URLs.
+
+Other config file fields can also be set as LLMC_<FIELD> (e.g. LLMC_FALLBACK_MODELS).
+Use 'llmc config' to see the resulting configuration.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		writeEnv(cmd.OutOrStdout(), os.LookupEnv)
+		return nil
+	},
+}
+
+// writeEnv writes the environment variables in envVars with their status and (masked) values
+func writeEnv(w io.Writer, lookup func(string) (string, bool)) {
+	fmt.Fprintf(w, "%-31s %-6s %-24s %s\n", "NAME", "STATUS", "VALUE", "DESCRIPTION")
+	for _, v := range envVars {
+		value, ok := lookup(v.Name)
+		status := "set"
+		switch {
+		case !ok:
+			status, value = "unset", "-"
+		case value == "":
+			value = "(empty)"
+		case v.Secret:
+			value = maskEnvValue(value)
+		}
+		fmt.Fprintf(w, "%-31s %-6s %-24s %s\n", v.Name, status, value, v.Description)
+	}
+}
+
+// maskEnvValue masks a secret value. URLs (proxies) are shown with their password
+// masked, other values are masked like tokens.
+func maskEnvValue(value string) string {
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.Redacted()
+	}
+	return maskToken(value)
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+}
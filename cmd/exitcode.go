@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by llmc
+const (
+	exitOK            = 0   // Success
+	exitError         = 1   // Generic error
+	exitUsage         = 2   // Invalid command line usage (flags, arguments, missing input)
+	exitConfig        = 3   // Authentication or configuration error
+	exitNetwork       = 4   // Network error, timeout or unavailable service (HTTP 5xx)
+	exitRateLimited   = 5   // Rate limited by the provider (HTTP 429)
+	exitContentFilter = 6   // Request or response blocked by a content filter
+	exitInterrupted   = 130 // Request cancelled with Ctrl+C (SIGINT)
+)
+
+// usageError marks an error caused by invalid command line usage
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// newUsageError wraps err as a usage error
+func newUsageError(err error) error {
+	return &usageError{err: err}
+}
+
+// exitCode returns the process exit code for err
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) || strings.HasPrefix(err.Error(), "unknown command") {
+		return exitUsage
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+
+	switch llmc.ErrorKindOf(err) {
+	case llmc.ErrorKindAuth, llmc.ErrorKindConfig:
+		return exitConfig
+	case llmc.ErrorKindNetwork, llmc.ErrorKindUnavailable:
+		return exitNetwork
+	case llmc.ErrorKindRateLimit:
+		return exitRateLimited
+	case llmc.ErrorKindContentFilter:
+		return exitContentFilter
+	default:
+		return exitError
+	}
+}
+
+// markUsageErrors marks flag and argument validation errors of c and its
+// subcommands as usage errors so that they exit with exitUsage
+func markUsageErrors(c *cobra.Command) {
+	c.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return newUsageError(err)
+	})
+
+	if c.Args != nil {
+		validateArgs := c.Args
+		c.Args = func(c *cobra.Command, args []string) error {
+			if err := validateArgs(c, args); err != nil {
+				return newUsageError(err)
+			}
+			return nil
+		}
+	}
+
+	for _, sub := range c.Commands() {
+		markUsageErrors(sub)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: exitOK},
+		{name: "generic error", err: fmt.Errorf("something went wrong"), want: exitError},
+		{name: "usage error", err: newUsageError(fmt.Errorf("cannot specify both --session and --new-session")), want: exitUsage},
+		{name: "unknown command", err: fmt.Errorf(`unknown command "foo" for "llmc"`), want: exitUsage},
+		{name: "missing token", err: fmt.Errorf("chat request failed: %w", llmc.NewConfigError("openai token is not configured")), want: exitConfig},
+		{name: "auth error", err: fmt.Errorf("chat request failed: %w", llmc.NewAPIError("openai", 401, "", "API request failed (HTTP 401)")), want: exitConfig},
+		{name: "timeout", err: fmt.Errorf("chat request failed: %w", &url.Error{Op: "Post", URL: "https://api.openai.com/v1/responses", Err: context.DeadlineExceeded}), want: exitNetwork},
+		{name: "server error", err: llmc.NewAPIError("gemini", 503, "", "API error (HTTP 503)"), want: exitNetwork},
+		{name: "rate limited", err: llmc.NewAPIError("anthropic", 429, "rate_limit_error", "API error: rate limited"), want: exitRateLimited},
+		{name: "content filter", err: llmc.NewAPIError("openai", 0, "content_filter", "API error: blocked"), want: exitContentFilter},
+		{name: "cancelled", err: errCancelled, want: exitInterrupted},
+		{name: "cancelled request", err: fmt.Errorf("chat request failed: %w", &url.Error{Op: "Post", URL: "https://api.openai.com/v1/responses", Err: context.Canceled}), want: exitInterrupted},
+		{name: "invalid request", err: llmc.NewAPIError("openai", 400, "", "API request failed (HTTP 400)"), want: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{
+		Use:  "sub <id>",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	sub.Flags().Int("limit", 0, "")
+	root.AddCommand(sub)
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+	markUsageErrors(root)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "valid", args: []string{"sub", "abcd"}, want: exitOK},
+		{name: "missing argument", args: []string{"sub"}, want: exitUsage},
+		{name: "invalid flag value", args: []string{"sub", "abcd", "--limit", "many"}, want: exitUsage},
+		{name: "unknown flag", args: []string{"sub", "abcd", "--unknown"}, want: exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.SetArgs(tt.args)
+			if got := exitCode(root.Execute()); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inputHistory persists the input of interactive mode to a history file shared by
+// all interactive sessions, so that earlier input can be recalled in later runs
+type inputHistory struct {
+	path string // History file ("" = history is not saved)
+	size int    // Number of entries kept in the file
+}
+
+// newInputHistory returns the history of interactive mode: the --history-file or
+// the default history file, or none with --no-history or a history_size of 0
+func newInputHistory(path string, size int, disabled bool) *inputHistory {
+	if disabled || size <= 0 {
+		return &inputHistory{}
+	}
+	if path == "" {
+		path = getHistoryFilePath()
+	}
+	return &inputHistory{path: path, size: size}
+}
+
+// enabled reports whether input is saved to the history file
+func (h *inputHistory) enabled() bool {
+	return h.path != ""
+}
+
+// load returns the last size entries of the history file, oldest first.
+// A missing history file is an empty history.
+func (h *inputHistory) load() ([]string, error) {
+	if !h.enabled() {
+		return nil, nil
+	}
+	f, err := os.Open(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			entries = append(entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) > h.size {
+		entries = entries[len(entries)-h.size:]
+	}
+	return entries, nil
+}
+
+// append adds line to the end of the history file
+func (h *inputHistory) append(line string) error {
+	if !h.enabled() || strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// trim rewrites the history file with only its last size entries
+func (h *inputHistory) trim() error {
+	entries, err := h.load()
+	if err != nil || entries == nil {
+		return err
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(entries, "\n")+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// getHistoryFilePath returns the path to the readline history file
+func getHistoryFilePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return homeDir + "/.config/llmc/history"
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInputHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llmc", "history")
+	history := newInputHistory(path, 3, false)
+
+	entries, err := history.load()
+	if err != nil || entries != nil {
+		t.Fatalf("load() = %v, %v for a missing file, want an empty history", entries, err)
+	}
+
+	for _, line := range []string{"one", "two", "  ", "three", "four"} {
+		if err := history.append(line); err != nil {
+			t.Fatalf("append(%q) error = %v", line, err)
+		}
+	}
+
+	entries, err = history.load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if want := []string{"two", "three", "four"}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("load() = %q, want the last %d entries %q", entries, history.size, want)
+	}
+
+	if err := history.trim(); err != nil {
+		t.Fatalf("trim() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "two\nthree\nfour\n"; string(data) != want {
+		t.Errorf("history file after trim() = %q, want %q", data, want)
+	}
+}
+
+func TestInputHistoryDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	for _, history := range []*inputHistory{newInputHistory(path, 100, true), newInputHistory(path, 0, false)} {
+		if err := history.append("secret"); err != nil {
+			t.Fatalf("append() error = %v", err)
+		}
+		if err := history.trim(); err != nil {
+			t.Fatalf("trim() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("history file was written with history disabled (stat error = %v)", err)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// writeJSON writes v to w as indented JSON followed by a newline,
+// or as a single line if compact is set (--compact)
+func writeJSON(w io.Writer, v interface{}, compact bool) error {
+	var data []byte
+	var err error
+	if compact {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// writeRawJSON writes already encoded JSON to w like writeJSON
+func writeRawJSON(w io.Writer, raw []byte, compact bool) error {
+	var buf bytes.Buffer
+	var err error
+	if compact {
+		err = json.Compact(&buf, raw)
+	} else {
+		err = json.Indent(&buf, raw, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, buf.String())
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+func TestWriteJSONCompact(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sess := session.NewSession("openai:gpt-4")
+	sess.AddMessage("user", "first line\nsecond line")
+	sess.AddAssistantMessage("ok", sess.Model)
+	if err := session.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	writers := []struct {
+		name  string
+		write func(buf *bytes.Buffer, compact bool) error
+	}{
+		{"sessions list", func(buf *bytes.Buffer, compact bool) error {
+			return writeSessionsJSON(buf, []session.Session{*sess, *sess}, compact)
+		}},
+		{"sessions show", func(buf *bytes.Buffer, compact bool) error {
+			return writeSessionJSON(buf, sess, false, compact)
+		}},
+		{"raw message", func(buf *bytes.Buffer, compact bool) error {
+			return writeRawMessage(buf, sess, 1, compact)
+		}},
+	}
+
+	for _, tt := range writers {
+		t.Run(tt.name, func(t *testing.T) {
+			var indented, compact bytes.Buffer
+			if err := tt.write(&indented, false); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+			if err := tt.write(&compact, true); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+
+			out := compact.String()
+			if !strings.HasSuffix(out, "\n") || strings.Count(out, "\n") != 1 {
+				t.Errorf("compact output = %q, want a single line", out)
+			}
+			if strings.Count(indented.String(), "\n") <= 1 {
+				t.Errorf("indented output = %q, want multiple lines", indented.String())
+			}
+
+			// Both forms hold the same data
+			var a, b interface{}
+			if err := json.Unmarshal(indented.Bytes(), &a); err != nil {
+				t.Fatalf("indented output is not valid JSON: %v", err)
+			}
+			if err := json.Unmarshal(compact.Bytes(), &b); err != nil {
+				t.Fatalf("compact output is not valid JSON: %v", err)
+			}
+			ja, _ := json.Marshal(a)
+			jb, _ := json.Marshal(b)
+			if string(ja) != string(jb) {
+				t.Errorf("compact output = %s, want %s", jb, ja)
+			}
+		})
+	}
+}
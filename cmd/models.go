@@ -5,14 +5,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/longkey1/llmc/internal/anthropic"
+	"github.com/longkey1/llmc/internal/cohere"
 	"github.com/longkey1/llmc/internal/gemini"
 	"github.com/longkey1/llmc/internal/llmc"
 	"github.com/longkey1/llmc/internal/llmc/config"
+	"github.com/longkey1/llmc/internal/llmc/modelcache"
 	"github.com/longkey1/llmc/internal/openai"
+	"github.com/longkey1/llmc/internal/openrouter"
 	"github.com/spf13/cobra"
 )
 
@@ -22,16 +27,22 @@ var modelsCmd = &cobra.Command{
 	Short: "List available models for the specified provider(s)",
 	Long: `List all available models for the specified provider.
 Fetches the latest model information directly from the provider's API.
+Model lists are cached for 24 hours; use --refresh to fetch them again.
 
-Supported providers: openai, gemini, anthropic
+Supported providers: openai, gemini, anthropic, cohere, openrouter
 
 If no provider is specified, lists models from all providers.
+Use --configured-only to list only providers whose tokens are configured,
+without warnings for the others.
 
 Example:
   llmc models              # List models from all providers
   llmc models openai       # List OpenAI models
   llmc models gemini       # List Gemini models
-  llmc models anthropic    # List Anthropic models`,
+  llmc models anthropic    # List Anthropic models
+  llmc models cohere       # List Cohere models
+  llmc models openrouter   # List OpenRouter models with their prices
+  llmc models --configured-only  # List models you can actually use`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load config to get tokens
@@ -43,22 +54,40 @@ Example:
 		// Save the original default model for comparison
 		originalModel := cfg.Model
 
+		// Load the model cache (an unavailable cache is ignored)
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		cachePath, cacheErr := modelcache.DefaultPath()
+		cache := &modelcache.Cache{}
+		if cacheErr == nil && !refresh {
+			cache = modelcache.Load(cachePath)
+		}
+
 		// Determine which providers to list
 		var providers []string
 		providerExplicitlySpecified := len(args) > 0
 
 		if !providerExplicitlySpecified {
 			// No provider specified, list all
-			providers = []string{openai.ProviderName, gemini.ProviderName, anthropic.ProviderName}
+			providers = []string{openai.ProviderName, gemini.ProviderName, anthropic.ProviderName, cohere.ProviderName, openrouter.ProviderName}
 		} else {
 			targetProvider := args[0]
 			// Validate provider
-			if targetProvider != openai.ProviderName && targetProvider != gemini.ProviderName && targetProvider != anthropic.ProviderName {
-				return fmt.Errorf("unsupported provider '%s'\nSupported providers: openai, gemini, anthropic", targetProvider)
+			if targetProvider != openai.ProviderName && targetProvider != gemini.ProviderName && targetProvider != anthropic.ProviderName && targetProvider != cohere.ProviderName && targetProvider != openrouter.ProviderName {
+				return fmt.Errorf("unsupported provider '%s'\nSupported providers: openai, gemini, anthropic, cohere, openrouter", targetProvider)
 			}
 			providers = []string{targetProvider}
 		}
 
+		// Keep only providers whose tokens resolve
+		configuredOnly, _ := cmd.Flags().GetBool("configured-only")
+		if configuredOnly {
+			providers = configuredProviders(cfg, providers)
+			if len(providers) == 0 {
+				fmt.Println("No configured providers.")
+				return nil
+			}
+		}
+
 		// Collect results and errors for all providers
 		type providerResult struct {
 			provider string
@@ -94,6 +123,17 @@ Example:
 				continue
 			}
 
+			// Use cached models if they are fresh enough
+			if models, ok := cache.Get(targetProvider, modelcache.DefaultTTL); ok {
+				if verbose {
+					fmt.Fprintf(os.Stderr, "Using cached models for provider: %s\n", targetProvider)
+				}
+				markDefaultModel(models, defaultModelID)
+				result.models = models
+				results = append(results, result)
+				continue
+			}
+
 			// Temporarily set the token and model for provider initialization
 			cfg.Model = llmc.FormatModelString(targetProvider, "temp")
 			if targetProvider == openai.ProviderName {
@@ -102,6 +142,10 @@ Example:
 				cfg.GeminiToken = token
 			} else if targetProvider == anthropic.ProviderName {
 				cfg.AnthropicToken = token
+			} else if targetProvider == cohere.ProviderName {
+				cfg.CohereToken = token
+			} else if targetProvider == openrouter.ProviderName {
+				cfg.OpenRouterToken = token
 			}
 
 			if verbose {
@@ -114,14 +158,27 @@ Example:
 			if targetProvider == openai.ProviderName {
 				provider := openai.NewProvider(cfg)
 				provider.SetDebug(verbose)
+				provider.SetTimeout(resolveTimeout(cfg))
 				models, modelsErr = provider.ListModels()
 			} else if targetProvider == gemini.ProviderName {
 				provider := gemini.NewProvider(cfg)
 				provider.SetDebug(verbose)
+				provider.SetTimeout(resolveTimeout(cfg))
 				models, modelsErr = provider.ListModels()
 			} else if targetProvider == anthropic.ProviderName {
 				provider := anthropic.NewProvider(cfg)
 				provider.SetDebug(verbose)
+				provider.SetTimeout(resolveTimeout(cfg))
+				models, modelsErr = provider.ListModels()
+			} else if targetProvider == cohere.ProviderName {
+				provider := cohere.NewProvider(cfg)
+				provider.SetDebug(verbose)
+				provider.SetTimeout(resolveTimeout(cfg))
+				models, modelsErr = provider.ListModels()
+			} else if targetProvider == openrouter.ProviderName {
+				provider := openrouter.NewProvider(cfg)
+				provider.SetDebug(verbose)
+				provider.SetTimeout(resolveTimeout(cfg))
 				models, modelsErr = provider.ListModels()
 			}
 
@@ -137,15 +194,15 @@ Example:
 				continue
 			}
 
-			// Set IsDefault based on the original default model
-			if defaultModelID != "" {
-				for i := range models {
-					if models[i].ID == defaultModelID {
-						models[i].IsDefault = true
-					}
+			// Update the cache (failures only affect the next invocation)
+			if cacheErr == nil {
+				if err := modelcache.Update(cachePath, targetProvider, models); err != nil && verbose {
+					fmt.Fprintf(os.Stderr, "Warning: failed to update model cache: %v\n", err)
 				}
 			}
 
+			markDefaultModel(models, defaultModelID)
+
 			result.models = models
 			results = append(results, result)
 		}
@@ -164,49 +221,7 @@ Example:
 
 			// Display provider name
 			fmt.Printf("Available models for %s:\n\n", result.provider)
-
-			// Calculate column widths
-			maxModelWidth := 15
-			maxModelIDWidth := 15
-			maxDescWidth := 50
-			for _, model := range result.models {
-				modelName := llmc.FormatModelString(result.provider, model.ID)
-				if len(modelName) > maxModelWidth {
-					maxModelWidth = len(modelName)
-				}
-				if len(model.ID) > maxModelIDWidth {
-					maxModelIDWidth = len(model.ID)
-				}
-				if len(model.Description) > maxDescWidth {
-					maxDescWidth = len(model.Description)
-				}
-			}
-
-			// Display header
-			fmt.Printf("%-*s  %-*s  %-*s  %s\n", maxModelWidth, "MODEL", maxModelIDWidth, "MODEL ID", maxDescWidth, "DESCRIPTION", "DEFAULT")
-			fmt.Printf("%s  %s  %s  %s\n",
-				strings.Repeat("-", maxModelWidth),
-				strings.Repeat("-", maxModelIDWidth),
-				strings.Repeat("-", maxDescWidth),
-				strings.Repeat("-", 10))
-
-			// Display models
-			for _, model := range result.models {
-				defaultMark := ""
-				if model.IsDefault {
-					defaultMark = "Yes"
-				}
-				modelName := llmc.FormatModelString(result.provider, model.ID)
-				fmt.Printf("%-*s  %-*s  %-*s  %s\n",
-					maxModelWidth,
-					modelName,
-					maxModelIDWidth,
-					model.ID,
-					maxDescWidth,
-					model.Description,
-					defaultMark)
-			}
-
+			writeModelsTable(os.Stdout, result.provider, result.models)
 		}
 
 		// Display errors at the end
@@ -228,6 +243,99 @@ Example:
 	},
 }
 
+// writeModelsTable writes the models of provider as a table. Context windows not
+// reported by the provider are taken from the built-in table, or shown as "-".
+func writeModelsTable(w io.Writer, provider string, models []llmc.ModelInfo) {
+	// Calculate column widths
+	maxModelWidth := 15
+	maxModelIDWidth := 15
+	maxDescWidth := 50
+	for _, model := range models {
+		modelName := llmc.FormatModelString(provider, model.ID)
+		if len(modelName) > maxModelWidth {
+			maxModelWidth = len(modelName)
+		}
+		if len(model.ID) > maxModelIDWidth {
+			maxModelIDWidth = len(model.ID)
+		}
+		if len(model.Description) > maxDescWidth {
+			maxDescWidth = len(model.Description)
+		}
+	}
+	const contextWidth = 7
+
+	// Display header
+	fmt.Fprintf(w, "%-*s  %-*s  %-*s  %-*s  %s\n", maxModelWidth, "MODEL", maxModelIDWidth, "MODEL ID", maxDescWidth, "DESCRIPTION", contextWidth, "CONTEXT", "DEFAULT")
+	fmt.Fprintf(w, "%s  %s  %s  %s  %s\n",
+		strings.Repeat("-", maxModelWidth),
+		strings.Repeat("-", maxModelIDWidth),
+		strings.Repeat("-", maxDescWidth),
+		strings.Repeat("-", contextWidth),
+		strings.Repeat("-", 10))
+
+	// Display models
+	for _, model := range models {
+		defaultMark := ""
+		if model.IsDefault {
+			defaultMark = "Yes"
+		}
+		contextWindow := model.ContextWindow
+		if contextWindow == 0 {
+			contextWindow = llmc.KnownContextWindow(model.ID)
+		}
+		modelName := llmc.FormatModelString(provider, model.ID)
+		fmt.Fprintf(w, "%-*s  %-*s  %-*s  %-*s  %s\n",
+			maxModelWidth,
+			modelName,
+			maxModelIDWidth,
+			model.ID,
+			maxDescWidth,
+			model.Description,
+			contextWidth,
+			formatContextWindow(contextWindow),
+			defaultMark)
+	}
+}
+
+// formatContextWindow formats a context window in tokens, e.g. "128K" or "1M" ("-" if unknown)
+func formatContextWindow(tokens int) string {
+	switch {
+	case tokens <= 0:
+		return "-"
+	case tokens >= 1000000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(tokens)/1000000), ".0") + "M"
+	case tokens >= 1000:
+		return fmt.Sprintf("%dK", tokens/1000)
+	}
+	return strconv.Itoa(tokens)
+}
+
+// configuredProviders returns the providers whose tokens are configured in cfg
+func configuredProviders(cfg *config.Config, providers []string) []string {
+	var configured []string
+	for _, provider := range providers {
+		if _, err := cfg.GetToken(provider); err == nil {
+			configured = append(configured, provider)
+		}
+	}
+	return configured
+}
+
+// markDefaultModel sets IsDefault on the model whose ID is defaultModelID (if any)
+func markDefaultModel(models []llmc.ModelInfo, defaultModelID string) {
+	if defaultModelID == "" {
+		return
+	}
+	for i := range models {
+		if models[i].ID == defaultModelID {
+			models[i].IsDefault = true
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(modelsCmd)
+
+	modelsCmd.Flags().Bool("refresh", false, "Ignore the model cache and fetch the latest models from the provider")
+	modelsCmd.Flags().Bool("configured-only", false, "List only providers whose tokens are configured, skipping the others silently")
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+)
+
+func TestConfiguredProviders(t *testing.T) {
+	all := []string{"openai", "gemini", "anthropic"}
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		providers []string
+		want      []string
+	}{
+		{
+			name:      "only configured providers are kept",
+			cfg:       &config.Config{OpenAIToken: "sk-test", AnthropicToken: "sk-ant-test"},
+			providers: all,
+			want:      []string{"openai", "anthropic"},
+		},
+		{
+			name:      "invalid token is treated as unconfigured",
+			cfg:       &config.Config{OpenAIToken: "$OPENAI_API_KEY", GeminiToken: "gm-test"},
+			providers: all,
+			want:      []string{"gemini"},
+		},
+		{
+			name:      "explicit unconfigured provider is omitted",
+			cfg:       &config.Config{OpenAIToken: "sk-test"},
+			providers: []string{"gemini"},
+			want:      nil,
+		},
+		{
+			name:      "nothing configured",
+			cfg:       &config.Config{},
+			providers: all,
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configuredProviders(tt.cfg, tt.providers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configuredProviders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteModelsTableContextWindow(t *testing.T) {
+	models := []llmc.ModelInfo{
+		{ID: "gpt-4o-2024-08-06"},
+		{ID: "reported-model", ContextWindow: 32768},
+		{ID: "unknown-model"},
+	}
+
+	var buf bytes.Buffer
+	writeModelsTable(&buf, "openai", models)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("table has %d lines, want 5:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "CONTEXT") {
+		t.Errorf("header %q has no CONTEXT column", lines[0])
+	}
+	for i, want := range []string{"128K", "32K", "-"} {
+		fields := strings.Fields(lines[i+2])
+		if got := fields[len(fields)-1]; got != want {
+			t.Errorf("context of %s = %q, want %q", models[i].ID, got, want)
+		}
+	}
+}
+
+func TestFormatContextWindow(t *testing.T) {
+	tests := map[int]string{
+		0:       "-",
+		512:     "512",
+		8192:    "8K",
+		200000:  "200K",
+		1048576: "1M",
+		2097152: "2.1M",
+	}
+	for tokens, want := range tests {
+		if got := formatContextWindow(tokens); got != want {
+			t.Errorf("formatContextWindow(%d) = %q, want %q", tokens, got, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,12 +14,16 @@ import (
 	"github.com/longkey1/llmc/internal/llmc/config"
 	promptpkg "github.com/longkey1/llmc/internal/llmc/prompt"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var promptsJSON bool
+
 // promptCmd represents the prompts command
 var promptCmd = &cobra.Command{
-	Use:   "prompts",
-	Short: "List available prompt templates",
+	Use:     "prompts",
+	Aliases: []string{"prompt"},
+	Short:   "List available prompt templates",
 	Long: `List all available prompt templates from the configured prompt directories.
 This command recursively scans all prompt directories specified in the configuration and displays
 the names of available .toml prompt files, including those in subdirectories.
@@ -28,7 +33,9 @@ system = "System prompt with optional {{input}} placeholder"
 user = "User prompt with optional {{input}} placeholder"
 model = "optional-model-name"  # Optional: overrides the default model for this prompt
 
-Prompt names are displayed in a table format with the relative path from the prompt directory root and the full file path.`,
+Prompt names are displayed in a table format with the relative path from the prompt directory root and the full file path.
+Use --json to print a JSON array of {name, dir, path} objects instead, where dir is the
+prompt directory the template was found in. Nested names always use forward slashes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load configuration from file
 		cfg, err := config.LoadConfig()
@@ -41,134 +48,14 @@ Prompt names are displayed in a table format with the relative path from the pro
 			fmt.Fprintf(os.Stderr, "Prompt directories: %v\n", cfg.PromptDirs)
 		}
 
-		// promptInfo holds information about each prompt
-		type promptInfo struct {
-			path      string
-			model     string
-			webSearch string
-		}
-
-		// Collect all prompt files from all directories
-		var allPrompts []string
-		promptInfoMap := make(map[string]*promptInfo) // prompt name -> prompt info
-
-		for _, promptDir := range cfg.PromptDirs {
-			// promptDir is already an absolute path
-			// Check if directory exists
-			if _, err := os.Stat(promptDir); os.IsNotExist(err) {
-				if verbose {
-					fmt.Fprintf(os.Stderr, "Prompt directory does not exist: %s\n", promptDir)
-				}
-				continue
-			}
-
-			// Recursively find all .toml files
-			err = filepath.Walk(promptDir, func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-
-				// Skip directories
-				if info.IsDir() {
-					return nil
-				}
-
-				// Check if it's a .toml file
-				if !strings.HasSuffix(info.Name(), ".toml") {
-					return nil
-				}
-
-				// Calculate relative path from prompt directory
-				relPath, err := filepath.Rel(promptDir, path)
-				if err != nil {
-					if verbose {
-						fmt.Fprintf(os.Stderr, "Error calculating relative path for %s: %v\n", path, err)
-					}
-					return nil
-				}
-
-				// Remove .toml extension to get prompt name
-				promptName := strings.TrimSuffix(relPath, ".toml")
-
-				// Convert Windows path separators to forward slashes for consistency
-				promptName = filepath.ToSlash(promptName)
-
-				// Load prompt file to get model and web_search settings
-				promptData, err := promptpkg.LoadPrompt(path)
-				if err != nil {
-					if verbose {
-						fmt.Fprintf(os.Stderr, "Warning: Failed to load prompt '%s': %v\n", promptName, err)
-					}
-					// Continue even if we can't load the prompt
-				}
-
-				// Extract model and web_search info
-				// Use default values in parentheses if not set in prompt
-				modelStr := ""
-				webSearchStr := ""
-				if promptData != nil {
-					if promptData.Model != nil {
-						modelStr = *promptData.Model
-					} else {
-						modelStr = fmt.Sprintf("(%s)", cfg.Model)
-					}
-					if promptData.WebSearch != nil {
-						if *promptData.WebSearch {
-							webSearchStr = "enabled"
-						} else {
-							webSearchStr = "disabled"
-						}
-					} else {
-						if cfg.EnableWebSearch {
-							webSearchStr = "(enabled)"
-						} else {
-							webSearchStr = "(disabled)"
-						}
-					}
-				} else {
-					// If prompt failed to load, show defaults
-					modelStr = fmt.Sprintf("(%s)", cfg.Model)
-					if cfg.EnableWebSearch {
-						webSearchStr = "(enabled)"
-					} else {
-						webSearchStr = "(disabled)"
-					}
-				}
-
-				// Check if we already found this prompt in another directory
-				_, exists := promptInfoMap[promptName]
-				if exists {
-					if verbose {
-						existingPath := promptInfoMap[promptName].path
-						fmt.Fprintf(os.Stderr, "Warning: Prompt '%s' found in multiple directories: %s and %s (using %s)\n",
-							promptName, filepath.Dir(existingPath), promptDir, promptDir)
-					}
-				}
-				// Always update with the current directory (later directories take precedence)
-				promptInfoMap[promptName] = &promptInfo{
-					path:      path,
-					model:     modelStr,
-					webSearch: webSearchStr,
-				}
-				// Only add to allPrompts if this is the first time we've seen this prompt
-				if !exists {
-					allPrompts = append(allPrompts, promptName)
-				}
-
-				return nil
-			})
+		templates := findPromptTemplates(cfg)
 
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error walking prompt directory %s: %v\n", promptDir, err)
-				continue
-			}
+		if promptsJSON {
+			return writePromptsJSON(os.Stdout, templates, compactJSON)
 		}
 
-		// Sort prompts alphabetically
-		sort.Strings(allPrompts)
-
 		// Display results
-		if len(allPrompts) == 0 {
+		if len(templates) == 0 {
 			fmt.Println("No prompt templates found.")
 			fmt.Println("Create .toml files in the following directories:")
 			for _, promptDir := range cfg.PromptDirs {
@@ -178,17 +65,16 @@ Prompt names are displayed in a table format with the relative path from the pro
 			return nil
 		}
 
-		fmt.Printf("Available prompt templates (%d found):\n\n", len(allPrompts))
+		fmt.Printf("Available prompt templates (%d found):\n\n", len(templates))
 
 		// Calculate maximum widths for columns (with minimum values)
 		maxNameWidth := 15
 		maxModelWidth := 10
 		maxWebSearchWidth := 10
-		for _, promptName := range allPrompts {
-			if len(promptName) > maxNameWidth {
-				maxNameWidth = len(promptName)
+		for _, info := range templates {
+			if len(info.name) > maxNameWidth {
+				maxNameWidth = len(info.name)
 			}
-			info := promptInfoMap[promptName]
 			if len(info.model) > maxModelWidth {
 				maxModelWidth = len(info.model)
 			}
@@ -209,10 +95,9 @@ Prompt names are displayed in a table format with the relative path from the pro
 			strings.Repeat("-", maxWebSearchWidth),
 			strings.Repeat("-", 60))
 
-		for _, promptName := range allPrompts {
-			info := promptInfoMap[promptName]
+		for _, info := range templates {
 			fmt.Printf("%-*s  %-*s  %-*s  %s\n",
-				maxNameWidth, promptName,
+				maxNameWidth, info.name,
 				maxModelWidth, info.model,
 				maxWebSearchWidth, info.webSearch,
 				info.path)
@@ -220,10 +105,436 @@ Prompt names are displayed in a table format with the relative path from the pro
 
 		fmt.Printf("\nUse a prompt template with: llmc chat --prompt <name> [message]\n")
 		fmt.Printf("Example: llmc chat --prompt foo/bar [message]\n")
+		fmt.Printf("     or: llmc prompts run foo/bar [message]\n")
 		return nil
 	},
 }
 
+// promptTemplate is a prompt template found in the prompt directories
+type promptTemplate struct {
+	name      string // Relative path without .toml, with forward slashes
+	dir       string // Prompt directory the template was found in
+	path      string // Path of the template file
+	model     string // Model, or the default in parentheses
+	webSearch string // Web search setting, or the default in parentheses
+}
+
+// findPromptTemplates recursively scans the prompt directories of cfg and returns
+// the templates sorted by name. When a name exists in several directories, the
+// later directory takes precedence.
+func findPromptTemplates(cfg *config.Config) []promptTemplate {
+	templates := make(map[string]*promptTemplate) // prompt name -> template
+
+	for _, promptDir := range cfg.PromptDirs {
+		// promptDir is already an absolute path
+		// Check if directory exists
+		if _, err := os.Stat(promptDir); os.IsNotExist(err) {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Prompt directory does not exist: %s\n", promptDir)
+			}
+			continue
+		}
+
+		// Recursively find all .toml files
+		err := filepath.Walk(promptDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// Skip directories
+			if info.IsDir() {
+				return nil
+			}
+
+			// Check if it's a .toml file (test fixtures are not templates)
+			if !strings.HasSuffix(info.Name(), ".toml") || promptpkg.IsTestFile(info.Name()) {
+				return nil
+			}
+
+			// Calculate relative path from prompt directory
+			relPath, err := filepath.Rel(promptDir, path)
+			if err != nil {
+				if verbose {
+					fmt.Fprintf(os.Stderr, "Error calculating relative path for %s: %v\n", path, err)
+				}
+				return nil
+			}
+
+			// Remove .toml extension to get prompt name
+			promptName := strings.TrimSuffix(relPath, ".toml")
+
+			// Convert Windows path separators to forward slashes for consistency
+			promptName = filepath.ToSlash(promptName)
+
+			// Load prompt file to get model and web_search settings
+			promptData, err := promptpkg.LoadPrompt(path)
+			if err != nil {
+				if verbose {
+					fmt.Fprintf(os.Stderr, "Warning: Failed to load prompt '%s': %v\n", promptName, err)
+				}
+				// Continue even if we can't load the prompt
+			}
+
+			// Extract model and web_search info
+			// Use default values in parentheses if not set in prompt
+			modelStr := fmt.Sprintf("(%s)", cfg.Model)
+			webSearchStr := "(disabled)"
+			if cfg.EnableWebSearch {
+				webSearchStr = "(enabled)"
+			}
+			if promptData != nil {
+				if promptData.Model != nil {
+					modelStr = *promptData.Model
+				}
+				if promptData.WebSearch != nil {
+					if *promptData.WebSearch {
+						webSearchStr = "enabled"
+					} else {
+						webSearchStr = "disabled"
+					}
+				}
+			}
+
+			// Check if we already found this prompt in another directory
+			if existing, exists := templates[promptName]; exists && verbose {
+				fmt.Fprintf(os.Stderr, "Warning: Prompt '%s' found in multiple directories: %s and %s (using %s)\n",
+					promptName, existing.dir, promptDir, promptDir)
+			}
+			// Always update with the current directory (later directories take precedence)
+			templates[promptName] = &promptTemplate{
+				name:      promptName,
+				dir:       promptDir,
+				path:      path,
+				model:     modelStr,
+				webSearch: webSearchStr,
+			}
+			return nil
+		})
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error walking prompt directory %s: %v\n", promptDir, err)
+			continue
+		}
+	}
+
+	result := make([]promptTemplate, 0, len(templates))
+	for _, template := range templates {
+		result = append(result, *template)
+	}
+	// Sort prompts alphabetically
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// promptTemplateJSON is the JSON form of a prompt template for prompts --json
+type promptTemplateJSON struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+	Path string `json:"path"`
+}
+
+// writePromptsJSON writes the templates to w as a JSON array (an empty array when there are none)
+func writePromptsJSON(w io.Writer, templates []promptTemplate, compact bool) error {
+	entries := make([]promptTemplateJSON, 0, len(templates))
+	for _, template := range templates {
+		entries = append(entries, promptTemplateJSON{
+			Name: template.name,
+			Dir:  template.dir,
+			Path: template.path,
+		})
+	}
+	return writeJSON(w, entries, compact)
+}
+
+// promptsRunCmd represents the prompts run command
+var promptsRunCmd = &cobra.Command{
+	Use:   "run <name> [input]",
+	Short: "Run a prompt template",
+	Long: `Run a prompt template with the given input and print the response.
+This is equivalent to 'llmc chat --prompt <name>'.
+
+If no input is provided as an argument, it reads from stdin.
+The template is checked before any request is sent: an unknown template or a
+{{placeholder}} without a matching --arg is reported as an error.
+Use --list-args to print the arguments the template expects without running it.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration from file
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		if listArgs {
+			return listPromptArgs(os.Stdout, args[0], cfg.PromptDirs)
+		}
+
+		templateArgs, err := promptpkg.ParseArgs(argFlags)
+		if err != nil {
+			return newUsageError(fmt.Errorf("parsing arguments: %w", err))
+		}
+		if err := checkPromptArgs(args[0], cfg.PromptDirs, templateArgs, stdinAs); err != nil {
+			return err
+		}
+
+		prompt = args[0]
+		return runChat(cmd, args[1:])
+	},
+}
+
+// promptsTestCmd represents the prompts test command
+var promptsTestCmd = &cobra.Command{
+	Use:   "test [name...]",
+	Short: "Run the tests of prompt templates",
+	Long: `Run the tests of prompt templates and report which pass or fail.
+
+The tests of a template are read from a fixture next to it, named <template>.test.toml
+(e.g. review.test.toml for review.toml):
+
+[[cases]]
+name = "finds unchecked error"
+input = "f, _ := os.Open(path)"
+args = { lang = "Go" }
+expect_contains = ["error"]
+expect_regex = ["(?i)os\\.Open"]
+recorded_response = "The error returned by os.Open is ignored."  # Used with --offline
+
+Each case runs the template with its input and args, and the response must contain
+every expect_contains string and match every expect_regex pattern.
+With --offline, no request is sent and recorded_response is checked instead.
+
+Without names, the tests of all templates with a fixture are run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		offline, _ := cmd.Flags().GetBool("offline")
+		modelFlag, _ := cmd.Flags().GetString("model")
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		tests, err := findPromptTests(args, cfg.PromptDirs)
+		if err != nil {
+			return err
+		}
+		if len(tests) == 0 {
+			fmt.Println("No prompt tests found.")
+			fmt.Printf("Add cases to <template>%s next to a template to test it.\n", promptpkg.TestFileSuffix)
+			return nil
+		}
+
+		run := func(name string, tc promptpkg.TestCase) (string, error) {
+			if offline {
+				if tc.RecordedResponse == "" {
+					return "", fmt.Errorf("no recorded_response for --offline")
+				}
+				return tc.RecordedResponse, nil
+			}
+			return runPromptTestCase(cfg, name, tc, modelFlag)
+		}
+
+		passed, failed := runPromptTests(os.Stdout, tests, run)
+		fmt.Printf("\n%d passed, %d failed\n", passed, failed)
+		if failed > 0 {
+			return fmt.Errorf("%d of %d prompt test case(s) failed", failed, passed+failed)
+		}
+		return nil
+	},
+}
+
+// promptTest is a prompt template with a test fixture
+type promptTest struct {
+	name     string // Prompt name
+	testPath string // Path of the test fixture
+}
+
+// findPromptTests returns the test fixtures of the named prompts,
+// or of all prompts with a fixture when names is empty
+func findPromptTests(names []string, promptDirs []string) ([]promptTest, error) {
+	var tests []promptTest
+	if len(names) > 0 {
+		for _, name := range names {
+			promptPath, err := promptpkg.FindPrompt(name, promptDirs)
+			if err != nil {
+				return nil, newUsageError(err)
+			}
+			testPath := promptpkg.TestFilePath(promptPath)
+			if _, err := os.Stat(testPath); err != nil {
+				return nil, newUsageError(fmt.Errorf("no tests for prompt '%s' (expected %s)", name, testPath))
+			}
+			tests = append(tests, promptTest{name: name, testPath: testPath})
+		}
+		return tests, nil
+	}
+
+	// Later directories take precedence, as for templates
+	testPaths := make(map[string]string)
+	for _, promptDir := range promptDirs {
+		if _, err := os.Stat(promptDir); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.Walk(promptDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !promptpkg.IsTestFile(info.Name()) {
+				return err
+			}
+			relPath, err := filepath.Rel(promptDir, path)
+			if err != nil {
+				return nil
+			}
+			name := filepath.ToSlash(strings.TrimSuffix(relPath, promptpkg.TestFileSuffix))
+			testPaths[name] = path
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walking prompt directory %s: %w", promptDir, err)
+		}
+	}
+
+	for name, testPath := range testPaths {
+		tests = append(tests, promptTest{name: name, testPath: testPath})
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].name < tests[j].name
+	})
+	return tests, nil
+}
+
+// runPromptTests runs every case of tests with run, writes the result of each to w
+// and returns the number of passed and failed cases
+func runPromptTests(w io.Writer, tests []promptTest, run func(name string, tc promptpkg.TestCase) (string, error)) (int, int) {
+	passed, failed := 0, 0
+	for _, test := range tests {
+		file, err := promptpkg.LoadTestFile(test.testPath)
+		if err != nil {
+			fmt.Fprintf(w, "FAIL %s\n  %s: %v\n", test.name, test.testPath, err)
+			failed++
+			continue
+		}
+
+		for _, tc := range file.Cases {
+			var failures []string
+			response, err := run(test.name, tc)
+			if err != nil {
+				failures = []string{err.Error()}
+			} else {
+				failures = tc.Check(response)
+			}
+
+			if len(failures) == 0 {
+				fmt.Fprintf(w, "PASS %s: %s\n", test.name, tc.Name)
+				passed++
+				continue
+			}
+			fmt.Fprintf(w, "FAIL %s: %s\n", test.name, tc.Name)
+			for _, failure := range failures {
+				fmt.Fprintf(w, "  %s\n", failure)
+			}
+			failed++
+		}
+	}
+	return passed, failed
+}
+
+// runPromptTestCase sends the template formatted with the input and args of tc and
+// returns the response. The model is taken from modelFlag, the template or cfg, in that order.
+func runPromptTestCase(cfg *config.Config, name string, tc promptpkg.TestCase, modelFlag string) (string, error) {
+	if err := checkPromptArgs(name, cfg.PromptDirs, tc.Args, ""); err != nil {
+		return "", err
+	}
+	message, promptModel, promptWebSearch, promptSampling, err := promptpkg.FormatMessageWithArgs(tc.Input, name, cfg.PromptDirs, tc.Args)
+	if err != nil {
+		return "", err
+	}
+
+	caseCfg := *cfg
+	if modelFlag != "" {
+		caseCfg.Model = cfg.QualifyModel(modelFlag)
+	} else if promptModel != nil {
+		caseCfg.Model = *promptModel
+	}
+	if promptWebSearch != nil {
+		caseCfg.EnableWebSearch = *promptWebSearch
+	}
+
+	provider, err := newChatProvider(&caseCfg, false)
+	if err != nil {
+		return "", err
+	}
+	provider.SetWebSearch(caseCfg.EnableWebSearch)
+	provider.SetDebug(verbose)
+	provider.SetTimeout(resolveTimeout(&caseCfg))
+	provider.SetSampling(caseCfg.SamplingParams().Merge(promptSampling))
+	return provider.Chat(message)
+}
+
+// checkPromptArgs checks that the prompt template exists and that all of its
+// placeholders are given by args (or bound to stdin with stdinKey)
+func checkPromptArgs(promptName string, promptDirs []string, args map[string]string, stdinKey string) error {
+	promptPath, err := promptpkg.FindPrompt(promptName, promptDirs)
+	if err != nil {
+		return newUsageError(err)
+	}
+	promptData, err := promptpkg.LoadPrompt(promptPath)
+	if err != nil {
+		return fmt.Errorf("loading prompt '%s': %w", promptName, err)
+	}
+	if err := promptData.ValidateModel(); err != nil {
+		return fmt.Errorf("prompt template '%s' (%s): %w", promptName, promptPath, err)
+	}
+
+	given := args
+	if stdinKey != "" {
+		given = promptpkg.MergeArgs(args, map[string]string{stdinKey: ""})
+	}
+	if missing := promptpkg.MissingArgs(promptData, given); len(missing) > 0 {
+		return newUsageError(fmt.Errorf("missing arguments for prompt '%s': %s\nUse --arg key:value to set them", promptName, strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// listPromptArgs writes the names of the arguments the prompt template expects, one per line
+func listPromptArgs(w io.Writer, promptName string, promptDirs []string) error {
+	promptPath, err := promptpkg.FindPrompt(promptName, promptDirs)
+	if err != nil {
+		return newUsageError(err)
+	}
+	promptData, err := promptpkg.LoadPrompt(promptPath)
+	if err != nil {
+		return fmt.Errorf("loading prompt '%s': %w", promptName, err)
+	}
+
+	names := promptpkg.TemplateArgs(promptData)
+	if len(names) == 0 {
+		fmt.Fprintf(os.Stderr, "Prompt '%s' takes no arguments.\n", promptName)
+		return nil
+	}
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(promptCmd)
+	promptCmd.AddCommand(promptsRunCmd)
+	promptCmd.AddCommand(promptsTestCmd)
+
+	promptCmd.Flags().BoolVar(&promptsJSON, "json", false, "Output the templates as a JSON array of {name, dir, path} objects")
+
+	promptsTestCmd.Flags().Bool("offline", false, "Check the recorded_response of each case instead of calling the model")
+	promptsTestCmd.Flags().StringP("model", "m", "", "Model to test with (format: provider:model; default: the template's or configured model)")
+
+	// Flags shared with the chat command
+	promptsRunCmd.Flags().StringVarP(&model, "model", "m", viper.GetString("model"), "Model to use (format: provider:model, e.g., openai:gpt-4)")
+	promptsRunCmd.Flags().StringArrayVar(&argFlags, "arg", []string{}, "Key-value pairs for prompt template (format: key:value)")
+	promptsRunCmd.Flags().BoolVarP(&useEditor, "editor", "e", false, "Use default editor (from EDITOR environment variable) to compose input")
+	promptsRunCmd.Flags().BoolVar(&webSearch, "web-search", false, "Enable web search for real-time information")
+	promptsRunCmd.Flags().BoolVar(&allowEmptyInput, "allow-empty-input", false, "Allow running the template without input")
+	promptsRunCmd.Flags().StringVar(&stdinAs, "stdin-as", "", "Bind piped stdin to a named prompt template argument (e.g., --stdin-as diff for {{diff}})")
+	promptsRunCmd.Flags().StringVar(&outputFormat, "output", outputText, "Output format: text or ndjson (stream response events as JSON lines)")
+	addSamplingFlags(promptsRunCmd)
+	promptsRunCmd.Flags().BoolVar(&listArgs, "list-args", false, "List the arguments ({{placeholder}} names) the template expects and exit")
+	promptsRunCmd.Flags().BoolVar(&noFallback, "no-fallback", false, "Disable falling back to fallback_models when the model is unavailable")
 }
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc/config"
+	promptpkg "github.com/longkey1/llmc/internal/llmc/prompt"
+)
+
+func TestWritePromptsJSON(t *testing.T) {
+	systemDir, userDir := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(systemDir, "review.toml"):            `user = "{{input}}"`,
+		filepath.Join(systemDir, "shared.toml"):            `user = "{{input}}"`,
+		filepath.Join(userDir, "shared.toml"):              `user = "{{input}}"`,
+		filepath.Join(userDir, "git", "commit.toml"):       `user = "{{input}}"`,
+		filepath.Join(userDir, "git", "commit.test.toml"):  `[[cases]]`,
+		filepath.Join(userDir, "git", "nested", "pr.toml"): `user = "{{input}}"`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{Model: "openai:gpt-4.1", PromptDirs: []string{systemDir, userDir}}
+	var buf bytes.Buffer
+	if err := writePromptsJSON(&buf, findPromptTemplates(cfg), false); err != nil {
+		t.Fatalf("writePromptsJSON() error = %v", err)
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	want := []map[string]string{
+		{"name": "git/commit", "dir": userDir, "path": filepath.Join(userDir, "git", "commit.toml")},
+		{"name": "git/nested/pr", "dir": userDir, "path": filepath.Join(userDir, "git", "nested", "pr.toml")},
+		{"name": "review", "dir": systemDir, "path": filepath.Join(systemDir, "review.toml")},
+		{"name": "shared", "dir": userDir, "path": filepath.Join(userDir, "shared.toml")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writePromptsJSON() = %v, want %v", got, want)
+	}
+
+	buf.Reset()
+	if err := writePromptsJSON(&buf, nil, true); err != nil {
+		t.Fatalf("writePromptsJSON() error = %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("writePromptsJSON() with no templates = %q, want []", got)
+	}
+}
+
+func TestCheckPromptArgs(t *testing.T) {
+	dir := t.TempDir()
+	content := `system = "Translate into {{lang}}."
+user = "{{input}} ({{tone}})"
+`
+	if err := os.WriteFile(filepath.Join(dir, "translate.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		promptName string
+		args       map[string]string
+		stdinKey   string
+		wantErr    string
+	}{
+		{
+			name:       "all arguments given",
+			promptName: "translate",
+			args:       map[string]string{"lang": "Japanese", "tone": "formal"},
+		},
+		{
+			name:       "missing arguments",
+			promptName: "translate",
+			args:       map[string]string{},
+			wantErr:    "missing arguments for prompt 'translate': lang, tone",
+		},
+		{
+			name:       "argument bound to stdin",
+			promptName: "translate",
+			args:       map[string]string{"lang": "Japanese"},
+			stdinKey:   "tone",
+		},
+		{
+			name:       "unknown template",
+			promptName: "unknown",
+			args:       map[string]string{},
+			wantErr:    "prompt file 'unknown.toml' not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPromptArgs(tt.promptName, []string{dir}, tt.args, tt.stdinKey)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkPromptArgs() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkPromptArgs() error = %v, want containing %q", err, tt.wantErr)
+			}
+			var usageErr *usageError
+			if !errors.As(err, &usageErr) {
+				t.Errorf("checkPromptArgs() error is not a usage error")
+			}
+		})
+	}
+}
+
+func TestListPromptArgs(t *testing.T) {
+	dir := t.TempDir()
+	content := `system = "Translate into {{lang}}."
+user = "{{input}} ({{tone}}, {{lang}})"
+`
+	if err := os.WriteFile(filepath.Join(dir, "translate.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := listPromptArgs(&buf, "translate", []string{dir}); err != nil {
+		t.Fatalf("listPromptArgs() error = %v", err)
+	}
+	if got, want := buf.String(), "lang\ntone\n"; got != want {
+		t.Errorf("listPromptArgs() = %q, want %q", got, want)
+	}
+
+	if err := listPromptArgs(&buf, "unknown", []string{dir}); err == nil {
+		t.Error("listPromptArgs() error = nil, want error for unknown template")
+	}
+}
+
+func TestRunPromptTestsOffline(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"translate.toml": `user = "Translate into {{lang}}: {{input}}"`,
+		"translate.test.toml": `[[cases]]
+name = "passes"
+input = "Good morning"
+args = { lang = "Japanese" }
+expect_contains = ["おはよう"]
+recorded_response = "おはようございます"
+
+[[cases]]
+name = "fails"
+input = "Good night"
+args = { lang = "Japanese" }
+expect_regex = ["^おやすみ"]
+recorded_response = "こんばんは"
+`,
+		"untested.toml": `user = "{{input}}"`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests, err := findPromptTests(nil, []string{dir})
+	if err != nil {
+		t.Fatalf("findPromptTests() error = %v", err)
+	}
+	if len(tests) != 1 || tests[0].name != "translate" {
+		t.Fatalf("findPromptTests() = %v, want only translate", tests)
+	}
+
+	var buf bytes.Buffer
+	passed, failed := runPromptTests(&buf, tests, func(name string, tc promptpkg.TestCase) (string, error) {
+		return tc.RecordedResponse, nil
+	})
+	if passed != 1 || failed != 1 {
+		t.Errorf("runPromptTests() = %d passed, %d failed, want 1 and 1", passed, failed)
+	}
+	for _, want := range []string{"PASS translate: passes", "FAIL translate: fails", `response does not match "^おやすみ"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("runPromptTests() output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if _, err := findPromptTests([]string{"untested"}, []string{dir}); err == nil {
+		t.Error("findPromptTests() error = nil, want error for a prompt without tests")
+	}
+}
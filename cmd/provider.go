@@ -2,21 +2,34 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/longkey1/llmc/internal/anthropic"
+	"github.com/longkey1/llmc/internal/azureopenai"
+	"github.com/longkey1/llmc/internal/cohere"
 	"github.com/longkey1/llmc/internal/gemini"
 	"github.com/longkey1/llmc/internal/llmc"
 	"github.com/longkey1/llmc/internal/llmc/config"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
 	"github.com/longkey1/llmc/internal/openai"
+	"github.com/longkey1/llmc/internal/openrouter"
 )
 
-// newProvider creates a new provider instance based on the configuration
+// newProvider creates a new provider instance based on the configuration.
+// The shared HTTP transport and request retries are configured with the settings of cfg.
 func newProvider(cfg *config.Config) (llmc.Provider, error) {
 	provider, _, err := llmc.ParseModelString(cfg.Model)
 	if err != nil {
 		return nil, fmt.Errorf("invalid model format: %w", err)
 	}
 
+	transportOpts := cfg.TransportOptions()
+	transportOpts.DisableKeepAlives = noKeepAlive
+	llmc.ConfigureTransport(transportOpts)
+	httpretry.Configure(retryPolicy(cfg))
+
 	switch provider {
 	case openai.ProviderName:
 		return openai.NewProvider(cfg), nil
@@ -24,7 +37,135 @@ func newProvider(cfg *config.Config) (llmc.Provider, error) {
 		return gemini.NewProvider(cfg), nil
 	case anthropic.ProviderName:
 		return anthropic.NewProvider(cfg), nil
+	case cohere.ProviderName:
+		return cohere.NewProvider(cfg), nil
+	case openrouter.ProviderName:
+		return openrouter.NewProvider(cfg), nil
+	case azureopenai.ProviderName:
+		return azureopenai.NewProvider(cfg), nil
+	case llmc.CustomProviderName:
+		return newCustomProvider(cfg)
 	default:
-		return nil, fmt.Errorf("unsupported provider: %s (supported: openai, gemini, anthropic)", provider)
+		return nil, fmt.Errorf("unsupported provider: %s (supported: %s)", provider, strings.Join(llmc.SupportedProviders, ", "))
+	}
+}
+
+// resolveTimeout returns the timeout of each provider request: --timeout if given,
+// otherwise request_timeout_seconds of cfg
+func resolveTimeout(cfg *config.Config) time.Duration {
+	if rootCmd.PersistentFlags().Changed("timeout") {
+		return requestTimeout
+	}
+	return cfg.RequestTimeout()
+}
+
+// retryPolicy returns the retry settings of cfg with --provider-timeout-retries applied.
+// With --verbose, each retry is reported to stderr.
+func retryPolicy(cfg *config.Config) httpretry.Policy {
+	policy := cfg.RetryPolicy()
+	if rootCmd.PersistentFlags().Changed("provider-timeout-retries") {
+		policy.TimeoutRetries = timeoutRetries
+	}
+	if !verbose {
+		return policy
+	}
+	policy.OnRetry = func(reason httpretry.Reason, attempt int, delay time.Duration) {
+		budget := policy.MaxRetries
+		switch reason {
+		case httpretry.ReasonTimeout:
+			budget = policy.TimeoutRetries
+		case httpretry.ReasonOverloaded:
+			budget = policy.OverloadedRetries
+		}
+		fmt.Fprintf(os.Stderr, "Request failed (%s), retrying in %s (%d/%d)\n", reason, delay, attempt, budget)
+	}
+	return policy
+}
+
+// customProviderConfig makes a provider package use the base URL and token of the
+// custom provider instead of its own
+type customProviderConfig struct {
+	*config.Config
+}
+
+func (c customProviderConfig) GetBaseURL(provider string) (string, error) {
+	return c.Config.GetBaseURL(llmc.CustomProviderName)
+}
+
+func (c customProviderConfig) GetToken(provider string) (string, error) {
+	return c.Config.GetToken(llmc.CustomProviderName)
+}
+
+func (c customProviderConfig) GetRoleMap(provider string) map[string]string {
+	return c.Config.GetRoleMap(llmc.CustomProviderName)
+}
+
+// newCustomProvider creates a provider for a compatible gateway at custom_base_url,
+// using the request and response format selected by api_style
+func newCustomProvider(cfg *config.Config) (llmc.Provider, error) {
+	customCfg := customProviderConfig{cfg}
+	switch cfg.APIStyle {
+	case openai.ProviderName:
+		return openai.NewProvider(customCfg), nil
+	case gemini.ProviderName:
+		return gemini.NewProvider(customCfg), nil
+	case anthropic.ProviderName:
+		return anthropic.NewProvider(customCfg), nil
+	case "":
+		return nil, llmc.NewConfigError("the custom provider requires api_style (one of: %s)", strings.Join(llmc.APIStyles, ", "))
+	default:
+		return nil, llmc.NewConfigError("invalid api_style '%s' (must be one of: %s)", cfg.APIStyle, strings.Join(llmc.APIStyles, ", "))
+	}
+}
+
+// newChatProvider creates a provider for cfg.Model wrapped with the configured
+// fallback models. Fallback is skipped when disabled or no fallback models are set.
+// Outgoing messages are redacted if enabled in cfg.
+func newChatProvider(cfg *config.Config, noFallback bool) (llmc.Provider, error) {
+	primary, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if noFallback || len(cfg.FallbackModels) == 0 {
+		return withRedaction(cfg, primary)
+	}
+
+	models := []string{cfg.Model}
+	providers := []llmc.Provider{primary}
+	for _, fallbackModel := range cfg.FallbackModels {
+		if fallbackModel == cfg.Model {
+			continue
+		}
+		fallbackCfg := *cfg
+		fallbackCfg.Model = fallbackModel
+		p, err := newProvider(&fallbackCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback model %q: %w", fallbackModel, err)
+		}
+		models = append(models, fallbackModel)
+		providers = append(providers, p)
+	}
+
+	return withRedaction(cfg, llmc.NewFallbackProvider(models, providers, func(failedModel, nextModel string, err error) {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Request to %s failed (%v), falling back to %s\n", failedModel, err, nextModel)
+		}
+	}))
+}
+
+// withRedaction wraps provider so that credentials in outgoing messages are redacted,
+// unless redaction is disabled in cfg or by --no-redact
+func withRedaction(cfg *config.Config, provider llmc.Provider) (llmc.Provider, error) {
+	if noRedact || !cfg.RedactionEnabled() {
+		return provider, nil
+	}
+	redactor, err := llmc.NewRedactor(cfg.RedactPatterns)
+	if err != nil {
+		return nil, err
 	}
+	return llmc.NewRedactingProvider(provider, redactor, func(count int) {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Warning: redacted %d match(es) in the outgoing request\n", count)
+		}
+	}), nil
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+)
+
+func TestNewProviderSupportsAllProviders(t *testing.T) {
+	for _, name := range llmc.SupportedProviders {
+		t.Run(name, func(t *testing.T) {
+			cfg := &config.Config{Model: llmc.FormatModelString(name, "test-model"), APIStyle: "openai"}
+			if _, err := newProvider(cfg); err != nil {
+				t.Errorf("newProvider() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewCustomProviderRequiresAPIStyle(t *testing.T) {
+	cfg := &config.Config{Model: llmc.FormatModelString(llmc.CustomProviderName, "test-model")}
+	if _, err := newProvider(cfg); err == nil {
+		t.Error("newProvider() error = nil, want error for missing api_style")
+	}
+}
+
+func TestCustomProviderUsesOpenAICodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/responses" {
+			t.Errorf("request = %s %s, want POST /responses", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer custom-token" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer custom-token")
+		}
+		var body struct {
+			Model string `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		if body.Model != "my-model" {
+			t.Errorf("request model = %q, want %q", body.Model, "my-model")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"resp","status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"hi"}]}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Model:         llmc.FormatModelString(llmc.CustomProviderName, "my-model"),
+		APIStyle:      "openai",
+		CustomBaseURL: server.URL,
+		CustomToken:   "custom-token",
+	}
+	provider, err := newProvider(cfg)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	got, err := provider.Chat("hello")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got != "hi" {
+		t.Errorf("Chat() = %q, want %q", got, "hi")
+	}
+}
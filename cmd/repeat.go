@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+// repeatChat sends message to llmProvider n times and returns the responses in order.
+// Each request is independent (no history). Requests are sent one after another so
+// that they stay within rate limits; rate limited requests are retried by the provider.
+func repeatChat(ctx context.Context, llmProvider llmc.Provider, systemPrompt, message, model string, n int) ([]chatResult, error) {
+	results := make([]chatResult, 0, n)
+	for i := 0; i < n; i++ {
+		var response string
+		var err error
+		if systemPrompt != "" {
+			response, err = llmProvider.ChatWithHistoryContext(ctx, systemPrompt, nil, message)
+		} else {
+			response, err = llmProvider.ChatContext(ctx, message)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("response %d of %d: %w", i+1, n, err)
+		}
+		results = append(results, chatResult{
+			Model:    llmc.ResponseModel(llmProvider, model),
+			Response: withPrefill(response),
+			Usage:    llmc.LastUsage(llmProvider),
+		})
+	}
+	return results, nil
+}
+
+// bestNumberPattern matches the number of the response picked by the model
+var bestNumberPattern = regexp.MustCompile(`\d+`)
+
+// pickBestResponse asks llmProvider which of results answers message best and
+// returns its index
+func pickBestResponse(ctx context.Context, llmProvider llmc.Provider, message string, results []chatResult) (int, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The following %d responses were given to the same request.\n\n", len(results))
+	fmt.Fprintf(&b, "Request:\n%s\n", message)
+	for i, result := range results {
+		fmt.Fprintf(&b, "\nResponse %d:\n%s\n", i+1, result.Response)
+	}
+	b.WriteString("\nWhich response is the best? Reply with only its number.")
+
+	reply, err := llmProvider.ChatContext(ctx, b.String())
+	if err != nil {
+		return 0, err
+	}
+	number, err := strconv.Atoi(bestNumberPattern.FindString(reply))
+	if err != nil || number < 1 || number > len(results) {
+		return 0, fmt.Errorf("could not find a response number in the reply %q", strings.TrimSpace(reply))
+	}
+	return number - 1, nil
+}
+
+// writeRepeatedResponses prints results numbered, or as a JSON array with --json
+func writeRepeatedResponses(w io.Writer, results []chatResult) error {
+	if chatJSON {
+		for i := range results {
+			results[i].Response = extractResponse(results[i].Response)
+		}
+		return writeJSON(w, results, compactJSON)
+	}
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "--- Response %d of %d ---\n", i+1, len(results))
+		fmt.Fprintln(w, extractResponse(result.Response))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+// countingProvider returns a numbered response for each request
+type countingProvider struct {
+	fakeProvider
+	calls    int
+	messages []string
+	history  [][]llmc.Message
+}
+
+func (p *countingProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	return p.ChatWithHistoryContext(ctx, "", nil, message)
+}
+
+func (p *countingProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	p.calls++
+	p.messages = append(p.messages, newMessage)
+	p.history = append(p.history, messages)
+	return fmt.Sprintf("response %d", p.calls), nil
+}
+
+func TestRepeatChat(t *testing.T) {
+	provider := &countingProvider{}
+	results, err := repeatChat(context.Background(), provider, "be brief", "name a color", "openai:gpt-4", 3)
+	if err != nil {
+		t.Fatalf("repeatChat() error = %v", err)
+	}
+
+	if provider.calls != 3 {
+		t.Errorf("requests = %d, want 3", provider.calls)
+	}
+	for i, history := range provider.history {
+		if len(history) != 0 {
+			t.Errorf("request %d has history %v, want none", i+1, history)
+		}
+		if provider.messages[i] != "name a color" {
+			t.Errorf("request %d message = %q, want %q", i+1, provider.messages[i], "name a color")
+		}
+	}
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	for i, result := range results {
+		if want := fmt.Sprintf("response %d", i+1); result.Response != want {
+			t.Errorf("results[%d].Response = %q, want %q", i, result.Response, want)
+		}
+		if result.Model != "openai:gpt-4" {
+			t.Errorf("results[%d].Model = %q, want %q", i, result.Model, "openai:gpt-4")
+		}
+	}
+}
+
+func TestRepeatChatStopsOnError(t *testing.T) {
+	provider := &failingProvider{err: fmt.Errorf("boom")}
+	if _, err := repeatChat(context.Background(), provider, "", "hi", "openai:gpt-4", 3); err == nil || !strings.Contains(err.Error(), "response 1 of 3") {
+		t.Errorf("repeatChat() error = %v, want the failed response number", err)
+	}
+}
+
+func TestWriteRepeatedResponses(t *testing.T) {
+	results := []chatResult{{Model: "m", Response: "one"}, {Model: "m", Response: "two"}}
+
+	var text bytes.Buffer
+	if err := writeRepeatedResponses(&text, results); err != nil {
+		t.Fatalf("writeRepeatedResponses() error = %v", err)
+	}
+	want := "--- Response 1 of 2 ---\none\n\n--- Response 2 of 2 ---\ntwo\n"
+	if text.String() != want {
+		t.Errorf("output = %q, want %q", text.String(), want)
+	}
+
+	chatJSON = true
+	defer func() { chatJSON = false }()
+	var out bytes.Buffer
+	if err := writeRepeatedResponses(&out, results); err != nil {
+		t.Fatalf("writeRepeatedResponses() error = %v", err)
+	}
+	var got []chatResult
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v (%s)", err, out.String())
+	}
+	if len(got) != 2 || got[0].Response != "one" || got[1].Response != "two" {
+		t.Errorf("results = %+v, want both responses", got)
+	}
+}
+
+func TestPickBestResponse(t *testing.T) {
+	results := []chatResult{{Response: "red"}, {Response: "blue"}, {Response: "green"}}
+
+	provider := &scriptedReplyProvider{reply: "Response 2 is the best."}
+	best, err := pickBestResponse(context.Background(), provider, "name a color", results)
+	if err != nil {
+		t.Fatalf("pickBestResponse() error = %v", err)
+	}
+	if best != 1 {
+		t.Errorf("best = %d, want 1", best)
+	}
+	for _, want := range []string{"name a color", "Response 1:\nred", "Response 3:\ngreen"} {
+		if !strings.Contains(provider.message, want) {
+			t.Errorf("request %q does not contain %q", provider.message, want)
+		}
+	}
+
+	provider = &scriptedReplyProvider{reply: "4"}
+	if _, err := pickBestResponse(context.Background(), provider, "name a color", results); err == nil {
+		t.Error("pickBestResponse() error = nil, want an error for an out of range number")
+	}
+}
+
+// scriptedReplyProvider returns reply and records the request
+type scriptedReplyProvider struct {
+	fakeProvider
+	reply   string
+	message string
+}
+
+func (p *scriptedReplyProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	p.message = message
+	return p.reply, nil
+}
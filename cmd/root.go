@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/longkey1/llmc/internal/llmc/config"
 	"github.com/spf13/cobra"
@@ -14,8 +15,16 @@ import (
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile     string
+	verbose     bool
+	noRedact    bool
+	noKeepAlive bool
+	recordFile  string
+	replayFile  string
+	compactJSON bool
+
+	requestTimeout time.Duration
+	timeoutRetries int
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -25,6 +34,15 @@ var rootCmd = &cobra.Command{
 	Long: `llmc is a command-line tool that allows you to interact with various LLM APIs.
 It supports multiple providers.
 You can configure the tool using a TOML configuration file.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if requestTimeout < 0 {
+			return newUsageError(fmt.Errorf("--timeout must be 0 or greater"))
+		}
+		if timeoutRetries < 0 {
+			return newUsageError(fmt.Errorf("--provider-timeout-retries must be 0 or greater"))
+		}
+		return setupCassette(recordFile, replayFile)
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -32,10 +50,12 @@ You can configure the tool using a TOML configuration file.`,
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The process exits with a code describing the failure (see exitcode.go).
 func Execute() {
+	markUsageErrors(rootCmd)
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -45,6 +65,13 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/llmc/config.toml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&noRedact, "no-redact", false, "Do not redact credentials in outgoing messages (see redact in config)")
+	rootCmd.PersistentFlags().BoolVar(&noKeepAlive, "no-keep-alive", false, "Open a new connection for every request instead of reusing connections")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 0, "Timeout of each provider request, e.g. 30s or 2m; 0 = no timeout (default: request_timeout_seconds)")
+	rootCmd.PersistentFlags().IntVar(&timeoutRetries, "provider-timeout-retries", 0, "Retry a request that timed out up to N times (default: timeout_retries)")
+	rootCmd.PersistentFlags().BoolVar(&compactJSON, "compact", false, "Write JSON output on a single line instead of indented")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "Record provider requests and responses to a cassette file (credentials are redacted)")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "Answer provider requests from a cassette file recorded with --record instead of calling the API")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -73,26 +100,81 @@ func initConfig() {
 
 	// Set default values from llmc package
 	viper.SetDefault("model", defaultConfig.Model)
+	viper.SetDefault("fallback_models", defaultConfig.FallbackModels)
+	viper.SetDefault("default_provider", defaultConfig.DefaultProvider)
 	viper.SetDefault("openai_base_url", defaultConfig.OpenAIBaseURL)
 	viper.SetDefault("openai_token", defaultConfig.OpenAIToken)
 	viper.SetDefault("gemini_base_url", defaultConfig.GeminiBaseURL)
 	viper.SetDefault("gemini_token", defaultConfig.GeminiToken)
 	viper.SetDefault("anthropic_base_url", defaultConfig.AnthropicBaseURL)
 	viper.SetDefault("anthropic_token", defaultConfig.AnthropicToken)
+	viper.SetDefault("cohere_base_url", defaultConfig.CohereBaseURL)
+	viper.SetDefault("cohere_token", defaultConfig.CohereToken)
+	viper.SetDefault("openrouter_base_url", defaultConfig.OpenRouterBaseURL)
+	viper.SetDefault("openrouter_token", defaultConfig.OpenRouterToken)
+	viper.SetDefault("azure_endpoint", defaultConfig.AzureEndpoint)
+	viper.SetDefault("azure_api_version", defaultConfig.AzureAPIVersion)
+	viper.SetDefault("azure_token", defaultConfig.AzureToken)
+	viper.SetDefault("custom_base_url", defaultConfig.CustomBaseURL)
+	viper.SetDefault("custom_token", defaultConfig.CustomToken)
+	viper.SetDefault("api_style", defaultConfig.APIStyle)
 	viper.SetDefault("prompt_dirs", defaultPromptDirs)
 	viper.SetDefault("enable_web_search", defaultConfig.EnableWebSearch)
 	viper.SetDefault("session_message_threshold", defaultConfig.SessionMessageThreshold)
 	viper.SetDefault("session_retention_days", defaultConfig.SessionRetentionDays)
+	viper.SetDefault("max_sessions", defaultConfig.MaxSessions)
+	viper.SetDefault("time_format", defaultConfig.TimeFormat)
+	viper.SetDefault("timezone", defaultConfig.Timezone)
+	viper.SetDefault("user_label", defaultConfig.UserLabel)
+	viper.SetDefault("assistant_label", defaultConfig.AssistantLabel)
+	viper.SetDefault("redact", defaultConfig.Redact)
+	viper.SetDefault("redact_patterns", defaultConfig.RedactPatterns)
+	viper.SetDefault("max_idle_conns", defaultConfig.MaxIdleConns)
+	viper.SetDefault("idle_conn_timeout", defaultConfig.IdleConnTimeout)
+	viper.SetDefault("max_display_lines", defaultConfig.MaxDisplayLines)
+	viper.SetDefault("history_size", defaultConfig.HistorySize)
+	viper.SetDefault("max_retries", defaultConfig.MaxRetries)
+	viper.SetDefault("timeout_retries", defaultConfig.TimeoutRetries)
+	viper.SetDefault("overloaded_retries", defaultConfig.OverloadedRetries)
+	viper.SetDefault("request_timeout_seconds", defaultConfig.RequestTimeoutSeconds)
 
 	// Bind environment variables
+	viper.BindEnv("default_provider", "LLMC_DEFAULT_PROVIDER")
 	viper.BindEnv("openai_base_url", "LLMC_OPENAI_BASE_URL")
 	viper.BindEnv("openai_token", "LLMC_OPENAI_TOKEN")
 	viper.BindEnv("gemini_base_url", "LLMC_GEMINI_BASE_URL")
 	viper.BindEnv("gemini_token", "LLMC_GEMINI_TOKEN")
 	viper.BindEnv("anthropic_base_url", "LLMC_ANTHROPIC_BASE_URL")
 	viper.BindEnv("anthropic_token", "LLMC_ANTHROPIC_TOKEN")
+	viper.BindEnv("cohere_base_url", "LLMC_COHERE_BASE_URL")
+	viper.BindEnv("cohere_token", "LLMC_COHERE_TOKEN")
+	viper.BindEnv("openrouter_base_url", "LLMC_OPENROUTER_BASE_URL")
+	viper.BindEnv("openrouter_token", "LLMC_OPENROUTER_TOKEN")
+	viper.BindEnv("azure_endpoint", "LLMC_AZURE_ENDPOINT")
+	viper.BindEnv("azure_api_version", "LLMC_AZURE_API_VERSION")
+	viper.BindEnv("azure_token", "LLMC_AZURE_TOKEN")
+	viper.BindEnv("custom_base_url", "LLMC_CUSTOM_BASE_URL")
+	viper.BindEnv("custom_token", "LLMC_CUSTOM_TOKEN")
+	viper.BindEnv("api_style", "LLMC_API_STYLE")
 	viper.BindEnv("session_message_threshold", "LLMC_SESSION_MESSAGE_THRESHOLD")
 	viper.BindEnv("session_retention_days", "LLMC_SESSION_RETENTION_DAYS")
+	viper.BindEnv("max_sessions", "LLMC_MAX_SESSIONS")
+	viper.BindEnv("time_format", "LLMC_TIME_FORMAT")
+	viper.BindEnv("timezone", "LLMC_TIMEZONE")
+	viper.BindEnv("user_label", "LLMC_USER_LABEL")
+	viper.BindEnv("assistant_label", "LLMC_ASSISTANT_LABEL")
+	viper.BindEnv("temperature", "LLMC_TEMPERATURE")
+	viper.BindEnv("top_p", "LLMC_TOP_P")
+	viper.BindEnv("max_tokens", "LLMC_MAX_TOKENS")
+	viper.BindEnv("redact", "LLMC_REDACT")
+	viper.BindEnv("max_idle_conns", "LLMC_MAX_IDLE_CONNS")
+	viper.BindEnv("idle_conn_timeout", "LLMC_IDLE_CONN_TIMEOUT")
+	viper.BindEnv("max_display_lines", "LLMC_MAX_DISPLAY_LINES")
+	viper.BindEnv("history_size", "LLMC_HISTORY_SIZE")
+	viper.BindEnv("max_retries", "LLMC_MAX_RETRIES")
+	viper.BindEnv("timeout_retries", "LLMC_TIMEOUT_RETRIES")
+	viper.BindEnv("overloaded_retries", "LLMC_OVERLOADED_RETRIES")
+	viper.BindEnv("request_timeout_seconds", "LLMC_REQUEST_TIMEOUT_SECONDS")
 
 	if cfgFile != "" {
 		// Use config file from the flag.
@@ -138,6 +220,31 @@ func initConfig() {
 				}
 			}
 		}
+
+		// Merge config fragments (config.d/*.toml in lexical order) on top of the config files
+		settings, fragments, err := config.LoadConfigDir(filepath.Join(userConfigDir, config.ConfigDirName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config fragments: %v\n", err)
+		} else if len(fragments) > 0 {
+			if err := viper.MergeConfigMap(settings); err != nil {
+				fmt.Fprintf(os.Stderr, "Error merging config fragments: %v\n", err)
+			} else if verbose {
+				for _, fragment := range fragments {
+					fmt.Fprintln(os.Stderr, "Merged config fragment:", fragment)
+				}
+			}
+		}
+	}
+
+	// Map settings of the old single-provider schema (provider, base_url, token) to the current one
+	if changes, err := config.ApplyLegacySettings(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error migrating legacy config settings: %v\n", err)
+	} else if len(changes) > 0 {
+		fmt.Fprintln(os.Stderr, "Warning: The config uses legacy settings (provider, base_url, token), which were converted:")
+		for _, change := range changes {
+			fmt.Fprintf(os.Stderr, "  - %s\n", change)
+		}
+		fmt.Fprintln(os.Stderr, "Run 'llmc config migrate' to rewrite the config file in the current format.")
 	}
 
 	if verbose {
@@ -147,6 +254,9 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "  LLMC_OPENAI_BASE_URL:", viper.GetString("openai_base_url"))
 		fmt.Fprintln(os.Stderr, "  LLMC_GEMINI_BASE_URL:", viper.GetString("gemini_base_url"))
 		fmt.Fprintln(os.Stderr, "  LLMC_ANTHROPIC_BASE_URL:", viper.GetString("anthropic_base_url"))
+		fmt.Fprintln(os.Stderr, "  LLMC_COHERE_BASE_URL:", viper.GetString("cohere_base_url"))
+		fmt.Fprintln(os.Stderr, "  LLMC_OPENROUTER_BASE_URL:", viper.GetString("openrouter_base_url"))
+		fmt.Fprintln(os.Stderr, "  LLMC_AZURE_ENDPOINT:", viper.GetString("azure_endpoint"))
 		fmt.Fprintln(os.Stderr, "  LLMC_PROMPT_DIRS:", viper.GetStringSlice("prompt_dirs"))
 		fmt.Fprintln(os.Stderr, "  LLMC_ENABLE_WEB_SEARCH:", viper.GetBool("enable_web_search"))
 	}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+	"github.com/longkey1/llmc/internal/llmc/session"
+	"github.com/spf13/cobra"
+)
+
+// addSamplingFlags adds the --temperature, --top-p and --max-tokens flags to c
+func addSamplingFlags(c *cobra.Command) {
+	flags := c.Flags()
+	flags.Float64("temperature", 0, "Sampling temperature (0-2; default: config or provider default)")
+	flags.Float64("top-p", 0, "Nucleus sampling probability (0-1; default: config or provider default)")
+	flags.Int("max-tokens", 0, "Maximum number of output tokens (default: config or provider default)")
+}
+
+// samplingFromFlags returns the sampling parameters explicitly set on the command line
+func samplingFromFlags(cmd *cobra.Command) (llmc.SamplingParams, error) {
+	var params llmc.SamplingParams
+	flags := cmd.Flags()
+	if flags.Changed("temperature") {
+		v, _ := flags.GetFloat64("temperature")
+		params.Temperature = &v
+	}
+	if flags.Changed("top-p") {
+		v, _ := flags.GetFloat64("top-p")
+		params.TopP = &v
+	}
+	if flags.Changed("max-tokens") {
+		v, _ := flags.GetInt("max-tokens")
+		params.MaxTokens = &v
+	}
+	if err := params.Validate(); err != nil {
+		return llmc.SamplingParams{}, newUsageError(fmt.Errorf("invalid sampling parameter: %w", err))
+	}
+	return params, nil
+}
+
+// resolveSampling returns the sampling parameters for a request.
+// A session uses the parameters it was created with (sessions created before
+// they were stored use the configuration); flags override them for this turn only.
+func resolveSampling(cfg *config.Config, sess *session.Session, flags llmc.SamplingParams) llmc.SamplingParams {
+	base := cfg.SamplingParams()
+	if sess != nil && sess.Sampling != nil {
+		base = *sess.Sampling
+	}
+	return base.Merge(flags)
+}
@@ -1,16 +1,26 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 
 	"github.com/chzyer/readline"
 	"github.com/longkey1/llmc/internal/llmc"
 	"github.com/longkey1/llmc/internal/llmc/config"
+	"github.com/longkey1/llmc/internal/llmc/export"
+	promptpkg "github.com/longkey1/llmc/internal/llmc/prompt"
 	"github.com/longkey1/llmc/internal/llmc/session"
 	"github.com/spf13/cobra"
 )
@@ -28,12 +38,48 @@ Sessions allow you to maintain conversation history across multiple interactions
 var sessionsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all sessions",
-	Long:  `List all conversation sessions sorted by most recently updated.`,
+	Long: `List all conversation sessions sorted by most recently updated.
+
+Use --json to print the sessions as a JSON array for scripts and other tools.
+Use --tag to list only the sessions with a tag (repeat it to require several tags).
+
+Session files that cannot be read are left out of the list and counted in a warning.
+Use --show-errors to print their file names and errors after the list.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		sessions, err := session.ListSessions()
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		wide, _ := cmd.Flags().GetBool("wide")
+		limit, _ := cmd.Flags().GetInt("limit")
+		showErrors, _ := cmd.Flags().GetBool("show-errors")
+		tags, _ := cmd.Flags().GetStringArray("tag")
+		if limit < 0 {
+			return newUsageError(fmt.Errorf("--limit must be 0 or greater (got %d)", limit))
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		sessions, fileErrors, err := session.ListSessionsWithErrors()
 		if err != nil {
 			return fmt.Errorf("listing sessions: %w", err)
 		}
+		// Report unreadable files after the list (on stderr, so JSON output stays valid)
+		defer writeSessionFileErrors(os.Stderr, fileErrors, showErrors)
+
+		// On a terminal, mark the session 'latest' refers to (the most recently updated one)
+		latestID := ""
+		if len(sessions) > 0 && readline.IsTerminal(int(os.Stdout.Fd())) {
+			latestID = sessions[0].ID
+		}
+
+		// Keep only the tagged sessions, then the most recently updated ones if limited
+		sessions = filterSessionsByTags(sessions, tags)
+		sessions = limitSessions(sessions, limit)
+
+		if jsonOutput {
+			return writeSessionsJSON(os.Stdout, sessions, compactJSON)
+		}
 
 		if len(sessions) == 0 {
 			fmt.Println("No sessions found.")
@@ -42,55 +88,410 @@ var sessionsListCmd = &cobra.Command{
 			return nil
 		}
 
-		// Print table header
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tMODEL\tCREATED\tMESSAGES\tNAME\tFIRST MESSAGE")
-		fmt.Fprintln(w, "--\t-----\t-------\t--------\t----\t-------------")
+		// Fit the table to the terminal unless --wide is given
+		width := 0
+		if !wide {
+			width = terminalWidth()
+		}
+		writeSessionsTable(os.Stdout, sessions, cfg, width, latestID)
+
+		fmt.Println("\nUse 'llmc sessions show <id>' to view session details.")
+		return nil
+	},
+}
 
-		// Print each session
-		for _, sess := range sessions {
-			name := sess.Name
-			if name == "" {
-				name = "-"
-			}
-			firstMsg := "-"
-			for _, msg := range sess.Messages {
-				if msg.Role == "user" {
-					content := strings.ReplaceAll(msg.Content, "\n", " ")
-					if len(content) > 50 {
-						firstMsg = content[:50] + "..."
-					} else {
-						firstMsg = content
-					}
-					break
-				}
+// writeSessionFileErrors reports the session files that could not be read:
+// each file and its error if showErrors is set, or only their number otherwise
+func writeSessionFileErrors(w io.Writer, fileErrors []session.FileError, showErrors bool) {
+	if len(fileErrors) == 0 {
+		return
+	}
+	if !showErrors {
+		fmt.Fprintf(w, "\nWarning: %d session file(s) could not be read (use --show-errors for details)\n", len(fileErrors))
+		return
+	}
+
+	fmt.Fprintf(w, "\n%d session file(s) could not be read:\n", len(fileErrors))
+	for _, fileErr := range fileErrors {
+		fmt.Fprintf(w, "  %s: %v\n", fileErr.Path, fileErr.Err)
+	}
+	fmt.Fprintln(w, "\nRun 'llmc sessions repair' to recover corrupted sessions.")
+}
+
+// Columns of the sessions list table
+const (
+	colID = iota
+	colModel
+	colCreated
+	colMessages
+	colName
+	colFirstMessage
+)
+
+// sessionsTableShrinkOrder lists the columns that can be truncated to fit the
+// terminal, in the order they are shrunk, with their minimum widths
+var sessionsTableShrinkOrder = []struct {
+	col      int
+	minWidth int
+}{
+	{colFirstMessage, 10},
+	{colName, 8},
+	{colModel, 12},
+}
+
+// terminalWidth returns the width of the terminal on stdout, or 0 if stdout is not a terminal
+func terminalWidth() int {
+	if !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return 0
+	}
+	if width := readline.GetScreenWidth(); width > 0 {
+		return width
+	}
+	return 0
+}
+
+// writeSessionsTable writes sessions as a table.
+// If width is greater than 0, the model, name and first message columns are
+// truncated with an ellipsis so that each row fits within width.
+// The row of the session with latestID ("" = none) is marked with an asterisk.
+func writeSessionsTable(out io.Writer, sessions []session.Session, cfg *config.Config, width int, latestID string) {
+	rows := [][]string{
+		{"ID", "MODEL", "CREATED", "MESSAGES", "NAME", "FIRST MESSAGE"},
+		{"--", "-----", "-------", "--------", "----", "-------------"},
+	}
+	marked := false
+	for _, sess := range sessions {
+		id := sess.GetShortID()
+		if latestID != "" && sess.ID == latestID {
+			id += " *"
+			marked = true
+		}
+		name := sess.Name
+		if name == "" {
+			name = "-"
+		}
+		firstMsg := "-"
+		for _, msg := range sess.Messages {
+			if msg.Role == "user" {
+				firstMsg = truncateText(strings.ReplaceAll(msg.Content, "\n", " "), 53)
+				break
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
-				sess.GetShortID(),
-				sess.Model,
-				sess.CreatedAt.Format("2006-01-02"),
-				sess.MessageCount(),
-				name,
-				firstMsg,
-			)
 		}
-		w.Flush()
+		rows = append(rows, []string{
+			id,
+			sess.Model,
+			cfg.FormatTime(sess.CreatedAt),
+			strconv.Itoa(sess.MessageCount()),
+			name,
+			firstMsg,
+		})
+	}
 
-		fmt.Println("\nUse 'llmc sessions show <id>' to view session details.")
+	// Shrink columns until the table fits
+	const padding = 2
+	colWidths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			colWidths[i] = max(colWidths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	if width > 0 {
+		total := padding * (len(colWidths) - 1)
+		for _, w := range colWidths {
+			total += w
+		}
+		for _, s := range sessionsTableShrinkOrder {
+			if total <= width {
+				break
+			}
+			shrink := min(total-width, max(colWidths[s.col]-s.minWidth, 0))
+			colWidths[s.col] -= shrink
+			total -= shrink
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, padding, ' ', 0)
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = truncateText(cell, colWidths[i])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	if marked {
+		fmt.Fprintln(out, "\n* latest (the session 'latest' refers to)")
+	}
+}
+
+// truncateText shortens s to at most n characters, ending with "..." when truncated
+func truncateText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+// sessionListItem is the JSON representation of a session in 'sessions list --json'.
+// Field names are part of the output contract and must be kept stable.
+type sessionListItem struct {
+	ID           string    `json:"id"`
+	ShortID      string    `json:"short_id"`
+	Name         string    `json:"name"`
+	Model        string    `json:"model"`
+	Provider     string    `json:"provider"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MessageCount int       `json:"message_count"`
+	ParentID     string    `json:"parent_id"`
+	Tags         []string  `json:"tags"`
+}
+
+// filterSessionsByTags returns the sessions that have all of tags (all sessions if tags is empty)
+func filterSessionsByTags(sessions []session.Session, tags []string) []session.Session {
+	if len(tags) == 0 {
+		return sessions
+	}
+	var filtered []session.Session
+	for _, sess := range sessions {
+		matches := true
+		for _, tag := range tags {
+			if !sess.HasTag(tag) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, sess)
+		}
+	}
+	return filtered
+}
+
+// limitSessions returns at most limit sessions (0 means no limit)
+func limitSessions(sessions []session.Session, limit int) []session.Session {
+	if limit > 0 && len(sessions) > limit {
+		return sessions[:limit]
+	}
+	return sessions
+}
+
+// writeSessionsJSON writes the sessions as a JSON array of sessionListItem
+func writeSessionsJSON(w io.Writer, sessions []session.Session, compact bool) error {
+	items := make([]sessionListItem, 0, len(sessions))
+	for _, sess := range sessions {
+		tags := sess.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		items = append(items, sessionListItem{
+			ID:           sess.ID,
+			ShortID:      sess.GetShortID(),
+			Name:         sess.Name,
+			Model:        sess.Model,
+			Provider:     sess.GetProvider(),
+			CreatedAt:    sess.CreatedAt,
+			UpdatedAt:    sess.UpdatedAt,
+			MessageCount: sess.MessageCount(),
+			ParentID:     sess.ParentID,
+			Tags:         tags,
+		})
+	}
+
+	if err := writeJSON(w, items, compact); err != nil {
+		return fmt.Errorf("serializing sessions: %w", err)
+	}
+	return nil
+}
+
+// Dimensions that sessions stats groups by
+const (
+	statsByModel    = "model"
+	statsByDay      = "day"
+	statsByTemplate = "template"
+)
+
+// noTemplateKey is the template group of sessions created without a prompt template
+const noTemplateKey = "(none)"
+
+// sessionsStatsCmd represents the sessions stats command
+var sessionsStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Count sessions and messages by model, day or template",
+	Long: `Count sessions and messages grouped by a dimension:
+
+  model     the model of each session (default)
+  day       the date each session was created (in the configured timezone)
+  template  the prompt template each session was created with
+
+Groups are sorted by session count, or by date with --by day.
+Use --json to print the groups as a JSON array.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, _ := cmd.Flags().GetString("by")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if by != statsByModel && by != statsByDay && by != statsByTemplate {
+			return newUsageError(fmt.Errorf("invalid --by '%s' (must be %s, %s or %s)", by, statsByModel, statsByDay, statsByTemplate))
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		sessions, fileErrors, err := session.ListSessionsWithErrors()
+		if err != nil {
+			return fmt.Errorf("listing sessions: %w", err)
+		}
+		defer writeSessionFileErrors(os.Stderr, fileErrors, false)
+
+		groups := groupSessionStats(sessions, by, cfg.Location())
+		if jsonOutput {
+			if err := writeJSON(os.Stdout, groups, compactJSON); err != nil {
+				return fmt.Errorf("serializing stats: %w", err)
+			}
+			return nil
+		}
+
+		if len(groups) == 0 {
+			fmt.Println("No sessions found.")
+			return nil
+		}
+		writeSessionStatsTable(os.Stdout, groups, by)
 		return nil
 	},
 }
 
+// sessionStatsGroup counts the sessions and messages of one group of sessions stats
+type sessionStatsGroup struct {
+	Key      string `json:"key"`
+	Sessions int    `json:"sessions"`
+	Messages int    `json:"messages"`
+}
+
+// groupSessionStats counts sessions and their messages grouped by the dimension by.
+// Days are the creation dates in loc. Day groups are sorted by date (oldest first),
+// other groups by session count (most first) and then by key.
+func groupSessionStats(sessions []session.Session, by string, loc *time.Location) []sessionStatsGroup {
+	index := make(map[string]int)
+	groups := []sessionStatsGroup{}
+	for _, sess := range sessions {
+		var key string
+		switch by {
+		case statsByDay:
+			key = sess.CreatedAt.In(loc).Format("2006-01-02")
+		case statsByTemplate:
+			key = sess.TemplateName
+			if key == "" {
+				key = noTemplateKey
+			}
+		default:
+			key = sess.Model
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, sessionStatsGroup{Key: key})
+		}
+		groups[i].Sessions++
+		groups[i].Messages += sess.MessageCount()
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if by != statsByDay && groups[i].Sessions != groups[j].Sessions {
+			return groups[i].Sessions > groups[j].Sessions
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// writeSessionStatsTable writes groups as a table with a total row
+func writeSessionStatsTable(out io.Writer, groups []sessionStatsGroup, by string) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tSESSIONS\tMESSAGES\n", strings.ToUpper(by))
+	var total sessionStatsGroup
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", g.Key, g.Sessions, g.Messages)
+		total.Sessions += g.Sessions
+		total.Messages += g.Messages
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\n", total.Sessions, total.Messages)
+	w.Flush()
+}
+
 // sessionsShowCmd represents the sessions show command
 var sessionsShowCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Show session details and history",
 	Long: `Show detailed information about a session including all messages.
 
-The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.
+
+Use --first N and/or --last N to show only the opening and/or closing messages.
+When both are given, the omitted messages in between are marked.
+
+Use --since to show only the messages added after a time, given as a duration
+before now (e.g. 30m, 2h, 3d) or a date (YYYY-MM-DD, YYYY-MM, YYYY, or RFC 3339).
+Messages keep their numbers in the history.
+
+Use --metadata-only to show only the session header (ID, name, parent, model,
+dates, template, system prompt, message count) without the message history,
+and --json to print the session as JSON (also combinable with --metadata-only).
+
+Use --raw-json-message N to print message N (as numbered in the history) exactly
+as stored in the session file, including its timestamp and any other fields.
+
+Use --markdown to print the session as Markdown source, the same as
+'llmc sessions export' writes, e.g. to paste into docs or issues.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sessionID := args[0]
+		first, _ := cmd.Flags().GetInt("first")
+		last, _ := cmd.Flags().GetInt("last")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		metadataOnly, _ := cmd.Flags().GetBool("metadata-only")
+		rawMessage, _ := cmd.Flags().GetInt("raw-json-message")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		markdown, _ := cmd.Flags().GetBool("markdown")
+		if markdown && (first > 0 || last > 0 || jsonOutput || metadataOnly || sinceStr != "" || cmd.Flags().Changed("raw-json-message")) {
+			return newUsageError(fmt.Errorf("--markdown cannot be used with --first, --last, --json, --metadata-only, --since or --raw-json-message"))
+		}
+		if first < 0 || last < 0 {
+			return newUsageError(fmt.Errorf("--first and --last must be 0 or greater"))
+		}
+		if cmd.Flags().Changed("raw-json-message") {
+			if rawMessage < 1 {
+				return newUsageError(fmt.Errorf("--raw-json-message must be 1 or greater"))
+			}
+			if first > 0 || last > 0 || metadataOnly {
+				return newUsageError(fmt.Errorf("--raw-json-message cannot be used with --first, --last or --metadata-only"))
+			}
+		}
+		if (first > 0 || last > 0) && (jsonOutput || metadataOnly) {
+			return newUsageError(fmt.Errorf("--first and --last cannot be used with --json or --metadata-only"))
+		}
+		var since time.Time
+		if sinceStr != "" {
+			if first > 0 || last > 0 || jsonOutput || metadataOnly || cmd.Flags().Changed("raw-json-message") {
+				return newUsageError(fmt.Errorf("--since cannot be used with --first, --last, --json, --metadata-only or --raw-json-message"))
+			}
+			var err error
+			if since, err = parseSince(sinceStr, time.Now()); err != nil {
+				return newUsageError(err)
+			}
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
 
 		// Find session by prefix
 		sess, err := session.FindSessionByPrefix(sessionID)
@@ -98,24 +499,25 @@ The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the
 			return fmt.Errorf("finding session: %w", err)
 		}
 
-		// Print session info
-		fmt.Printf("Session: %s\n", sess.ID)
-		if sess.Name != "" {
-			fmt.Printf("Name: %s\n", sess.Name)
+		// The message is printed as JSON, so --json is implied
+		if rawMessage > 0 {
+			return writeRawMessage(os.Stdout, sess, rawMessage, compactJSON)
 		}
-		if sess.ParentID != "" {
-			fmt.Printf("Parent: %s\n", sess.ParentID)
+		if jsonOutput {
+			return writeSessionJSON(os.Stdout, sess, metadataOnly, compactJSON)
 		}
-		fmt.Printf("Model: %s\n", sess.Model)
-		fmt.Printf("Created: %s\n", sess.CreatedAt.Format("2006-01-02 15:04:05"))
-		fmt.Printf("Updated: %s\n", sess.UpdatedAt.Format("2006-01-02 15:04:05"))
-		if sess.TemplateName != "" {
-			fmt.Printf("Template: %s\n", sess.TemplateName)
+		if markdown {
+			return export.Write(os.Stdout, sess, export.FormatMarkdown, cfg)
+		}
+
+		// Print session info, noting which session 'latest' resolved to on a terminal
+		if sessionID == "latest" && readline.IsTerminal(int(os.Stdout.Fd())) {
+			fmt.Printf("Latest session (most recently updated): %s\n", sess.GetShortID())
 		}
-		if sess.SystemPrompt != "" {
-			fmt.Printf("System Prompt: %s\n", sess.SystemPrompt)
+		writeSessionHeader(os.Stdout, sess, cfg)
+		if metadataOnly {
+			return nil
 		}
-		fmt.Printf("Messages: %d\n", sess.MessageCount())
 		fmt.Println()
 
 		// Print message history
@@ -126,33 +528,189 @@ The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the
 
 		fmt.Println("Message History:")
 		fmt.Println("----------------")
-		for i, msg := range sess.Messages {
-			timestamp := ""
-			if t, ok := msg.Timestamp.(string); ok {
-				// Parse timestamp if it's a string
-				timestamp = t
-			} else {
-				timestamp = fmt.Sprintf("%v", msg.Timestamp)
-			}
+		writeMessageHistory(os.Stdout, sess, cfg, first, last, since)
 
-			roleLabel := "You"
-			if msg.Role == "assistant" {
-				roleLabel = "Assistant"
-			}
+		fmt.Printf("\nContinue this session with:\n  llmc chat -s %s \"your message\"\n", sess.GetShortID())
+		return nil
+	},
+}
+
+// writeSessionHeader writes the metadata of sess (everything except the messages)
+func writeSessionHeader(w io.Writer, sess *session.Session, cfg *config.Config) {
+	fmt.Fprintf(w, "Session: %s\n", sess.ID)
+	if sess.Name != "" {
+		fmt.Fprintf(w, "Name: %s\n", sess.Name)
+	}
+	if sess.BranchedAt > 0 {
+		fmt.Fprintf(w, "Parent: %s (branched at message %d)\n", sess.ParentID, sess.BranchedAt)
+	} else if sess.ParentID != "" {
+		fmt.Fprintf(w, "Parent: %s\n", sess.ParentID)
+	}
+	if _, _, err := llmc.ParseModelString(sess.Model); err != nil {
+		fmt.Fprintf(w, "Model: %s (invalid, expected provider:model)\n", sess.Model)
+	} else {
+		fmt.Fprintf(w, "Model: %s\n", sess.Model)
+	}
+	fmt.Fprintf(w, "Created: %s\n", cfg.FormatTime(sess.CreatedAt))
+	fmt.Fprintf(w, "Updated: %s\n", cfg.FormatTime(sess.UpdatedAt))
+	if sess.TemplateName != "" {
+		fmt.Fprintf(w, "Template: %s\n", sess.TemplateName)
+	}
+	if sess.SystemPrompt != "" {
+		fmt.Fprintf(w, "System Prompt: %s\n", sess.SystemPrompt)
+	}
+	if len(sess.DefaultArgs) > 0 {
+		fmt.Fprintf(w, "Default Args: %s\n", formatArgs(sess.DefaultArgs))
+	}
+	if sess.Sampling != nil {
+		fmt.Fprintf(w, "Sampling: %s\n", sess.Sampling)
+	}
+	if len(sess.Tags) > 0 {
+		fmt.Fprintf(w, "Tags: %s\n", strings.Join(sess.Tags, ", "))
+	}
+	fmt.Fprintf(w, "Messages: %d\n", sess.MessageCount())
+}
 
-			fmt.Printf("\n[%d] %s (%s):\n%s\n",
-				i+1,
-				roleLabel,
-				timestamp,
-				msg.Content,
-			)
+// writeSessionInfo writes the short summary of sess shown by /info and 'sessions info'
+func writeSessionInfo(w io.Writer, sess *session.Session, cfg *config.Config) {
+	fmt.Fprintln(w, "Session Information:")
+	fmt.Fprintf(w, "  ID: %s\n", sess.GetShortID())
+	fmt.Fprintf(w, "  Full ID: %s\n", sess.ID)
+	if sess.Name != "" {
+		fmt.Fprintf(w, "  Name: %s\n", sess.Name)
+	}
+	if sess.ParentID != "" {
+		fmt.Fprintf(w, "  Parent: %s\n", sess.ParentID)
+	}
+	fmt.Fprintf(w, "  Model: %s\n", sess.Model)
+	fmt.Fprintf(w, "  Messages: %d\n", sess.MessageCount())
+	fmt.Fprintf(w, "  Created: %s\n", cfg.FormatTime(sess.CreatedAt))
+	if sess.TemplateName != "" {
+		fmt.Fprintf(w, "  Template: %s\n", sess.TemplateName)
+	}
+	if len(sess.DefaultArgs) > 0 {
+		fmt.Fprintf(w, "  Default Args: %s\n", formatArgs(sess.DefaultArgs))
+	}
+	if sess.Sampling != nil {
+		fmt.Fprintf(w, "  Sampling: %s\n", sess.Sampling)
+	}
+}
+
+// sessionsInfoCmd represents the sessions info command
+var sessionsInfoCmd = &cobra.Command{
+	Use:   "info [id]",
+	Short: "Show a short summary of a session",
+	Long: `Show a short summary of a session (the same as /info in interactive mode):
+its ID, name, parent, model, message count, creation time and template,
+without the message history.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the
+most recent session. Without an ID, the latest session is shown.
+
+Examples:
+  llmc sessions info            # What is my current session?
+  llmc sessions info 550e8400`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessionID := "latest"
+		if len(args) > 0 {
+			sessionID = args[0]
 		}
 
-		fmt.Printf("\nContinue this session with:\n  llmc chat -s %s \"your message\"\n", sess.GetShortID())
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		sess, err := session.FindSessionByPrefix(sessionID)
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		writeSessionInfo(os.Stdout, sess, cfg)
 		return nil
 	},
 }
 
+// sessionMetadata is the JSON representation of a session in 'sessions show --json --metadata-only'.
+// Field names are part of the output contract and must be kept stable.
+type sessionMetadata struct {
+	ID           string               `json:"id"`
+	ShortID      string               `json:"short_id"`
+	Name         string               `json:"name"`
+	ParentID     string               `json:"parent_id"`
+	Model        string               `json:"model"`
+	Provider     string               `json:"provider"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	TemplateName string               `json:"template_name"`
+	SystemPrompt string               `json:"system_prompt"`
+	DefaultArgs  map[string]string    `json:"default_args,omitempty"`
+	Sampling     *llmc.SamplingParams `json:"sampling,omitempty"`
+	Tags         []string             `json:"tags"`
+	MessageCount int                  `json:"message_count"`
+}
+
+// sessionDetail is the JSON representation of a session in 'sessions show --json'
+type sessionDetail struct {
+	sessionMetadata
+	Messages []llmc.Message `json:"messages"`
+}
+
+// writeSessionJSON writes sess as JSON, without the messages if metadataOnly is set
+func writeSessionJSON(w io.Writer, sess *session.Session, metadataOnly, compact bool) error {
+	tags := sess.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	metadata := sessionMetadata{
+		ID:           sess.ID,
+		ShortID:      sess.GetShortID(),
+		Name:         sess.Name,
+		ParentID:     sess.ParentID,
+		Model:        sess.Model,
+		Provider:     sess.GetProvider(),
+		CreatedAt:    sess.CreatedAt,
+		UpdatedAt:    sess.UpdatedAt,
+		TemplateName: sess.TemplateName,
+		SystemPrompt: sess.SystemPrompt,
+		DefaultArgs:  sess.DefaultArgs,
+		Sampling:     sess.Sampling,
+		Tags:         tags,
+		MessageCount: sess.MessageCount(),
+	}
+
+	var v interface{} = metadata
+	if !metadataOnly {
+		messages := sess.Messages
+		if messages == nil {
+			messages = []llmc.Message{}
+		}
+		v = sessionDetail{sessionMetadata: metadata, Messages: messages}
+	}
+
+	if err := writeJSON(w, v, compact); err != nil {
+		return fmt.Errorf("serializing session: %w", err)
+	}
+	return nil
+}
+
+// writeRawMessage writes the JSON of message index (1-based) of sess as stored in its file
+func writeRawMessage(w io.Writer, sess *session.Session, index int, compact bool) error {
+	if index > sess.MessageCount() {
+		return newUsageError(fmt.Errorf("message %d not found (session %s has %d messages)", index, sess.GetShortID(), sess.MessageCount()))
+	}
+	raw, err := session.LoadRawMessage(sess.ID, index)
+	if err != nil {
+		return err
+	}
+
+	if err := writeRawJSON(w, raw, compact); err != nil {
+		return fmt.Errorf("formatting message: %w", err)
+	}
+	return nil
+}
+
 // sessionsDeleteCmd represents the sessions delete command
 var sessionsDeleteCmd = &cobra.Command{
 	Use:   "delete [id]",
@@ -263,44 +821,9 @@ Examples:
 			}
 
 			// Protect parent sessions that are referenced by child sessions
-			// Build a map of session IDs to delete for quick lookup
-			toDeleteMap := make(map[string]bool)
-			for _, sess := range sessionsToDelete {
-				toDeleteMap[sess.ID] = true
-			}
-
-			// Find parent sessions that should be protected
-			protectedParents := make(map[string]session.Session)
-			for _, sess := range sessions {
-				// If this session is not being deleted but its parent is
-				if !toDeleteMap[sess.ID] && sess.ParentID != "" && toDeleteMap[sess.ParentID] {
-					// Find the parent session in sessionsToDelete
-					for _, parent := range sessionsToDelete {
-						if parent.ID == sess.ParentID {
-							protectedParents[parent.ID] = parent
-							break
-						}
-					}
-				}
-			}
-
-			// Remove protected parents from deletion list
-			if len(protectedParents) > 0 {
-				var filteredSessions []session.Session
-				for _, sess := range sessionsToDelete {
-					if _, isProtected := protectedParents[sess.ID]; !isProtected {
-						filteredSessions = append(filteredSessions, sess)
-					}
-				}
-				sessionsToDelete = filteredSessions
-
-				// Display notice about protected sessions
-				fmt.Fprintf(os.Stderr, "\nNotice: The following sessions were not deleted (referenced by child sessions):\n")
-				for _, parent := range protectedParents {
-					fmt.Fprintf(os.Stderr, "  - %s (created: %s)\n", parent.GetShortID(), parent.CreatedAt.Format("2006-01-02"))
-				}
-				fmt.Fprintln(os.Stderr)
-			}
+			var protectedParents []session.Session
+			sessionsToDelete, protectedParents = excludeReferencedParents(sessions, sessionsToDelete)
+			writeProtectedParents(os.Stderr, protectedParents)
 
 			// Check if there are any sessions left to delete
 			if len(sessionsToDelete) == 0 {
@@ -353,43 +876,783 @@ Examples:
 	},
 }
 
-// sessionsRenameCmd represents the sessions rename command
-var sessionsRenameCmd = &cobra.Command{
-	Use:   "rename <id> <name>",
-	Short: "Rename a session",
-	Long: `Rename a conversation session.
-
-The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
-	Args: cobra.ExactArgs(2),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		sessionID := args[0]
-		newName := args[1]
+// excludeReferencedParents removes the sessions from toDelete that are the parent of a session
+// that is kept, directly or through other parents, and returns them as protected
+func excludeReferencedParents(sessions, toDelete []session.Session) (remaining, protected []session.Session) {
+	deleting := make(map[string]bool)
+	for _, sess := range toDelete {
+		deleting[sess.ID] = true
+	}
 
-		// Find session by prefix
-		sess, err := session.FindSessionByPrefix(sessionID)
-		if err != nil {
-			return fmt.Errorf("finding session: %w", err)
+	// Protecting a parent keeps it, which in turn protects its own parent
+	for changed := true; changed; {
+		changed = false
+		for _, sess := range sessions {
+			if !deleting[sess.ID] && sess.ParentID != "" && deleting[sess.ParentID] {
+				deleting[sess.ParentID] = false
+				changed = true
+			}
 		}
+	}
 
-		// Update session name
-		sess.Name = newName
-
-		// Save session
-		if err := session.SaveSession(sess); err != nil {
-			return fmt.Errorf("saving session: %w", err)
+	for _, sess := range toDelete {
+		if deleting[sess.ID] {
+			remaining = append(remaining, sess)
+		} else {
+			protected = append(protected, sess)
 		}
-
-		fmt.Printf("Session %s renamed to \"%s\".\n", sess.GetShortID(), newName)
-		return nil
-	},
+	}
+	return remaining, protected
 }
 
-// parseDate parses a date string in various formats and returns a time.Time
-// Supported formats: YYYY-MM-DD, YYYY-MM, YYYY
-func parseDate(dateStr string) (time.Time, error) {
-	// Try YYYY-MM-DD format
-	if t, err := time.Parse("2006-01-02", dateStr); err == nil {
-		return t, nil
+// writeProtectedParents writes a notice about sessions that were not deleted because they are parents
+func writeProtectedParents(w io.Writer, protected []session.Session) {
+	if len(protected) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nNotice: The following sessions were not deleted (referenced by child sessions):\n")
+	for _, parent := range protected {
+		fmt.Fprintf(w, "  - %s (created: %s)\n", parent.GetShortID(), parent.CreatedAt.Format("2006-01-02"))
+	}
+	fmt.Fprintln(w)
+}
+
+// sessionsGCCmd represents the sessions gc command
+var sessionsGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete all but the most recently updated sessions",
+	Long: `Delete sessions so that at most N remain, keeping the N most recently updated ones.
+
+N is given with --max-count or the max_sessions setting. Parent sessions of kept
+sessions are not deleted, so more than N sessions can remain.
+
+Warning: This action cannot be undone. Use --dry-run to see which sessions would be deleted.
+
+Examples:
+  llmc sessions gc --max-count 100            # Keep the 100 most recently updated sessions
+  llmc sessions gc --max-count 100 --dry-run  # Show which sessions would be deleted
+  llmc sessions gc                            # Keep max_sessions sessions`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		maxCount, _ := cmd.Flags().GetInt("max-count")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if cmd.Flags().Changed("max-count") {
+			if maxCount < 0 {
+				return newUsageError(fmt.Errorf("--max-count must be 0 or greater"))
+			}
+		} else {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			if cfg.MaxSessions == 0 {
+				fmt.Println("No maximum session count is set (max_sessions = 0).")
+				fmt.Println("Use --max-count to set one.")
+				return nil
+			}
+			maxCount = cfg.MaxSessions
+		}
+
+		sessions, err := session.ListSessions()
+		if err != nil {
+			return fmt.Errorf("listing sessions: %w", err)
+		}
+
+		sessionsToDelete, protectedParents := selectExcessSessions(sessions, maxCount)
+		writeProtectedParents(os.Stderr, protectedParents)
+		if len(sessionsToDelete) == 0 {
+			fmt.Printf("No sessions to delete (%d sessions, keeping at most %d).\n", len(sessions), maxCount)
+			return nil
+		}
+
+		if dryRun {
+			fmt.Printf("Would delete %d of %d sessions:\n", len(sessionsToDelete), len(sessions))
+			for _, sess := range sessionsToDelete {
+				fmt.Printf("  - %s (updated: %s)\n", sess.GetShortID(), sess.UpdatedAt.Format("2006-01-02"))
+			}
+			return nil
+		}
+
+		// Confirm deletion
+		fmt.Printf("Are you sure you want to delete %d of %d sessions, keeping the %d most recently updated? [y/N]: ",
+			len(sessionsToDelete), len(sessions), maxCount)
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" {
+			fmt.Println("Deletion cancelled.")
+			return nil
+		}
+
+		// Delete sessions
+		deleted := 0
+		failed := 0
+		for _, sess := range sessionsToDelete {
+			if err := session.DeleteSession(sess.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to delete session %s: %v\n", sess.GetShortID(), err)
+				failed++
+			} else {
+				deleted++
+			}
+		}
+
+		fmt.Printf("Successfully deleted %d sessions", deleted)
+		if failed > 0 {
+			fmt.Printf(" (%d failed)", failed)
+		}
+		fmt.Println(".")
+		return nil
+	},
+}
+
+// selectExcessSessions returns the sessions to delete so that the maxCount most recently
+// updated sessions are kept, and the parents that are kept although they are not among them
+func selectExcessSessions(sessions []session.Session, maxCount int) (toDelete, protected []session.Session) {
+	if len(sessions) <= maxCount {
+		return nil, nil
+	}
+	sorted := make([]session.Session, len(sessions))
+	copy(sorted, sessions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UpdatedAt.After(sorted[j].UpdatedAt)
+	})
+	return excludeReferencedParents(sorted, sorted[maxCount:])
+}
+
+// sessionsRenameCmd represents the sessions rename command
+var sessionsRenameCmd = &cobra.Command{
+	Use:   "rename <id> <name>",
+	Short: "Rename a session",
+	Long: `Rename a conversation session.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessionID := args[0]
+		newName := args[1]
+
+		// Find session by prefix
+		sess, err := session.FindSessionByPrefix(sessionID)
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		// Update session name
+		sess.Name = newName
+
+		// Save session
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Printf("Session %s renamed to \"%s\".\n", sess.GetShortID(), newName)
+		return nil
+	},
+}
+
+// sessionsTagCmd represents the sessions tag command
+var sessionsTagCmd = &cobra.Command{
+	Use:   "tag <id> <tag>...",
+	Short: "Add tags to a session",
+	Long: `Add tags to a conversation session to organize sessions.
+Tags the session already has are left as they are. List the sessions with a tag
+with 'llmc sessions list --tag <tag>'.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		added := sess.AddTags(args[1:]...)
+		if len(added) == 0 {
+			fmt.Printf("Session %s already has the given tags.\n", sess.GetShortID())
+			return nil
+		}
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Printf("Session %s tagged: %s\n", sess.GetShortID(), strings.Join(added, ", "))
+		return nil
+	},
+}
+
+// sessionsUntagCmd represents the sessions untag command
+var sessionsUntagCmd = &cobra.Command{
+	Use:   "untag <id> <tag>...",
+	Short: "Remove tags from a session",
+	Long: `Remove tags from a conversation session. Tags the session does not have are ignored.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		removed := sess.RemoveTags(args[1:]...)
+		if len(removed) == 0 {
+			fmt.Printf("Session %s has none of the given tags.\n", sess.GetShortID())
+			return nil
+		}
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Printf("Tags removed from session %s: %s\n", sess.GetShortID(), strings.Join(removed, ", "))
+		return nil
+	},
+}
+
+// sessionsBranchCmd represents the sessions branch command
+var sessionsBranchCmd = &cobra.Command{
+	Use:   "branch <id> --at <message-number>",
+	Short: "Branch a session at a message",
+	Long: `Create a new session that continues a conversation from one of its messages,
+to explore an alternative path without changing the original.
+
+The new session holds the messages up to and including message --at (as numbered
+by 'llmc sessions show'), has the original session as its parent, and uses the
+same model, system prompt and template.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		at, _ := cmd.Flags().GetInt("at")
+		if at < 1 {
+			return newUsageError(fmt.Errorf("--at must be a message number of 1 or greater"))
+		}
+
+		// Find session by prefix
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		branch, err := sess.Branch(at)
+		if err != nil {
+			return newUsageError(err)
+		}
+		if err := session.SaveSession(branch); err != nil {
+			return fmt.Errorf("saving new session: %w", err)
+		}
+
+		fmt.Fprintf(os.Stderr, "New session created: %s (parent: %s, %d message(s) copied)\n", branch.GetShortID(), sess.GetShortID(), at)
+		sessionDir, _ := session.GetSessionDir()
+		fmt.Fprintf(os.Stderr, "Path: %s/%s.json\n", sessionDir, branch.ID)
+		fmt.Fprintf(os.Stderr, "\nContinue with:\n  llmc chat -s %s \"your message\"\n", branch.GetShortID())
+		return nil
+	},
+}
+
+// sessionsEditCmd represents the sessions edit command
+var sessionsEditCmd = &cobra.Command{
+	Use:   "edit <id> --delete <message-number>",
+	Short: "Delete messages from a session",
+	Long: `Delete messages from a conversation session, e.g. to prune a bad turn before
+continuing the conversation.
+
+--delete takes a message number as shown by 'llmc sessions show', or a range of
+message numbers such as 3-5. A warning is printed when the deletion leaves a user
+message without its response or a response without its user message; the messages
+are deleted anyway.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.
+
+Examples:
+  llmc sessions edit latest --delete 4
+  llmc sessions edit 550e8400 --delete 3-4`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		del, _ := cmd.Flags().GetString("delete")
+		if del == "" {
+			return newUsageError(fmt.Errorf("--delete is required"))
+		}
+		first, last, err := parseMessageRange(del)
+		if err != nil {
+			return newUsageError(err)
+		}
+
+		// Find session by prefix
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		warnings := orphanedMessageWarnings(sess.Messages, first, last)
+		if err := sess.DeleteMessages(first, last); err != nil {
+			return newUsageError(err)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Printf("Deleted %d message(s) from session %s (%d remaining).\n", last-first+1, sess.GetShortID(), len(sess.Messages))
+		return nil
+	},
+}
+
+// sessionsCleanCmd represents the sessions clean command
+var sessionsCleanCmd = &cobra.Command{
+	Use:   "clean <id>",
+	Short: "Remove empty messages from a session",
+	Long: `Remove the messages of a session whose content is empty or only whitespace,
+e.g. left behind by a failed turn, since providers may reject a history with
+empty turns. Messages of the same role that end up next to each other are merged
+so that user and assistant messages alternate again.
+
+The session file is backed up to <id>.json.bak before it is changed.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Find session by prefix
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		removed, merged := sess.TrimEmptyMessages()
+		if removed == 0 {
+			fmt.Printf("Session %s has no empty messages.\n", sess.GetShortID())
+			return nil
+		}
+
+		// Back up the session file before saving the changes
+		backupFile, err := session.BackupSession(sess.ID)
+		if err != nil {
+			return fmt.Errorf("backing up session: %w", err)
+		}
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Printf("Removed %d empty message(s) from session %s", removed, sess.GetShortID())
+		if merged > 0 {
+			fmt.Printf(" and merged %d message(s) into the one before", merged)
+		}
+		fmt.Printf(" (%d remaining).\n", len(sess.Messages))
+		fmt.Printf("Backup: %s\n", backupFile)
+		return nil
+	},
+}
+
+// parseMessageRange parses a message number ("4") or an inclusive range of message numbers ("3-5")
+func parseMessageRange(value string) (first, last int, err error) {
+	start, end, isRange := strings.Cut(value, "-")
+	first, err = strconv.Atoi(strings.TrimSpace(start))
+	if err != nil || first < 1 {
+		return 0, 0, fmt.Errorf("invalid message number %q (expected N or N-M with N >= 1)", value)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	last, err = strconv.Atoi(strings.TrimSpace(end))
+	if err != nil || last < first {
+		return 0, 0, fmt.Errorf("invalid message range %q (expected N-M with N <= M)", value)
+	}
+	return first, last, nil
+}
+
+// orphanedMessageWarnings describes the messages around messages first through last
+// (1-based) that lose their user or assistant counterpart when those are deleted
+func orphanedMessageWarnings(messages []llmc.Message, first, last int) []string {
+	if first < 1 || last > len(messages) || first > last {
+		return nil
+	}
+	var warnings []string
+	if first > 1 && messages[first-2].Role == "user" && messages[first-1].Role == "assistant" {
+		warnings = append(warnings, fmt.Sprintf("message %d (user) is left without its response", first-1))
+	}
+	if last < len(messages) && messages[last-1].Role == "user" && messages[last].Role == "assistant" {
+		warnings = append(warnings, fmt.Sprintf("message %d (assistant) is left without its user message", last+1))
+	}
+	return warnings
+}
+
+// sessionsMoveCmd represents the sessions move command
+var sessionsMoveCmd = &cobra.Command{
+	Use:   "move <id> (--parent <parent-id> | --detach)",
+	Short: "Change the parent of a session",
+	Long: `Change the parent of a conversation session to reorganize the session tree.
+
+Use --parent to set a new parent session or --detach to make the session a root session.
+A session cannot be its own parent, and a session cannot be moved under one of its descendants.
+
+The IDs can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parentID, _ := cmd.Flags().GetString("parent")
+		detach, _ := cmd.Flags().GetBool("detach")
+		if (parentID == "") == !detach {
+			return newUsageError(fmt.Errorf("specify either --parent or --detach"))
+		}
+
+		// Find session by prefix
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		message := fmt.Sprintf("Session %s detached from its parent.", sess.GetShortID())
+		if detach {
+			if sess.ParentID == "" {
+				fmt.Printf("Session %s has no parent.\n", sess.GetShortID())
+				return nil
+			}
+			sess.ParentID = ""
+		} else {
+			parent, err := session.FindSessionByPrefix(parentID)
+			if err != nil {
+				return fmt.Errorf("finding parent session: %w", err)
+			}
+			if err := checkReparent(sess, parent, func(id string) (*session.Session, error) {
+				return session.FindSessionByPrefix(id)
+			}); err != nil {
+				return err
+			}
+			sess.ParentID = parent.ID
+			message = fmt.Sprintf("Session %s moved under %s.", sess.GetShortID(), parent.GetShortID())
+		}
+
+		// Save session
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Println(message)
+		return nil
+	},
+}
+
+// checkReparent checks that parent can become the parent of sess: it must not be
+// sess itself or one of its descendants. findSession looks up a session by ID
+// to walk up the ancestry of parent.
+func checkReparent(sess, parent *session.Session, findSession func(id string) (*session.Session, error)) error {
+	if parent.ID == sess.ID {
+		return fmt.Errorf("session %s cannot be its own parent", sess.GetShortID())
+	}
+
+	visited := map[string]bool{parent.ID: true}
+	for currentID := parent.ParentID; currentID != ""; {
+		if currentID == sess.ID {
+			return fmt.Errorf("cannot move session %s under %s: %s is a descendant of %s, which would create a cycle",
+				sess.GetShortID(), parent.GetShortID(), parent.GetShortID(), sess.GetShortID())
+		}
+		if visited[currentID] {
+			return fmt.Errorf("circular reference detected in session ancestry")
+		}
+		visited[currentID] = true
+
+		ancestor, err := findSession(currentID)
+		if err != nil {
+			// The chain is broken above this point, so it cannot reach sess
+			break
+		}
+		currentID = ancestor.ParentID
+	}
+	return nil
+}
+
+// sessionsSedCmd represents the sessions sed command
+var sessionsSedCmd = &cobra.Command{
+	Use:   "sed <id> --find <pattern> --replace <text>",
+	Short: "Find and replace text in the messages of a session",
+	Long: `Find and replace text in all message contents of a session, e.g. to redact
+secrets accidentally pasted into a conversation before exporting it.
+
+The pattern is matched literally unless --regex is given, in which case it is a
+Go regular expression and the replacement can refer to groups as $1, ${name}, etc.
+
+The number of replacements per message is shown and confirmed before anything
+is changed. The session file is backed up to <id>.json.bak first.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.
+
+Examples:
+  llmc sessions sed 550e8400 --find sk-abc123 --replace "[REDACTED]"
+  llmc sessions sed latest --regex --find 'sk-[A-Za-z0-9]+' --replace "[REDACTED]"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		find, _ := cmd.Flags().GetString("find")
+		replace, _ := cmd.Flags().GetString("replace")
+		useRegex, _ := cmd.Flags().GetBool("regex")
+		if find == "" {
+			return newUsageError(fmt.Errorf("--find is required"))
+		}
+		if !cmd.Flags().Changed("replace") {
+			return newUsageError(fmt.Errorf("--replace is required (use --replace \"\" to delete matches)"))
+		}
+
+		// Find session by prefix
+		sess, err := session.FindSessionByPrefix(args[0])
+		if err != nil {
+			return fmt.Errorf("finding session: %w", err)
+		}
+
+		messages, counts, err := replaceInMessages(sess.Messages, find, replace, useRegex)
+		if err != nil {
+			return newUsageError(err)
+		}
+
+		// Report replacements per message
+		total := 0
+		for i, count := range counts {
+			if count == 0 {
+				continue
+			}
+			total += count
+			fmt.Printf("Message %d (%s): %d replacement(s)\n", i+1, sess.Messages[i].Role, count)
+		}
+		if total == 0 {
+			fmt.Printf("No matches found in session %s.\n", sess.GetShortID())
+			return nil
+		}
+
+		// Confirm replacement
+		fmt.Printf("Apply %d replacement(s) to session %s? [y/N]: ", total, sess.GetShortID())
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" {
+			fmt.Println("Replacement cancelled.")
+			return nil
+		}
+
+		// Back up the session before changing it
+		backupFile, err := session.BackupSession(sess.ID)
+		if err != nil {
+			return fmt.Errorf("backing up session: %w", err)
+		}
+
+		sess.Messages = messages
+		if err := session.SaveSession(sess); err != nil {
+			return fmt.Errorf("saving session: %w", err)
+		}
+
+		fmt.Printf("Replaced %d occurrence(s) in session %s.\n", total, sess.GetShortID())
+		fmt.Printf("Backup: %s\n", backupFile)
+		return nil
+	},
+}
+
+// replaceInMessages replaces find with replace in the content of each message.
+// find is a regular expression if useRegex is set, and a literal string otherwise.
+// It returns the updated copy of messages and the number of replacements per message.
+func replaceInMessages(messages []llmc.Message, find, replace string, useRegex bool) ([]llmc.Message, []int, error) {
+	var re *regexp.Regexp
+	if useRegex {
+		var err error
+		re, err = regexp.Compile(find)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --find pattern: %w", err)
+		}
+	}
+
+	updated := make([]llmc.Message, len(messages))
+	counts := make([]int, len(messages))
+	for i, msg := range messages {
+		updated[i] = msg
+		if re != nil {
+			counts[i] = len(re.FindAllStringIndex(msg.Content, -1))
+			updated[i].Content = re.ReplaceAllString(msg.Content, replace)
+		} else {
+			counts[i] = strings.Count(msg.Content, find)
+			updated[i].Content = strings.ReplaceAll(msg.Content, find, replace)
+		}
+	}
+	return updated, counts, nil
+}
+
+// sessionsRepairCmd represents the sessions repair command
+var sessionsRepairCmd = &cobra.Command{
+	Use:   "repair [id]",
+	Short: "Recover corrupted session files",
+	Long: `Recover corrupted session files (e.g. truncated by an interrupted write).
+
+The readable fields and all complete messages are salvaged and written to
+<id>.recovered.json next to the original file, which is left untouched.
+What could not be recovered is reported so that you can review the result
+before replacing the original file with it.
+
+Without an ID, all corrupted sessions are repaired. The ID must be a full UUID
+or a prefix of one (minimum 4 characters), since corrupted sessions cannot be listed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := session.ListCorruptedSessions()
+		if err != nil {
+			return fmt.Errorf("finding corrupted sessions: %w", err)
+		}
+
+		// Keep only the requested session
+		if len(args) > 0 {
+			prefix := args[0]
+			if len(prefix) < 4 {
+				return newUsageError(fmt.Errorf("session ID prefix must be at least 4 characters (got %d)", len(prefix)))
+			}
+			var matches []string
+			for _, id := range ids {
+				if strings.HasPrefix(id, prefix) {
+					matches = append(matches, id)
+				}
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("no corrupted session found for %s", prefix)
+			}
+			if len(matches) > 1 {
+				return fmt.Errorf("ambiguous session ID %q matches %d corrupted sessions: %s", prefix, len(matches), strings.Join(matches, ", "))
+			}
+			ids = matches
+		}
+
+		if len(ids) == 0 {
+			fmt.Println("No corrupted sessions found.")
+			return nil
+		}
+
+		var failed int
+		for _, id := range ids {
+			_, report, path, err := session.RepairSession(id)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: could not recover session %s: %v\n", id, err)
+				failed++
+				continue
+			}
+			writeRecoveryReport(os.Stdout, id, report, path)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d corrupted session(s) could not be recovered", failed, len(ids))
+		}
+		return nil
+	},
+}
+
+// writeRecoveryReport writes what was recovered from the session with the given ID and what was lost
+func writeRecoveryReport(w io.Writer, id string, report *session.RecoveryReport, path string) {
+	fmt.Fprintf(w, "Session %s:\n", id)
+	fmt.Fprintf(w, "  Recovered: %d message(s)", report.RecoveredMessages)
+	if len(report.RecoveredFields) > 0 {
+		fmt.Fprintf(w, ", fields: %s", strings.Join(report.RecoveredFields, ", "))
+	}
+	fmt.Fprintln(w)
+	if len(report.LostFields) > 0 {
+		fmt.Fprintf(w, "  Lost fields: %s\n", strings.Join(report.LostFields, ", "))
+	}
+	if report.MessagesTruncated {
+		fmt.Fprintf(w, "  Lost messages: everything after message %d\n", report.RecoveredMessages)
+	}
+	fmt.Fprintf(w, "  Written to: %s\n", path)
+	fmt.Fprintf(w, "  Review it and replace the original with: mv %s %s\n", path, strings.TrimSuffix(path, session.RecoveredSuffix+".json")+".json")
+}
+
+// writeMessageHistory writes the messages of sess to w with their original index labels.
+// If first or last is greater than 0, only the first and/or last N messages are written;
+// when both are set, an elision marker is written between the head and the tail.
+func writeMessageHistory(w io.Writer, sess *session.Session, cfg *config.Config, first, last int, since time.Time) {
+	n := len(sess.Messages)
+	headEnd, tailStart := n, n
+	if first > 0 || last > 0 {
+		headEnd = min(first, n)
+		tailStart = max(n-last, headEnd)
+	}
+
+	if !since.IsZero() {
+		if hidden := countMessagesBefore(sess.Messages, since); hidden > 0 {
+			fmt.Fprintf(w, "\n... (%d messages before %s omitted) ...\n", hidden, cfg.FormatTime(since))
+		}
+	}
+
+	writeMessage := func(i int) {
+		msg := sess.Messages[i]
+		if msg.Timestamp.Before(since) {
+			return
+		}
+		label := cfg.RoleLabel(msg.Role)
+		// Annotate turns generated by another model than the session's (e.g. after a fallback)
+		if msg.Model != "" && msg.Model != sess.Model {
+			label = fmt.Sprintf("%s (%s)", label, msg.Model)
+		}
+		fmt.Fprintf(w, "\n[%d] %s (%s):\n%s\n",
+			i+1,
+			label,
+			cfg.FormatTime(msg.Timestamp),
+			msg.Content,
+		)
+	}
+
+	for i := 0; i < headEnd; i++ {
+		writeMessage(i)
+	}
+	if omitted := tailStart - headEnd; omitted > 0 && first > 0 && last > 0 {
+		fmt.Fprintf(w, "\n... (%d messages omitted) ...\n", omitted)
+	}
+	for i := tailStart; i < n; i++ {
+		writeMessage(i)
+	}
+}
+
+// countMessagesBefore returns the number of messages added before t
+func countMessagesBefore(messages []llmc.Message, t time.Time) int {
+	count := 0
+	for _, msg := range messages {
+		if msg.Timestamp.Before(t) {
+			count++
+		}
+	}
+	return count
+}
+
+// parseSince parses a --since value: a duration before now (Go syntax such as 90m or 2h,
+// or a number of days such as 3d) or an absolute time accepted by parseDate or RFC 3339
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := parseDate(value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s' (use a duration such as 2h or 3d, or a date such as 2025-01-01)", value)
+}
+
+// formatArgs formats template arguments as "key:value" pairs sorted by key
+func formatArgs(args map[string]string) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s:%s", key, args[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// parseDate parses a date string in various formats and returns a time.Time
+// Supported formats: YYYY-MM-DD, YYYY-MM, YYYY
+func parseDate(dateStr string) (time.Time, error) {
+	// Try YYYY-MM-DD format
+	if t, err := time.Parse("2006-01-02", dateStr); err == nil {
+		return t, nil
 	}
 
 	// Try YYYY-MM format (use first day of month)
@@ -412,6 +1675,8 @@ var sessionsSummarizeCmd = &cobra.Command{
 	Long: `Summarize a conversation session and create a new session with the summary.
 
 The original session is preserved and the new session has its ParentID set.
+With --summary-to, the summary is instead appended as a message to an existing
+session, e.g. a long-running "notes" session collecting summaries of many conversations.
 The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -427,65 +1692,157 @@ The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the
 			return fmt.Errorf("session %s has no messages to summarize", sess.GetShortID())
 		}
 
-		// Collect all ancestor sessions
-		ancestors, err := collectAncestorSessions(sess)
+		// Resolve the session receiving the summary, if any
+		var target *session.Session
+		if summaryTo, _ := cmd.Flags().GetString("summary-to"); summaryTo != "" {
+			target, err = session.FindSessionByPrefix(summaryTo)
+			if err != nil {
+				return fmt.Errorf("finding target session: %w", err)
+			}
+			if target.ID == sess.ID {
+				return newUsageError(fmt.Errorf("--summary-to must name a session other than %s", sess.GetShortID()))
+			}
+		}
+
+		// Load config
+		cfg, err := config.LoadConfig()
 		if err != nil {
-			return fmt.Errorf("collecting ancestor sessions: %w", err)
+			return fmt.Errorf("loading config: %w", err)
 		}
 
-		// Count total messages
-		totalMessages := 0
-		for _, ancestorSess := range ancestors {
-			// Skip first message if session has a parent (it's a summary)
-			if ancestorSess.ParentID != "" && ancestorSess.MessageCount() > 0 {
-				totalMessages += ancestorSess.MessageCount() - 1
-			} else {
-				totalMessages += ancestorSess.MessageCount()
+		// Use the original session's model for summarization
+		cfg.Model = sess.Model
+
+		// Create provider
+		llmProvider, err := newProvider(cfg)
+		if err != nil {
+			return fmt.Errorf("creating provider: %w", err)
+		}
+		llmProvider, err = withRedaction(cfg, llmProvider)
+		if err != nil {
+			return fmt.Errorf("creating provider: %w", err)
+		}
+		llmProvider.SetDebug(verbose)
+		llmProvider.SetTimeout(resolveTimeout(cfg))
+
+		// Generate summary and create new session (Ctrl+C aborts the request)
+		ctx, stop := interruptContext()
+		defer stop()
+		if target != nil {
+			if err := summarizeSessionInto(ctx, sess, target, llmProvider); err != nil {
+				if errors.Is(err, context.Canceled) {
+					return errCancelled
+				}
+				return err
 			}
+			fmt.Fprintf(os.Stderr, "\nSummary of %s appended to session: %s\n", sess.GetShortID(), target.GetShortID())
+			return nil
 		}
-		// Add current session messages (skip first if it has parent)
-		if sess.ParentID != "" && sess.MessageCount() > 0 {
-			totalMessages += sess.MessageCount() - 1
-		} else {
-			totalMessages += sess.MessageCount()
+		newSess, err := summarizeSession(ctx, sess, llmProvider)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return errCancelled
+			}
+			return err
 		}
 
-		fmt.Fprintf(os.Stderr, "Summarizing %d messages from session %s", totalMessages, sess.GetShortID())
-		if len(ancestors) > 0 {
-			fmt.Fprintf(os.Stderr, " and %d ancestor session(s)", len(ancestors))
+		// Save new session
+		if err := session.SaveSession(newSess); err != nil {
+			return fmt.Errorf("saving new session: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "...\n")
 
-		// Build conversation history for summarization including ancestors
-		var conversationText strings.Builder
-		messageNum := 1
+		fmt.Fprintf(os.Stderr, "\nNew session created: %s (parent: %s)\n", newSess.GetShortID(), sess.GetShortID())
+		sessionDir, _ := session.GetSessionDir()
+		fmt.Fprintf(os.Stderr, "Path: %s/%s.json\n", sessionDir, newSess.ID)
+		fmt.Fprintf(os.Stderr, "\nContinue with:\n  llmc chat -s %s \"your message\"\n", newSess.GetShortID())
+		return nil
+	},
+}
 
-		// Add ancestor messages first (oldest to newest)
-		for _, ancestorSess := range ancestors {
-			startIdx := 0
-			// Skip first message if this ancestor has a parent (it's a summary)
-			if ancestorSess.ParentID != "" && ancestorSess.MessageCount() > 0 {
-				startIdx = 1
-			}
+// summarizeSession asks provider to summarize sess, including its ancestor sessions,
+// and returns a new unsaved session that starts from the summary and has sess as parent
+func summarizeSession(ctx context.Context, sess *session.Session, llmProvider llmc.Provider) (*session.Session, error) {
+	summary, err := generateSummary(ctx, sess, llmProvider)
+	if err != nil {
+		return nil, err
+	}
 
-			for i := startIdx; i < len(ancestorSess.Messages); i++ {
-				msg := ancestorSess.Messages[i]
-				role := "User"
-				if msg.Role == "assistant" {
-					role = "Assistant"
-				}
-				conversationText.WriteString(fmt.Sprintf("[Message %d] %s: %s\n\n", messageNum, role, msg.Content))
-				messageNum++
-			}
+	// Create new session with summary
+	newSess := session.NewSession(sess.Model)
+	newSess.ParentID = sess.ID
+	newSess.SystemPrompt = sess.SystemPrompt
+	newSess.TemplateName = sess.TemplateName
+	newSess.DefaultArgs = sess.DefaultArgs
+	newSess.Sampling = sess.Sampling
+
+	// Add summary as first user message with context
+	summaryMessage := fmt.Sprintf("Previous conversation summary:\n\n%s", summary)
+	newSess.AddMessage("user", summaryMessage)
+
+	return newSess, nil
+}
+
+// summarizeSessionInto asks provider to summarize sess, including its ancestor sessions,
+// and appends the summary as a user message to target, which is saved. sess is left unchanged.
+func summarizeSessionInto(ctx context.Context, sess, target *session.Session, llmProvider llmc.Provider) error {
+	summary, err := generateSummary(ctx, sess, llmProvider)
+	if err != nil {
+		return err
+	}
+
+	target.AddMessage("user", fmt.Sprintf("Summary of session %s:\n\n%s", sess.GetDisplayName(), summary))
+	if err := session.SaveSession(target); err != nil {
+		return fmt.Errorf("saving target session: %w", err)
+	}
+	return nil
+}
+
+// generateSummary asks provider to summarize sess, including its ancestor sessions
+func generateSummary(ctx context.Context, sess *session.Session, llmProvider llmc.Provider) (string, error) {
+	// Collect all ancestor sessions
+	ancestors, err := collectAncestorSessions(sess)
+	if err != nil {
+		return "", fmt.Errorf("collecting ancestor sessions: %w", err)
+	}
+	ancestors = ancestorsSinceBranch(ancestors, sess)
+
+	// Count total messages
+	totalMessages := 0
+	for _, ancestorSess := range ancestors {
+		// Skip first message if it is a summary of the parent
+		if ancestorSess.StartsWithSummary() {
+			totalMessages += ancestorSess.MessageCount() - 1
+		} else {
+			totalMessages += ancestorSess.MessageCount()
 		}
+	}
+	// Add current session messages (skip first if it is a summary)
+	if sess.StartsWithSummary() {
+		totalMessages += sess.MessageCount() - 1
+	} else {
+		totalMessages += sess.MessageCount()
+	}
+
+	fmt.Fprintf(os.Stderr, "Summarizing %d messages from session %s", totalMessages, sess.GetShortID())
+	if len(ancestors) > 0 {
+		fmt.Fprintf(os.Stderr, " and %d ancestor session(s)", len(ancestors))
+	}
+	fmt.Fprintf(os.Stderr, "...\n")
 
-		// Add current session messages
+	// Build conversation history for summarization including ancestors
+	var conversationText strings.Builder
+	messageNum := 1
+
+	// Add ancestor messages first (oldest to newest)
+	for _, ancestorSess := range ancestors {
 		startIdx := 0
-		if sess.ParentID != "" && sess.MessageCount() > 0 {
+		// Skip first message if it is a summary of the parent
+		if ancestorSess.StartsWithSummary() {
 			startIdx = 1
 		}
-		for i := startIdx; i < len(sess.Messages); i++ {
-			msg := sess.Messages[i]
+
+		for i := startIdx; i < len(ancestorSess.Messages); i++ {
+			msg := ancestorSess.Messages[i]
 			role := "User"
 			if msg.Role == "assistant" {
 				role = "Assistant"
@@ -493,63 +1850,56 @@ The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the
 			conversationText.WriteString(fmt.Sprintf("[Message %d] %s: %s\n\n", messageNum, role, msg.Content))
 			messageNum++
 		}
+	}
 
-		// Create summarization prompt
-		summarizationPrompt := fmt.Sprintf(`Please summarize the following conversation in 3-5 concise paragraphs.
-Focus on:
-- Main topics discussed
-- Key decisions made
-- Current status or next steps
-
-Conversation history:
-
-%s`, conversationText.String())
-
-		// Load config
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
-		}
-
-		// Use the original session's model for summarization
-		cfg.Model = sess.Model
-
-		// Create provider
-		llmProvider, err := newProvider(cfg)
-		if err != nil {
-			return fmt.Errorf("creating provider: %w", err)
+	// Add current session messages
+	startIdx := 0
+	if sess.StartsWithSummary() {
+		startIdx = 1
+	}
+	for i := startIdx; i < len(sess.Messages); i++ {
+		msg := sess.Messages[i]
+		role := "User"
+		if msg.Role == "assistant" {
+			role = "Assistant"
 		}
-		llmProvider.SetDebug(verbose)
+		conversationText.WriteString(fmt.Sprintf("[Message %d] %s: %s\n\n", messageNum, role, msg.Content))
+		messageNum++
+	}
 
-		fmt.Fprintf(os.Stderr, "Generating summary using %s...\n", sess.Model)
+	// Create summarization prompt
+	summarizationPrompt := fmt.Sprintf(`Please summarize the following conversation in 3-5 concise paragraphs.
+Focus on:
+- Main topics discussed
+- Key decisions made
+- Current status or next steps
 
-		// Generate summary
-		summary, err := llmProvider.Chat(summarizationPrompt)
-		if err != nil {
-			return fmt.Errorf("generating summary: %w", err)
-		}
+Conversation history:
 
-		// Create new session with summary
-		newSess := session.NewSession(sess.Model)
-		newSess.ParentID = sess.ID
-		newSess.SystemPrompt = sess.SystemPrompt
-		newSess.TemplateName = sess.TemplateName
+%s`, conversationText.String())
 
-		// Add summary as first user message with context
-		summaryMessage := fmt.Sprintf("Previous conversation summary:\n\n%s", summary)
-		newSess.AddMessage("user", summaryMessage)
+	fmt.Fprintf(os.Stderr, "Generating summary using %s...\n", sess.Model)
 
-		// Save new session
-		if err := session.SaveSession(newSess); err != nil {
-			return fmt.Errorf("saving new session: %w", err)
-		}
+	// Generate summary
+	summary, err := llmProvider.ChatContext(ctx, summarizationPrompt)
+	if err != nil {
+		return "", fmt.Errorf("generating summary: %w", err)
+	}
+	return summary, nil
+}
 
-		fmt.Fprintf(os.Stderr, "\nNew session created: %s (parent: %s)\n", newSess.GetShortID(), sess.GetShortID())
-		sessionDir, _ := session.GetSessionDir()
-		fmt.Fprintf(os.Stderr, "Path: %s/%s.json\n", sessionDir, newSess.ID)
-		fmt.Fprintf(os.Stderr, "\nContinue with:\n  llmc chat -s %s \"your message\"\n", newSess.GetShortID())
+// ancestorsSinceBranch drops the ancestors (oldest first) whose messages are already
+// copied into sess or into a later ancestor that was branched from its parent
+func ancestorsSinceBranch(ancestors []*session.Session, sess *session.Session) []*session.Session {
+	if sess.BranchedAt > 0 {
 		return nil
-	},
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if ancestors[i].BranchedAt > 0 {
+			return ancestors[i:]
+		}
+	}
+	return ancestors
 }
 
 // collectAncestorSessions collects all ancestor sessions by following ParentID chain
@@ -604,6 +1954,10 @@ Examples:
 		if err != nil {
 			return fmt.Errorf("loading config: %w", err)
 		}
+		samplingFlags, err := samplingFromFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		var sess *session.Session
 
@@ -617,16 +1971,29 @@ Examples:
 				return fmt.Errorf("finding session: %w", err)
 			}
 
-			// Use session's model
-			cfg.Model = sess.Model
+			// Use session's model (or a replacement when the stored one is invalid)
+			modelFlag, _ := cmd.Flags().GetString("model")
+			cfg.Model, err = resolveSessionModel(sess, cfg.QualifyModel(modelFlag), os.Stdin, os.Stderr, readline.IsTerminal(int(os.Stdin.Fd())))
+			if err != nil {
+				return err
+			}
 
 			if verbose {
 				fmt.Fprintf(os.Stderr, "Continuing session: %s\n", sess.GetShortID())
-				fmt.Fprintf(os.Stderr, "Model: %s\n", sess.Model)
+				fmt.Fprintf(os.Stderr, "Model: %s\n", cfg.Model)
 			}
 		} else {
 			// Create new session
+			if modelFlag, _ := cmd.Flags().GetString("model"); modelFlag != "" {
+				modelFlag = cfg.QualifyModel(modelFlag)
+				if _, _, err := llmc.ParseModelString(modelFlag); err != nil {
+					return newUsageError(fmt.Errorf("invalid model from flag: %w", err))
+				}
+				cfg.Model = modelFlag
+			}
 			sess = session.NewSession(cfg.Model)
+			sampling := resolveSampling(cfg, nil, samplingFlags)
+			sess.Sampling = &sampling
 
 			if verbose {
 				fmt.Fprintf(os.Stderr, "Creating new session: %s\n", sess.GetShortID())
@@ -643,15 +2010,42 @@ Examples:
 			fmt.Fprintf(os.Stderr, "Path: %s/%s.json\n\n", sessionDir, sess.ID)
 		}
 
-		// Create provider
-		llmProvider, err := newProvider(cfg)
+		// Create provider; /load uses the same settings for the session it switches to
+		noFallback, _ := cmd.Flags().GetBool("no-fallback")
+		providerFor := func(s *session.Session) (llmc.Provider, error) {
+			sessCfg := *cfg
+			sessCfg.Model = sessionModel(s, cfg.Model)
+			p, err := newChatProvider(&sessCfg, noFallback)
+			if err != nil {
+				return nil, err
+			}
+			p.SetDebug(verbose)
+			p.SetTimeout(resolveTimeout(&sessCfg))
+			p.SetSampling(resolveSampling(&sessCfg, s, samplingFlags))
+			return p, nil
+		}
+		llmProvider, err := providerFor(sess)
 		if err != nil {
 			return fmt.Errorf("creating provider: %w", err)
 		}
-		llmProvider.SetDebug(verbose)
 
 		// Start interactive mode
-		if err := runInteractiveMode(sess, llmProvider); err != nil {
+		autoSummarize, _ := cmd.Flags().GetBool("auto-summarize")
+		maxLines := cfg.MaxDisplayLines
+		if noPager, _ := cmd.Flags().GetBool("no-pager"); noPager {
+			maxLines = 0
+		}
+		showUsage, _ := cmd.Flags().GetBool("usage")
+		historyFile, _ := cmd.Flags().GetString("history-file")
+		noHistory, _ := cmd.Flags().GetBool("no-history")
+		history := newInputHistory(historyFile, cfg.HistorySize, noHistory)
+		summarizeThreshold := cfg.SessionMessageThreshold
+		if noAutoSummarize, _ := cmd.Flags().GetBool("no-auto-summarize"); noAutoSummarize {
+			summarizeThreshold = 0
+		}
+		noRender, _ := cmd.Flags().GetBool("no-render")
+		pager := &responsePager{maxLines: maxLines, render: !noRender && readline.IsTerminal(int(os.Stdout.Fd()))}
+		if err := runInteractiveMode(sess, llmProvider, providerFor, cfg, samplingFlags, autoSummarize, pager, showUsage, history, summarizeThreshold); err != nil {
 			return fmt.Errorf("interactive mode: %w", err)
 		}
 
@@ -659,77 +2053,177 @@ Examples:
 	},
 }
 
-// runInteractiveMode starts an interactive chat session
-func runInteractiveMode(sess *session.Session, llmProvider llmc.Provider) error {
-	// Print session header
-	fmt.Fprintf(os.Stderr, "\n=== Interactive Session [%s] ===\n", sess.GetShortID())
-	fmt.Fprintf(os.Stderr, "Model: %s\n", sess.Model)
-	if sess.SystemPrompt != "" {
-		fmt.Fprintf(os.Stderr, "System Prompt: %s\n", sess.SystemPrompt)
+// sessionModel returns the stored model of sess, or fallback (the model resolved at
+// startup) when the stored one is invalid
+func sessionModel(sess *session.Session, fallback string) string {
+	if _, _, err := llmc.ParseModelString(sess.Model); err == nil {
+		return sess.Model
+	}
+	return fallback
+}
+
+// addTurn adds an exchange to sess. The response is attributed to the model that
+// answered: model (the model sess is continued with) unless a fallback model answered.
+func addTurn(sess *session.Session, message, response string, llmProvider llmc.Provider, model string) {
+	sess.AddMessage("user", message)
+	sess.AddAssistantMessage(response, llmc.ResponseModel(llmProvider, model))
+}
+
+// resolveSessionModel returns the model to continue sess with. A valid stored model is used as is.
+// When it cannot be parsed (e.g. a hand-edited "gpt-4"), override (the --model flag) is used,
+// or the user is asked for a model when interactive. Interactive users are then offered
+// to store the model in the session file; otherwise the stored value is left as it is.
+func resolveSessionModel(sess *session.Session, override string, in io.Reader, out io.Writer, interactive bool) (string, error) {
+	if _, _, err := llmc.ParseModelString(sess.Model); err == nil {
+		return sess.Model, nil
+	}
+
+	fmt.Fprintf(out, "Warning: session %s has an invalid model %q (expected provider:model, e.g. openai:gpt-4)\n", sess.GetShortID(), sess.Model)
+	model := override
+	if model == "" {
+		if !interactive {
+			return "", newUsageError(fmt.Errorf("session %s has an invalid model %q; use --model provider:model to continue it", sess.GetShortID(), sess.Model))
+		}
+		fmt.Fprint(out, "Model to use (provider:model): ")
+		fmt.Fscanln(in, &model)
+	}
+	if _, _, err := llmc.ParseModelString(model); err != nil {
+		return "", newUsageError(err)
+	}
+
+	if interactive {
+		fmt.Fprintf(out, "Save %s as the model of session %s? [y/N]: ", model, sess.GetShortID())
+		var response string
+		fmt.Fscanln(in, &response)
+		if response == "y" || response == "Y" {
+			sess.Model = model
+			if err := session.SaveSession(sess); err != nil {
+				return "", fmt.Errorf("saving session: %w", err)
+			}
+			fmt.Fprintf(out, "Session model updated.\n")
+		}
 	}
-	fmt.Fprintf(os.Stderr, "Type '/help' for commands, '/exit' or 'Ctrl+D' to quit\n")
-	fmt.Fprintf(os.Stderr, "===================================\n\n")
+	return model, nil
+}
 
-	// Create readline instance with history
+// runInteractiveMode starts an interactive chat session.
+// With autoSummarize, the conversation moves to a summarized session when the context window is exceeded.
+// Responses longer than maxLines lines are shown maxLines at a time with /more (0 = no limit).
+// providerFor creates the provider for a session switched to with /load.
+// samplingFlags are the sampling parameters given on the command line, restored after /retry with a temperature.
+// With showUsage, the token usage of each response and the running total of the session are printed.
+// After a turn that leaves the session with more than summarizeThreshold messages (0 = never),
+// the conversation moves to a summarized session.
+func runInteractiveMode(sess *session.Session, llmProvider llmc.Provider, providerFor func(*session.Session) (llmc.Provider, error), cfg *config.Config, samplingFlags llmc.SamplingParams, autoSummarize bool, pager *responsePager, showUsage bool, history *inputHistory, summarizeThreshold int) error {
+	printInteractiveHeader(os.Stderr, sess)
+	model := cfg.Model // Model the current session is continued with (changes with /load)
+
+	// Create readline instance; input is added to the history file as it is entered
+	userPrompt := cfg.RoleLabel("user") + "> "
 	rl, err := readline.NewEx(&readline.Config{
-		Prompt:          "You> ",
-		HistoryFile:     getHistoryFilePath(),
-		InterruptPrompt: "^C",
-		EOFPrompt:       "exit",
-		Stderr:          os.Stderr,
+		Prompt:                 userPrompt,
+		HistoryLimit:           cfg.HistorySize,
+		DisableAutoSaveHistory: true,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              "exit",
+		Stderr:                 os.Stderr,
 	})
 	if err != nil {
 		return fmt.Errorf("creating readline instance: %w", err)
 	}
 	defer rl.Close()
 
-	for {
-		// Read input (with backslash continuation support)
-		var inputLines []string
-		rl.SetPrompt("You> ")
-		for {
-			line, err := rl.Readline()
-			if err != nil {
-				if err == readline.ErrInterrupt {
-					if len(line) == 0 && len(inputLines) == 0 {
-						fmt.Fprintln(os.Stderr, "\nGoodbye!")
-						return nil
-					}
-					// Cancel current input
-					inputLines = nil
-					rl.SetPrompt("You> ")
-					break
-				} else if err == io.EOF {
-					fmt.Fprintln(os.Stderr, "\nGoodbye!")
-					return nil
-				}
-				return fmt.Errorf("input error: %w", err)
-			}
+	// Recall the input of earlier runs, and keep the history file at history_size entries
+	entries, err := history.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not read history file: %v\n", err)
+	}
+	for _, entry := range entries {
+		rl.SaveHistory(entry)
+	}
+	defer func() {
+		if err := history.trim(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not trim history file: %v\n", err)
+		}
+	}()
 
-			if strings.HasSuffix(line, `\`) {
-				// Backslash continuation: strip trailing backslash and continue
-				inputLines = append(inputLines, strings.TrimSuffix(line, `\`))
-				rl.SetPrompt("...> ")
-				continue
+	var sessionUsage llmc.Usage // Tokens used in the current session since it was started or loaded
+	for {
+		input, err := readTurnInput(rl, userPrompt, func(line string) {
+			rl.SaveHistory(line)
+			if err := history.append(line); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not save history: %v\n", err)
 			}
-			inputLines = append(inputLines, line)
-			break
+		}, os.Stderr)
+		if err == io.EOF {
+			fmt.Fprintln(os.Stderr, "\nGoodbye!")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("input error: %w", err)
 		}
 
-		if len(inputLines) == 0 {
+		// Skip empty input
+		if input == "" {
 			continue
 		}
 
-		input := strings.TrimSpace(strings.Join(inputLines, "\n"))
+		// Switch to another session; this replaces the session and provider used by the loop
+		if fields := strings.Fields(input); strings.ToLower(fields[0]) == "/load" {
+			if len(fields) != 2 {
+				fmt.Fprintln(os.Stderr, "Usage: /load <id>")
+				continue
+			}
+			loaded, loadedProvider, err := loadInteractiveSession(sess, fields[1], providerFor)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			sess, llmProvider = loaded, loadedProvider
+			model = sessionModel(sess, cfg.Model)
+			sessionUsage = llmc.Usage{}
+			pager.pending = nil
+			printInteractiveHeader(os.Stderr, sess)
+			continue
+		}
 
-		// Skip empty input
-		if input == "" {
+		// Regenerate the last response, optionally at another temperature (/retry 0.9);
+		// this needs the provider, unlike the other special commands
+		if fields := strings.Fields(input); strings.ToLower(fields[0]) == "/retry" || strings.ToLower(fields[0]) == "/regenerate" {
+			temperature, err := parseRetryTemperature(fields[1:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			done := make(chan bool)
+			go showSpinner(done)
+			ctx, stop := interruptContext()
+			response, err := retryWithTemperature(ctx, sess, llmProvider, model, resolveSampling(cfg, sess, samplingFlags), temperature)
+			stop()
+			done <- true
+			close(done)
+
+			if errors.Is(err, context.Canceled) {
+				fmt.Fprintln(os.Stderr, "\nRequest cancelled.")
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			if err := session.SaveSession(sess); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save session: %v\n", err)
+			}
+			pager.show(os.Stdout, cfg.RoleLabel("assistant"), response)
+			if showUsage {
+				writeSessionUsage(os.Stderr, llmc.LastUsage(llmProvider), &sessionUsage)
+			}
 			continue
 		}
 
 		// Handle special commands
 		if strings.HasPrefix(input, "/") {
-			if handleSpecialCommand(input, sess) {
+			if handleSpecialCommand(input, sess, cfg, pager) {
 				// Continue loop if command was handled
 				continue
 			}
@@ -737,52 +2231,288 @@ func runInteractiveMode(sess *session.Session, llmProvider llmc.Provider) error
 			break
 		}
 
-		// Add user message to session
-		sess.AddMessage("user", input)
-
-		// Get conversation history (excluding the just-added message)
-		historyMessages := sess.Messages[:len(sess.Messages)-1]
+		// Apply session default arguments to placeholders in the input
+		input = promptpkg.ApplyArgs(input, sess.DefaultArgs)
 
 		// Start spinner
 		done := make(chan bool)
 		go showSpinner(done)
 
-		// Send message with history
-		response, err := llmProvider.ChatWithHistory(sess.SystemPrompt, historyMessages, input)
+		// Send message with history; Ctrl+C cancels this turn but keeps the session open
+		ctx, stop := interruptContext()
+		parentSess := sess
+		response, activeSess, err := chatWithAutoSummarize(ctx, llmProvider, sess, input, autoSummarize)
+		stop()
 
 		// Stop spinner
 		done <- true
 		close(done)
 
+		// Continue in the summarized session if the context window was exceeded
+		if activeSess != parentSess {
+			sess = activeSess
+			sessionUsage = llmc.Usage{}
+			fmt.Fprintf(os.Stderr, "Continuing in summarized session %s (parent: %s)\n", sess.GetShortID(), parentSess.GetShortID())
+		}
+
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "\nRequest cancelled.")
+			continue
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			// Remove the failed message from history
-			sess.Messages = sess.Messages[:len(sess.Messages)-1]
 			continue
 		}
 
-		// Add assistant response
-		sess.AddMessage("assistant", response)
+		// Add the turn to the session
+		addTurn(sess, input, response, llmProvider, model)
 
 		// Save session after each turn
 		if err := session.SaveSession(sess); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to save session: %v\n", err)
 		}
 
-		// Print response
-		fmt.Printf("\nAssistant> %s\n\n", response)
+		// Print response (the full response has been saved above, even if it is paged)
+		pager.show(os.Stdout, cfg.RoleLabel("assistant"), response)
+		if showUsage {
+			writeSessionUsage(os.Stderr, llmc.LastUsage(llmProvider), &sessionUsage)
+		}
+
+		// Move to a summarized session once the session grows past the message threshold
+		ctx, stop = interruptContext()
+		summarized, err := summarizeAtThreshold(ctx, sess, llmProvider, summarizeThreshold, os.Stderr)
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: auto-summarize failed: %v\n", err)
+		} else if summarized != sess {
+			sess = summarized
+			sessionUsage = llmc.Usage{}
+		}
 	}
 
 	return nil
 }
 
-// getHistoryFilePath returns the path to the readline history file
-func getHistoryFilePath() string {
-	homeDir, err := os.UserHomeDir()
+// lineReader reads the lines typed in interactive mode (a *readline.Instance)
+type lineReader interface {
+	Readline() (string, error)
+	SetPrompt(prompt string)
+}
+
+// multiLineTerminator ends the input started with /multi
+const multiLineTerminator = "."
+
+// readTurnInput reads the message of one turn in interactive mode. A line ending with a
+// backslash continues on the next line, and /multi reads lines until one with only "."
+// (e.g. for pasted text). record is called with each non-empty line for the history.
+// Ctrl+C cancels the input ("" is returned), or exits on an empty prompt (io.EOF).
+// At the end of input, the lines entered so far are returned rather than dropped;
+// io.EOF is returned once there are none.
+func readTurnInput(r lineReader, userPrompt string, record func(line string), w io.Writer) (string, error) {
+	var lines []string
+	multi := false
+	r.SetPrompt(userPrompt)
+	for {
+		line, err := r.Readline()
+		if err == readline.ErrInterrupt {
+			if len(line) == 0 && len(lines) == 0 && !multi {
+				return "", io.EOF
+			}
+			// Cancel current input
+			return "", nil
+		}
+		if err == io.EOF {
+			if len(lines) == 0 {
+				return "", io.EOF
+			}
+			fmt.Fprintf(w, "\nEnd of input, sending the %d line(s) entered so far\n", len(lines))
+			return strings.TrimSpace(strings.Join(lines, "\n")), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if multi && strings.TrimSpace(line) == multiLineTerminator {
+			return strings.TrimSpace(strings.Join(lines, "\n")), nil
+		}
+		if strings.TrimSpace(line) != "" {
+			record(line)
+		}
+
+		if multi {
+			lines = append(lines, line)
+			continue
+		}
+		if len(lines) == 0 && strings.EqualFold(strings.TrimSpace(line), "/multi") {
+			multi = true
+			fmt.Fprintf(w, "Multi-line input: end with a line containing only %q\n", multiLineTerminator)
+			r.SetPrompt("...> ")
+			continue
+		}
+		if strings.HasSuffix(line, `\`) {
+			// Backslash continuation: strip trailing backslash and continue
+			lines = append(lines, strings.TrimSuffix(line, `\`))
+			r.SetPrompt("...> ")
+			continue
+		}
+		lines = append(lines, line)
+		return strings.TrimSpace(strings.Join(lines, "\n")), nil
+	}
+}
+
+// retryLastResponse sends the last user message of sess again with the history before it
+// and replaces the last response with the new one, attributed to model (the model sess is
+// continued with) unless a fallback model answered. The session is not saved, and it is
+// left unchanged if the request fails or the session does not end with an exchange.
+func retryLastResponse(ctx context.Context, sess *session.Session, llmProvider llmc.Provider, model string) (string, error) {
+	n := len(sess.Messages)
+	if n < 2 || sess.Messages[n-1].Role != "assistant" || sess.Messages[n-2].Role != "user" {
+		return "", fmt.Errorf("no previous exchange to retry")
+	}
+
+	response, err := llmProvider.ChatWithHistoryContext(ctx, sess.SystemPrompt, sess.Messages[:n-2], sess.Messages[n-2].Content)
+	if err != nil {
+		return "", err
+	}
+
+	sess.Messages = sess.Messages[:n-1]
+	sess.AddAssistantMessage(response, llmc.ResponseModel(llmProvider, model))
+	return response, nil
+}
+
+// parseRetryTemperature parses the optional temperature argument of /retry (nil if not given)
+func parseRetryTemperature(args []string) (*float64, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	if len(args) > 1 {
+		return nil, fmt.Errorf("usage: /retry [temperature]")
+	}
+	temperature, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid temperature '%s'", args[0])
+	}
+	if err := (llmc.SamplingParams{Temperature: &temperature}).Validate(); err != nil {
+		return nil, err
+	}
+	return &temperature, nil
+}
+
+// retryWithTemperature is like retryLastResponse but sends the request at temperature
+// when it is not nil. The sampling parameters of llmProvider are reset to sampling
+// afterwards, so the session keeps its settings for later turns.
+func retryWithTemperature(ctx context.Context, sess *session.Session, llmProvider llmc.Provider, model string, sampling llmc.SamplingParams, temperature *float64) (string, error) {
+	if temperature == nil {
+		return retryLastResponse(ctx, sess, llmProvider, model)
+	}
+	llmProvider.SetSampling(sampling.Merge(llmc.SamplingParams{Temperature: temperature}))
+	defer llmProvider.SetSampling(sampling)
+	return retryLastResponse(ctx, sess, llmProvider, model)
+}
+
+// summarizeAtThreshold summarizes sess into a new saved session when it has more than
+// threshold messages (0 = never) and returns the session to continue in
+func summarizeAtThreshold(ctx context.Context, sess *session.Session, llmProvider llmc.Provider, threshold int, w io.Writer) (*session.Session, error) {
+	if threshold <= 0 || sess.MessageCount() <= threshold {
+		return sess, nil
+	}
+
+	fmt.Fprintf(w, "Session %s has %d messages (threshold: %d), summarizing into a new session...\n", sess.GetShortID(), sess.MessageCount(), threshold)
+	summarized, err := summarizeSession(ctx, sess, llmProvider)
+	if err != nil {
+		return sess, err
+	}
+	if err := session.SaveSession(summarized); err != nil {
+		return sess, fmt.Errorf("saving summarized session: %w", err)
+	}
+	fmt.Fprintf(w, "Continuing in summarized session %s (parent: %s)\n\n", summarized.GetShortID(), sess.GetShortID())
+	return summarized, nil
+}
+
+// writeSessionUsage adds usage (nil if not reported) to the running total of the
+// session and writes both
+func writeSessionUsage(w io.Writer, usage *llmc.Usage, total *llmc.Usage) {
+	if usage == nil {
+		fmt.Fprintf(w, "Tokens: not reported by the provider; session total: %s\n\n", total)
+		return
+	}
+	total.Add(*usage)
+	fmt.Fprintf(w, "Tokens: %s; session total: %s\n\n", usage, total)
+}
+
+// printInteractiveHeader writes the header shown when an interactive session starts or is loaded
+func printInteractiveHeader(w io.Writer, sess *session.Session) {
+	fmt.Fprintf(w, "\n=== Interactive Session [%s] ===\n", sess.GetShortID())
+	fmt.Fprintf(w, "Model: %s\n", sess.Model)
+	if sess.SystemPrompt != "" {
+		fmt.Fprintf(w, "System Prompt: %s\n", sess.SystemPrompt)
+	}
+	fmt.Fprintf(w, "Type '/help' for commands, '/exit' or 'Ctrl+D' to quit\n")
+	fmt.Fprintf(w, "===================================\n\n")
+}
+
+// loadInteractiveSession saves the current session and loads the session matching id
+// (a prefix or "latest"), returning it with a provider for its model created by providerFor
+func loadInteractiveSession(current *session.Session, id string, providerFor func(*session.Session) (llmc.Provider, error)) (*session.Session, llmc.Provider, error) {
+	loaded, err := session.FindSessionByPrefix(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if loaded.ID == current.ID {
+		return nil, nil, fmt.Errorf("session %s is already loaded", current.GetShortID())
+	}
+
+	if err := session.SaveSession(current); err != nil {
+		return nil, nil, fmt.Errorf("saving session: %w", err)
+	}
+
+	provider, err := providerFor(loaded)
 	if err != nil {
-		return ""
+		return nil, nil, fmt.Errorf("creating provider: %w", err)
+	}
+	return loaded, provider, nil
+}
+
+// responsePager shows long responses in interactive mode maxLines lines at a time.
+// The lines not shown yet are kept for /more.
+type responsePager struct {
+	maxLines int      // Lines shown at once (0 = no limit)
+	render   bool     // Style Markdown with ANSI escape sequences (see llmc.RenderMarkdown)
+	pending  []string // Lines not shown yet
+}
+
+// show writes a response with its label, up to maxLines lines
+func (p *responsePager) show(w io.Writer, label, response string) {
+	if p.render {
+		response = llmc.RenderMarkdown(response)
+	}
+	p.pending = strings.Split(response, "\n")
+	fmt.Fprintf(w, "\n%s> ", label)
+	p.writeNext(w)
+}
+
+// more writes the next maxLines lines of the last response.
+// It returns false if all of the response has been shown.
+func (p *responsePager) more(w io.Writer) bool {
+	if len(p.pending) == 0 {
+		return false
+	}
+	fmt.Fprintln(w)
+	p.writeNext(w)
+	return true
+}
+
+// writeNext writes the next maxLines pending lines followed by an indicator of the lines left
+func (p *responsePager) writeNext(w io.Writer) {
+	lines := p.pending
+	if p.maxLines > 0 && len(lines) > p.maxLines {
+		lines = lines[:p.maxLines]
 	}
-	return homeDir + "/.config/llmc/history"
+	p.pending = p.pending[len(lines):]
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+	if len(p.pending) > 0 {
+		fmt.Fprintf(w, "... (%d more line(s), type /more to show them)\n", len(p.pending))
+	}
+	fmt.Fprintln(w)
 }
 
 // showSpinner displays a spinner animation while waiting for response
@@ -805,8 +2535,11 @@ func showSpinner(done chan bool) {
 
 // handleSpecialCommand processes special commands in interactive mode
 // Returns true to continue the loop, false to exit
-func handleSpecialCommand(command string, sess *session.Session) bool {
-	command = strings.ToLower(strings.TrimSpace(command))
+func handleSpecialCommand(command string, sess *session.Session, cfg *config.Config, pager *responsePager) bool {
+	// Command names are case-insensitive; arguments (e.g., file paths) are kept as typed
+	fields := strings.Fields(command)
+	command = strings.ToLower(fields[0])
+	cmdArgs := fields[1:]
 
 	switch command {
 	case "/help", "/h":
@@ -814,24 +2547,21 @@ func handleSpecialCommand(command string, sess *session.Session) bool {
 		fmt.Fprintln(os.Stderr, "  /help, /h     - Show this help message")
 		fmt.Fprintln(os.Stderr, "  /info, /i     - Show session information")
 		fmt.Fprintln(os.Stderr, "  /clear, /c    - Clear screen (Unix/Linux only)")
+		fmt.Fprintln(os.Stderr, "  /more, /m     - Show more of a long response (see max_display_lines)")
+		fmt.Fprintln(os.Stderr, "  /retry [temperature]")
+		fmt.Fprintln(os.Stderr, "                - Regenerate the last response (at another temperature for this response only)")
+		fmt.Fprintln(os.Stderr, "  /multi        - Enter a multi-line message, ended by a line with only '.'")
+		fmt.Fprintln(os.Stderr, "  /export [format] [path]")
+		fmt.Fprintln(os.Stderr, "                - Export the session (markdown, json or html; default: markdown to <id>.md)")
+		fmt.Fprintln(os.Stderr, "  /load <id>    - Save this session and switch to another (ID prefix or 'latest')")
 		fmt.Fprintln(os.Stderr, "  /exit, /quit  - Exit interactive mode")
 		fmt.Fprintln(os.Stderr, "  Ctrl+D        - Exit interactive mode")
 		fmt.Fprintln(os.Stderr, "")
 		return true
 
 	case "/info", "/i":
-		fmt.Fprintln(os.Stderr, "\nSession Information:")
-		fmt.Fprintf(os.Stderr, "  ID: %s\n", sess.GetShortID())
-		fmt.Fprintf(os.Stderr, "  Full ID: %s\n", sess.ID)
-		if sess.Name != "" {
-			fmt.Fprintf(os.Stderr, "  Name: %s\n", sess.Name)
-		}
-		fmt.Fprintf(os.Stderr, "  Model: %s\n", sess.Model)
-		fmt.Fprintf(os.Stderr, "  Messages: %d\n", sess.MessageCount())
-		fmt.Fprintf(os.Stderr, "  Created: %s\n", sess.CreatedAt.Format("2006-01-02 15:04:05"))
-		if sess.TemplateName != "" {
-			fmt.Fprintf(os.Stderr, "  Template: %s\n", sess.TemplateName)
-		}
+		fmt.Fprintln(os.Stderr, "")
+		writeSessionInfo(os.Stderr, sess, cfg)
 		fmt.Fprintln(os.Stderr, "")
 		return true
 
@@ -840,6 +2570,21 @@ func handleSpecialCommand(command string, sess *session.Session) bool {
 		fmt.Print("\033[H\033[2J")
 		return true
 
+	case "/more", "/m":
+		if !pager.more(os.Stdout) {
+			fmt.Fprintln(os.Stderr, "Nothing more to show")
+		}
+		return true
+
+	case "/export":
+		path, err := exportSession(sess, cfg, cmdArgs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return true
+		}
+		fmt.Fprintf(os.Stderr, "Exported to: %s\n", path)
+		return true
+
 	case "/exit", "/quit", "/q":
 		fmt.Fprintln(os.Stderr, "Goodbye!")
 		return false
@@ -850,16 +2595,229 @@ func handleSpecialCommand(command string, sess *session.Session) bool {
 	}
 }
 
+// sessionsExportCmd represents the sessions export command
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export [id]",
+	Short: "Export a session or all sessions to readable files",
+	Long: `Export a session as Markdown, JSON or HTML.
+
+The ID can be a short ID (minimum 4 characters), full UUID, or "latest" for the most recent session.
+The session is written to --output (default: <short-id>.<ext>).
+
+With --all, every session is exported for a readable full-history backup:
+  - into the directory --output (default: llmc-sessions), one file per session named
+    <short-id>.<ext>, with a manifest.json listing the exported sessions, or
+  - with --combined, into the single Markdown file --output (default: llmc-sessions.md),
+    starting with a table of contents.
+
+Examples:
+  llmc sessions export latest
+  llmc sessions export 550e8400 --format html -o chat.html
+  llmc sessions export --all --format json -o backup/
+  llmc sessions export --all --combined`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		combined, _ := cmd.Flags().GetBool("combined")
+		formatName, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		format, err := export.ParseFormat(formatName)
+		if err != nil {
+			return newUsageError(err)
+		}
+		if all && len(args) > 0 {
+			return newUsageError(fmt.Errorf("--all cannot be used with a session ID"))
+		}
+		if !all && len(args) == 0 {
+			return newUsageError(fmt.Errorf("a session ID or --all is required"))
+		}
+		if combined && !all {
+			return newUsageError(fmt.Errorf("--combined requires --all"))
+		}
+		if combined && format != export.FormatMarkdown {
+			return newUsageError(fmt.Errorf("--combined supports only the markdown format"))
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+
+		if !all {
+			sess, err := session.FindSessionByPrefix(args[0])
+			if err != nil {
+				return fmt.Errorf("finding session: %w", err)
+			}
+			path, err := exportSession(sess, cfg, []string{string(format), output})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Exported session %s to: %s\n", sess.GetShortID(), path)
+			return nil
+		}
+
+		sessions, err := session.ListSessions()
+		if err != nil {
+			return fmt.Errorf("listing sessions: %w", err)
+		}
+		if len(sessions) == 0 {
+			fmt.Println("No sessions to export.")
+			return nil
+		}
+
+		if combined {
+			if output == "" {
+				output = "llmc-sessions.md"
+			}
+			var buf bytes.Buffer
+			if err := export.WriteCombinedMarkdown(&buf, sessions, cfg); err != nil {
+				return fmt.Errorf("exporting sessions: %w", err)
+			}
+			if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("writing export file: %w", err)
+			}
+			fmt.Printf("Exported %d session(s) to: %s\n", len(sessions), output)
+			return nil
+		}
+
+		if output == "" {
+			output = "llmc-sessions"
+		}
+		if _, err := export.WriteAll(output, sessions, format, cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Exported %d session(s) to: %s (see %s)\n", len(sessions), output, export.ManifestFileName)
+		return nil
+	},
+}
+
+// exportSession writes sess to a file according to the /export arguments ([format] [path])
+// and returns the path written. A single argument with a file extension is taken as the path,
+// and the format is then inferred from the extension.
+func exportSession(sess *session.Session, cfg *config.Config, args []string) (string, error) {
+	if len(args) > 2 {
+		return "", fmt.Errorf("usage: /export [format] [path]")
+	}
+
+	format := export.FormatMarkdown
+	path := ""
+	switch len(args) {
+	case 1:
+		if f, err := export.ParseFormat(args[0]); err == nil {
+			format = f
+		} else if ext := filepath.Ext(args[0]); ext != "" {
+			path = args[0]
+			if f, err := export.ParseFormat(ext); err == nil {
+				format = f
+			}
+		} else {
+			return "", err
+		}
+	case 2:
+		f, err := export.ParseFormat(args[0])
+		if err != nil {
+			return "", err
+		}
+		format = f
+		path = args[1]
+	}
+	if path == "" {
+		path = export.DefaultFileName(sess, format)
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, sess, format, cfg); err != nil {
+		return "", fmt.Errorf("exporting session: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("writing export file: %w", err)
+	}
+	return path, nil
+}
+
 func init() {
 	rootCmd.AddCommand(sessionsCmd)
 	sessionsCmd.AddCommand(sessionsListCmd)
 	sessionsCmd.AddCommand(sessionsShowCmd)
+	sessionsCmd.AddCommand(sessionsInfoCmd)
+	sessionsCmd.AddCommand(sessionsCleanCmd)
 	sessionsCmd.AddCommand(sessionsDeleteCmd)
+	sessionsCmd.AddCommand(sessionsGCCmd)
 	sessionsCmd.AddCommand(sessionsRenameCmd)
+	sessionsCmd.AddCommand(sessionsTagCmd)
+	sessionsCmd.AddCommand(sessionsUntagCmd)
+	sessionsCmd.AddCommand(sessionsMoveCmd)
+	sessionsCmd.AddCommand(sessionsRepairCmd)
+	sessionsCmd.AddCommand(sessionsSedCmd)
 	sessionsCmd.AddCommand(sessionsSummarizeCmd)
+	sessionsCmd.AddCommand(sessionsBranchCmd)
+	sessionsCmd.AddCommand(sessionsEditCmd)
 	sessionsCmd.AddCommand(sessionsStartCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsCmd.AddCommand(sessionsStatsCmd)
+
+	// sessionsListCmd flags
+	sessionsListCmd.Flags().Bool("json", false, "Output sessions as JSON")
+	sessionsListCmd.Flags().Bool("wide", false, "Do not truncate columns to fit the terminal width")
+	sessionsListCmd.Flags().Int("limit", 0, "Show only the N most recently updated sessions (0 = all)")
+	sessionsListCmd.Flags().StringArray("tag", nil, "Show only sessions with this tag (repeatable; sessions must have all of them)")
+	sessionsListCmd.Flags().Bool("show-errors", false, "Print the session files that could not be read and their errors")
+
+	// sessionsShowCmd flags
+	sessionsShowCmd.Flags().Int("first", 0, "Show only the first N messages")
+	sessionsShowCmd.Flags().Int("last", 0, "Show only the last N messages")
+	sessionsShowCmd.Flags().Bool("metadata-only", false, "Show only the session header without the message history")
+	sessionsShowCmd.Flags().Bool("json", false, "Output the session as JSON")
+	sessionsShowCmd.Flags().String("since", "", "Show only messages added after a time (duration such as 2h or 3d, or a date such as 2025-01-01)")
+	sessionsShowCmd.Flags().Int("raw-json-message", 0, "Print the raw JSON of message N as stored in the session file")
+	sessionsShowCmd.Flags().Bool("markdown", false, "Print the session as Markdown source (as exported) instead of the terminal view")
 
 	// sessionsDeleteCmd flags (for bulk deletion mode)
 	sessionsDeleteCmd.Flags().String("before", "", "Delete only sessions created before this date (format: YYYY-MM-DD, YYYY-MM, or YYYY)")
 	sessionsDeleteCmd.Flags().Bool("all", false, "Delete all sessions (overrides retention days setting)")
+
+	// sessionsGCCmd flags
+	sessionsGCCmd.Flags().Int("max-count", 0, "Number of most recently updated sessions to keep (default: max_sessions)")
+	sessionsGCCmd.Flags().Bool("dry-run", false, "Show the sessions that would be deleted without deleting them")
+
+	// sessionsSedCmd flags
+	sessionsSedCmd.Flags().String("find", "", "Text to find (a regular expression with --regex)")
+	sessionsSedCmd.Flags().String("replace", "", "Replacement text")
+	sessionsSedCmd.Flags().Bool("regex", false, "Treat --find as a regular expression")
+
+	// sessionsBranchCmd flags
+	sessionsBranchCmd.Flags().Int("at", 0, "Number of the last message to copy into the new session (as shown by 'sessions show')")
+
+	// sessionsEditCmd flags
+	sessionsEditCmd.Flags().String("delete", "", "Message number or range (e.g. 3-5) to delete (as shown by 'sessions show')")
+
+	// sessionsSummarizeCmd flags
+	sessionsSummarizeCmd.Flags().String("summary-to", "", "Append the summary to this existing session instead of creating a new one")
+
+	// sessionsStartCmd flags
+	addSamplingFlags(sessionsStartCmd)
+	sessionsStartCmd.Flags().String("model", "", "Model for a new session, or for a session whose stored model is invalid (provider:model)")
+	sessionsStartCmd.Flags().Bool("no-fallback", false, "Disable falling back to fallback_models when the model is unavailable")
+	sessionsStartCmd.Flags().Bool("auto-summarize", false, "Summarize the session into a new one and retry when the context window is exceeded")
+	sessionsStartCmd.Flags().Bool("no-auto-summarize", false, "Keep the full history instead of summarizing the session into a new one past session_message_threshold messages")
+	sessionsStartCmd.Flags().Bool("usage", false, "Print the token usage of each response and the running total of the session")
+	sessionsStartCmd.Flags().String("history-file", "", "File to persist input history to (default: ~/.config/llmc/history)")
+	sessionsStartCmd.Flags().Bool("no-history", false, "Do not read or save the input history file (e.g. for sensitive sessions)")
+	sessionsStartCmd.Flags().Bool("no-pager", false, "Show long responses in full instead of max_display_lines lines at a time")
+	sessionsStartCmd.Flags().Bool("no-render", false, "Print responses as raw Markdown instead of styling them for the terminal")
+
+	// sessionsExportCmd flags
+	sessionsExportCmd.Flags().String("format", string(export.FormatMarkdown), "Export format: markdown, json or html")
+	sessionsExportCmd.Flags().StringP("output", "o", "", "Output file, or directory with --all (default: <short-id>.<ext>, llmc-sessions or llmc-sessions.md)")
+	sessionsExportCmd.Flags().Bool("all", false, "Export every session (one file per session and a manifest.json)")
+	sessionsExportCmd.Flags().Bool("combined", false, "With --all, write a single Markdown file with a table of contents")
+
+	// sessionsStatsCmd flags
+	sessionsStatsCmd.Flags().String("by", statsByModel, "Group by model, day or template")
+	sessionsStatsCmd.Flags().Bool("json", false, "Output the groups as JSON")
+
+	// sessionsMoveCmd flags
+	sessionsMoveCmd.Flags().String("parent", "", "ID of the new parent session")
+	sessionsMoveCmd.Flags().Bool("detach", false, "Remove the parent so that the session becomes a root session")
 }
@@ -0,0 +1,1686 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/config"
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+func TestWriteSessionsJSON(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	sessions := []session.Session{
+		{
+			ID:        "550e8400-e29b-41d4-a716-446655440000",
+			ParentID:  "9a3f92d1-0000-0000-0000-000000000000",
+			Name:      "work",
+			Model:     "openai:gpt-4",
+			CreatedAt: now,
+			UpdatedAt: now,
+			Tags:      []string{"project"},
+		},
+		{
+			ID:        "9a3f92d1-0000-0000-0000-000000000000",
+			Model:     "gemini:gemini-2.0-flash",
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		{
+			ID:        "abcd1234-0000-0000-0000-000000000000",
+			Model:     "anthropic:claude-3-5-sonnet-20241022",
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantCount int
+	}{
+		{name: "no limit", limit: 0, wantCount: 3},
+		{name: "limit 2", limit: 2, wantCount: 2},
+		{name: "limit larger than sessions", limit: 10, wantCount: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeSessionsJSON(&buf, limitSessions(sessions, tt.limit), false); err != nil {
+				t.Fatalf("writeSessionsJSON() error = %v", err)
+			}
+
+			var items []map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
+				t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+			}
+			if len(items) != tt.wantCount {
+				t.Fatalf("got %d items, want %d", len(items), tt.wantCount)
+			}
+
+			wantFields := []string{"id", "short_id", "name", "model", "provider", "created_at", "updated_at", "message_count", "parent_id", "tags"}
+			for _, item := range items {
+				for _, field := range wantFields {
+					if _, ok := item[field]; !ok {
+						t.Errorf("item %v is missing field %q", item["id"], field)
+					}
+				}
+			}
+
+			first := items[0]
+			if first["short_id"] != "550e8400" {
+				t.Errorf("short_id = %v, want 550e8400", first["short_id"])
+			}
+			if first["provider"] != "openai" {
+				t.Errorf("provider = %v, want openai", first["provider"])
+			}
+			if first["parent_id"] != sessions[0].ParentID {
+				t.Errorf("parent_id = %v, want %v", first["parent_id"], sessions[0].ParentID)
+			}
+		})
+	}
+}
+
+func TestWriteMessageHistory(t *testing.T) {
+	sess := session.NewSession("openai:gpt-4")
+	for i := 1; i <= 6; i++ {
+		sess.AddMessage("user", fmt.Sprintf("message %d", i))
+	}
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tests := []struct {
+		name        string
+		first       int
+		last        int
+		wantLabels  []string
+		wantElision string
+	}{
+		{
+			name:       "all messages",
+			wantLabels: []string{"[1]", "[2]", "[3]", "[4]", "[5]", "[6]"},
+		},
+		{
+			name:       "head only",
+			first:      2,
+			wantLabels: []string{"[1]", "[2]"},
+		},
+		{
+			name:       "tail only",
+			last:       2,
+			wantLabels: []string{"[5]", "[6]"},
+		},
+		{
+			name:        "head and tail with elision",
+			first:       2,
+			last:        1,
+			wantLabels:  []string{"[1]", "[2]", "[6]"},
+			wantElision: "... (3 messages omitted) ...",
+		},
+		{
+			name:       "overlapping head and tail",
+			first:      4,
+			last:       4,
+			wantLabels: []string{"[1]", "[2]", "[3]", "[4]", "[5]", "[6]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeMessageHistory(&buf, sess, cfg, tt.first, tt.last, time.Time{})
+			out := buf.String()
+
+			var gotLabels []string
+			for _, line := range strings.Split(out, "\n") {
+				if strings.HasPrefix(line, "[") {
+					gotLabels = append(gotLabels, strings.Fields(line)[0])
+				}
+			}
+			if strings.Join(gotLabels, ",") != strings.Join(tt.wantLabels, ",") {
+				t.Errorf("labels = %v, want %v", gotLabels, tt.wantLabels)
+			}
+
+			hasElision := strings.Contains(out, "omitted")
+			if tt.wantElision == "" && hasElision {
+				t.Errorf("unexpected elision marker in output:\n%s", out)
+			}
+			if tt.wantElision != "" && !strings.Contains(out, tt.wantElision) {
+				t.Errorf("output missing %q:\n%s", tt.wantElision, out)
+			}
+		})
+	}
+}
+
+func TestWriteMessageHistoryModels(t *testing.T) {
+	sess := session.NewSession("openai:gpt-4o")
+	sess.AddMessage("user", "Hello")
+	sess.AddAssistantMessage("Hi from the session model", "openai:gpt-4o")
+	sess.AddMessage("user", "And now?")
+	sess.AddAssistantMessage("Hi from the fallback model", "anthropic:claude-3-5-sonnet")
+	sess.AddMessage("assistant", "Hi from an older version")
+	cfg := &config.Config{Timezone: "UTC"}
+
+	var buf bytes.Buffer
+	writeMessageHistory(&buf, sess, cfg, 0, 0, time.Time{})
+
+	var labels []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "[") {
+			labels = append(labels, line[:strings.LastIndex(line, " (")])
+		}
+	}
+	want := []string{
+		"[1] You",
+		"[2] Assistant",
+		"[3] You",
+		"[4] Assistant (anthropic:claude-3-5-sonnet)",
+		"[5] Assistant",
+	}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %q, want %q", labels, want)
+	}
+}
+
+func TestWriteMessageHistoryLabels(t *testing.T) {
+	sess := session.NewSession("openai:gpt-4")
+	sess.AddMessage("user", "Hello")
+	sess.AddMessage("assistant", "Hi there")
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want []string
+	}{
+		{
+			name: "default labels",
+			cfg:  &config.Config{Timezone: "UTC"},
+			want: []string{"[1] You (", "[2] Assistant ("},
+		},
+		{
+			name: "custom labels",
+			cfg:  &config.Config{Timezone: "UTC", UserLabel: "Alice", AssistantLabel: "Sherlock"},
+			want: []string{"[1] Alice (", "[2] Sherlock ("},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeMessageHistory(&buf, sess, tt.cfg, 0, 0, time.Time{})
+			out := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSampling(t *testing.T) {
+	cfgTemp, sessTemp, turnTemp := 1.0, 0.2, 0.8
+	cfgMax, sessMax := 4096, 512
+	cfg := &config.Config{Temperature: &cfgTemp, MaxTokens: &cfgMax}
+
+	created := session.NewSession("openai:gpt-4")
+	created.Sampling = &llmc.SamplingParams{Temperature: &sessTemp, MaxTokens: &sessMax}
+	legacy := session.NewSession("openai:gpt-4")
+
+	tests := []struct {
+		name          string
+		sess          *session.Session
+		flags         llmc.SamplingParams
+		wantTemp      float64
+		wantMaxTokens int
+	}{
+		{name: "no session uses config", sess: nil, wantTemp: 1.0, wantMaxTokens: 4096},
+		{name: "session uses stored parameters", sess: created, wantTemp: 0.2, wantMaxTokens: 512},
+		{name: "flags override for the turn", sess: created, flags: llmc.SamplingParams{Temperature: &turnTemp}, wantTemp: 0.8, wantMaxTokens: 512},
+		{name: "session without parameters uses config", sess: legacy, wantTemp: 1.0, wantMaxTokens: 4096},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveSampling(cfg, tt.sess, tt.flags)
+			if got.Temperature == nil || *got.Temperature != tt.wantTemp {
+				t.Errorf("temperature = %v, want %v", got.Temperature, tt.wantTemp)
+			}
+			if got.MaxTokens == nil || *got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("max_tokens = %v, want %v", got.MaxTokens, tt.wantMaxTokens)
+			}
+		})
+	}
+
+	// The per-turn override must not change the stored parameters
+	if *created.Sampling.Temperature != 0.2 {
+		t.Errorf("stored temperature = %v, want 0.2", *created.Sampling.Temperature)
+	}
+}
+
+func TestWriteSessionsTableMarksLatest(t *testing.T) {
+	latest := session.NewSession("openai:gpt-4")
+	older := session.NewSession("openai:gpt-4")
+	cfg := &config.Config{Timezone: "UTC"}
+
+	var buf bytes.Buffer
+	writeSessionsTable(&buf, []session.Session{*latest, *older}, cfg, 0, latest.ID)
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[2], latest.GetShortID()+" *") {
+		t.Errorf("latest row = %q, want it marked with *", lines[2])
+	}
+	if strings.Contains(lines[3], "*") {
+		t.Errorf("other row = %q, want it unmarked", lines[3])
+	}
+	if !strings.Contains(buf.String(), "* latest") {
+		t.Errorf("output = %q, want a legend for the marker", buf.String())
+	}
+
+	buf.Reset()
+	writeSessionsTable(&buf, []session.Session{*latest, *older}, cfg, 0, "")
+	if strings.Contains(buf.String(), "*") {
+		t.Errorf("output = %q, want no marker without a latest ID", buf.String())
+	}
+}
+
+func TestWriteSessionsTableTruncation(t *testing.T) {
+	sess := session.NewSession("openai:a-very-long-fine-tuned-model-name-for-testing-truncation")
+	sess.Name = "design review"
+	sess.AddMessage("user", "Please review the attached design document")
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tests := []struct {
+		name      string
+		width     int
+		wantModel string
+	}{
+		{name: "no limit", width: 0, wantModel: sess.Model},
+		{name: "narrow terminal", width: 80, wantModel: "openai:a-very-..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeSessionsTable(&buf, []session.Session{*sess}, cfg, tt.width, "")
+			lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+			}
+
+			fields := strings.Fields(lines[2])
+			if fields[1] != tt.wantModel {
+				t.Errorf("model = %q, want %q", fields[1], tt.wantModel)
+			}
+
+			// Columns stay aligned: the header and the row start the CREATED column at the same offset
+			headerCol := strings.Index(lines[0], "CREATED")
+			rowCol := strings.Index(lines[2], cfg.FormatTime(sess.CreatedAt))
+			if headerCol != rowCol {
+				t.Errorf("CREATED column at %d in header and %d in row", headerCol, rowCol)
+			}
+
+			if tt.width > 0 {
+				for _, line := range lines {
+					if n := len([]rune(line)); n > tt.width {
+						t.Errorf("line is %d characters, want at most %d: %q", n, tt.width, line)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "short", n: 10, want: "short"},
+		{s: "exactly10!", n: 10, want: "exactly10!"},
+		{s: "this is too long", n: 10, want: "this is..."},
+		{s: "abcdef", n: 2, want: "ab"},
+		{s: "日本語のテキスト", n: 6, want: "日本語..."},
+	}
+
+	for _, tt := range tests {
+		if got := truncateText(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestExportSession(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	sess := session.NewSession("openai:gpt-4")
+	sess.AddMessage("user", "What is Go?")
+	sess.AddMessage("assistant", "A programming language.")
+	cfg := &config.Config{Timezone: "UTC", AssistantLabel: "Gopher"}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPath string
+		want     string
+	}{
+		{name: "default", args: nil, wantPath: sess.GetShortID() + ".md", want: "## Gopher ("},
+		{name: "format only", args: []string{"json"}, wantPath: sess.GetShortID() + ".json", want: `"content": "What is Go?"`},
+		{name: "format and path", args: []string{"html", "chat.html"}, wantPath: "chat.html", want: "<strong>Gopher</strong>"},
+		{name: "path only", args: []string{"notes.md"}, wantPath: "notes.md", want: "A programming language."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := exportSession(sess, cfg, tt.args)
+			if err != nil {
+				t.Fatalf("exportSession() error = %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("exportSession() path = %q, want %q", path, tt.wantPath)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, path))
+			if err != nil {
+				t.Fatalf("reading export: %v", err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("export missing %q:\n%s", tt.want, data)
+			}
+		})
+	}
+
+	if _, err := exportSession(sess, cfg, []string{"pdf"}); err == nil {
+		t.Error("exportSession() with unknown format: error = nil, want error")
+	}
+}
+
+func TestCheckReparent(t *testing.T) {
+	// Tree: root -> child -> grandchild, and other (unrelated root)
+	root := session.NewSession("openai:gpt-4")
+	child := session.NewSession("openai:gpt-4")
+	child.ParentID = root.ID
+	grandchild := session.NewSession("openai:gpt-4")
+	grandchild.ParentID = child.ID
+	other := session.NewSession("openai:gpt-4")
+
+	sessions := map[string]*session.Session{}
+	for _, s := range []*session.Session{root, child, grandchild, other} {
+		sessions[s.ID] = s
+	}
+	findSession := func(id string) (*session.Session, error) {
+		if s, ok := sessions[id]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+
+	tests := []struct {
+		name    string
+		sess    *session.Session
+		parent  *session.Session
+		wantErr string
+	}{
+		{name: "valid move", sess: grandchild, parent: other},
+		{name: "move under an ancestor's sibling", sess: child, parent: other},
+		{name: "self parent", sess: child, parent: child, wantErr: "cannot be its own parent"},
+		{name: "cycle with child", sess: root, parent: child, wantErr: "would create a cycle"},
+		{name: "cycle with grandchild", sess: root, parent: grandchild, wantErr: "would create a cycle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReparent(tt.sess, tt.parent, findSession)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkReparent() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkReparent() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShowMetadataOnly(t *testing.T) {
+	cfg := &config.Config{TimeFormat: "rfc3339", Timezone: "UTC"}
+	sess := session.NewSession("openai:gpt-4")
+	sess.Name = "work"
+	sess.SystemPrompt = "Be brief."
+	sess.AddMessage("user", "secret question")
+	sess.AddMessage("assistant", "secret answer")
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		writeSessionHeader(&buf, sess, cfg)
+		got := buf.String()
+		for _, want := range []string{"Session: " + sess.ID, "Name: work", "Model: openai:gpt-4", "System Prompt: Be brief.", "Messages: 2"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("writeSessionHeader() output missing %q:\n%s", want, got)
+			}
+		}
+		if strings.Contains(got, "secret") {
+			t.Errorf("writeSessionHeader() printed message content:\n%s", got)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeSessionJSON(&buf, sess, true, false); err != nil {
+			t.Fatalf("writeSessionJSON() error = %v", err)
+		}
+		if strings.Contains(buf.String(), "secret") || strings.Contains(buf.String(), `"messages"`) {
+			t.Errorf("writeSessionJSON() printed messages:\n%s", buf.String())
+		}
+
+		var got map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("writeSessionJSON() output is not valid JSON: %v", err)
+		}
+		if got["id"] != sess.ID || got["message_count"] != float64(2) || got["system_prompt"] != "Be brief." {
+			t.Errorf("writeSessionJSON() = %v", got)
+		}
+	})
+
+	t.Run("json with messages", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeSessionJSON(&buf, sess, false, false); err != nil {
+			t.Fatalf("writeSessionJSON() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), "secret answer") {
+			t.Errorf("writeSessionJSON() output missing messages:\n%s", buf.String())
+		}
+	})
+}
+
+func TestWriteRawMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sess := session.NewSession("openai:gpt-4")
+	sess.AddMessage("user", "first question")
+	sess.AddMessage("assistant", "first answer")
+	if err := session.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	// Add a field that the Message type does not know to the second message
+	sessionDir, _ := session.GetSessionDir()
+	path := filepath.Join(sessionDir, sess.ID+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte(`"content": "first answer",`), []byte(`"content": "first answer", "finish_reason": "stop",`), 1)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("message by index", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeRawMessage(&buf, sess, 2, false); err != nil {
+			t.Fatalf("writeRawMessage() error = %v", err)
+		}
+
+		var got map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("writeRawMessage() output is not valid JSON: %v\n%s", err, buf.String())
+		}
+		want := map[string]interface{}{
+			"role":          "assistant",
+			"content":       "first answer",
+			"finish_reason": "stop",
+			"timestamp":     sess.Messages[1].Timestamp.Format(time.RFC3339Nano),
+		}
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("writeRawMessage() %s = %v, want %v", key, got[key], value)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("writeRawMessage() = %v, want fields %v", got, want)
+		}
+	})
+
+	t.Run("index out of range", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeRawMessage(&buf, sess, 3, false); err == nil {
+			t.Error("writeRawMessage() error = nil, want error for index 3 of 2 messages")
+		}
+	})
+}
+
+func TestWriteSessionFileErrors(t *testing.T) {
+	fileErrors := []session.FileError{{Path: "/sessions/broken.json", Err: fmt.Errorf("unexpected end of JSON input")}}
+
+	tests := []struct {
+		name       string
+		fileErrors []session.FileError
+		showErrors bool
+		want       []string
+		notWant    []string
+	}{
+		{name: "no errors", fileErrors: nil, notWant: []string{"session file"}},
+		{name: "count only", fileErrors: fileErrors, want: []string{"Warning: 1 session file(s) could not be read", "--show-errors"}, notWant: []string{"broken.json"}},
+		{name: "show errors", fileErrors: fileErrors, showErrors: true, want: []string{"/sessions/broken.json: unexpected end of JSON input", "llmc sessions repair"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeSessionFileErrors(&buf, tt.fileErrors, tt.showErrors)
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("writeSessionFileErrors() output missing %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("writeSessionFileErrors() output contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceInMessages(t *testing.T) {
+	messages := []llmc.Message{
+		{Role: "user", Content: "my key is sk-abc123 and sk-abc123 again"},
+		{Role: "assistant", Content: "Never share sk-abc123. Use sk-xyz789 instead."},
+		{Role: "user", Content: "thanks"},
+	}
+
+	tests := []struct {
+		name       string
+		find       string
+		replace    string
+		useRegex   bool
+		wantCounts []int
+		wantFirst  string
+		wantSecond string
+	}{
+		{
+			name:       "literal",
+			find:       "sk-abc123",
+			replace:    "[REDACTED]",
+			wantCounts: []int{2, 1, 0},
+			wantFirst:  "my key is [REDACTED] and [REDACTED] again",
+			wantSecond: "Never share [REDACTED]. Use sk-xyz789 instead.",
+		},
+		{
+			name:       "literal with regex characters",
+			find:       "sk-.*",
+			replace:    "x",
+			wantCounts: []int{0, 0, 0},
+			wantFirst:  messages[0].Content,
+			wantSecond: messages[1].Content,
+		},
+		{
+			name:       "regex",
+			find:       `sk-[a-z0-9]+`,
+			replace:    "[REDACTED]",
+			useRegex:   true,
+			wantCounts: []int{2, 2, 0},
+			wantFirst:  "my key is [REDACTED] and [REDACTED] again",
+			wantSecond: "Never share [REDACTED]. Use [REDACTED] instead.",
+		},
+		{
+			name:       "regex with group",
+			find:       `sk-([a-z]+)[0-9]+`,
+			replace:    "sk-${1}***",
+			useRegex:   true,
+			wantCounts: []int{2, 2, 0},
+			wantFirst:  "my key is sk-abc*** and sk-abc*** again",
+			wantSecond: "Never share sk-abc***. Use sk-xyz*** instead.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, counts, err := replaceInMessages(messages, tt.find, tt.replace, tt.useRegex)
+			if err != nil {
+				t.Fatalf("replaceInMessages() error = %v", err)
+			}
+			if fmt.Sprint(counts) != fmt.Sprint(tt.wantCounts) {
+				t.Errorf("replaceInMessages() counts = %v, want %v", counts, tt.wantCounts)
+			}
+			if got[0].Content != tt.wantFirst || got[1].Content != tt.wantSecond {
+				t.Errorf("replaceInMessages() contents = %q, %q, want %q, %q", got[0].Content, got[1].Content, tt.wantFirst, tt.wantSecond)
+			}
+			if got[2].Content != "thanks" || got[1].Role != "assistant" {
+				t.Errorf("replaceInMessages() changed unrelated message data: %+v", got)
+			}
+		})
+	}
+
+	// The original messages are not modified
+	if messages[0].Content != "my key is sk-abc123 and sk-abc123 again" {
+		t.Errorf("replaceInMessages() modified its input: %q", messages[0].Content)
+	}
+
+	if _, _, err := replaceInMessages(messages, "(", "", true); err == nil {
+		t.Error("replaceInMessages() error = nil for an invalid regex")
+	}
+}
+
+func TestLoadInteractiveSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	current := session.NewSession("openai:gpt-4")
+	if err := session.SaveSession(current); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	other := session.NewSession("anthropic:claude-3-5-sonnet")
+	other.AddMessage("user", "earlier question")
+	if err := session.SaveSession(other); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	var providerModel string
+	providerFor := func(s *session.Session) (llmc.Provider, error) {
+		providerModel = s.Model
+		return &fakeProvider{}, nil
+	}
+
+	// A turn that has not been saved yet must be saved before switching
+	current.AddMessage("user", "unsaved question")
+
+	loaded, provider, err := loadInteractiveSession(current, other.GetShortID(), providerFor)
+	if err != nil {
+		t.Fatalf("loadInteractiveSession() error = %v", err)
+	}
+	if loaded.ID != other.ID || loaded.MessageCount() != 1 {
+		t.Errorf("loadInteractiveSession() session = %s with %d message(s), want %s with 1", loaded.ID, loaded.MessageCount(), other.ID)
+	}
+	if provider == nil || providerModel != other.Model {
+		t.Errorf("loadInteractiveSession() provider model = %q, want %q", providerModel, other.Model)
+	}
+
+	saved, err := session.LoadSession(current.ID)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if saved.MessageCount() != 1 {
+		t.Errorf("current session has %d saved message(s), want 1", saved.MessageCount())
+	}
+
+	for _, id := range []string{"0000", loaded.ID} {
+		if _, _, err := loadInteractiveSession(loaded, id, providerFor); err == nil {
+			t.Errorf("loadInteractiveSession(%q) error = nil, want error", id)
+		}
+	}
+}
+
+func TestResponsePager(t *testing.T) {
+	response := "line1\nline2\nline3\nline4\nline5"
+
+	tests := []struct {
+		name     string
+		maxLines int
+		want     []string // Output of show, then of each /more until nothing is left
+	}{
+		{
+			name:     "no limit",
+			maxLines: 0,
+			want:     []string{"\nAI> line1\nline2\nline3\nline4\nline5\n\n"},
+		},
+		{
+			name:     "short response",
+			maxLines: 5,
+			want:     []string{"\nAI> line1\nline2\nline3\nline4\nline5\n\n"},
+		},
+		{
+			name:     "paged",
+			maxLines: 2,
+			want: []string{
+				"\nAI> line1\nline2\n... (3 more line(s), type /more to show them)\n\n",
+				"\nline3\nline4\n... (1 more line(s), type /more to show them)\n\n",
+				"\nline5\n\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pager := &responsePager{maxLines: tt.maxLines}
+			var buf bytes.Buffer
+			pager.show(&buf, "AI", response)
+			got := []string{buf.String()}
+			for {
+				buf.Reset()
+				if !pager.more(&buf) {
+					break
+				}
+				got = append(got, buf.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("responsePager output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponsePagerRender(t *testing.T) {
+	response := "## Steps\n- run `make`"
+
+	var buf bytes.Buffer
+	(&responsePager{}).show(&buf, "AI", response)
+	if want := "\nAI> ## Steps\n- run `make`\n\n"; buf.String() != want {
+		t.Errorf("show() = %q, want the raw Markdown %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	(&responsePager{render: true}).show(&buf, "AI", response)
+	if want := "\nAI> " + llmc.RenderMarkdown(response) + "\n\n"; buf.String() != want {
+		t.Errorf("show() = %q, want the rendered Markdown %q", buf.String(), want)
+	}
+}
+
+func TestResponsePagerKeepsSavedResponse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	response := strings.Repeat("long line\n", 50) + "end"
+	sess := session.NewSession("openai:gpt-4")
+	sess.AddMessage("user", "hi")
+	sess.AddAssistantMessage(response, sess.Model)
+	if err := session.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	pager := &responsePager{maxLines: 10}
+	var buf bytes.Buffer
+	pager.show(&buf, "AI", response)
+	if strings.Contains(buf.String(), "end") {
+		t.Errorf("show() = %q, want the response truncated", buf.String())
+	}
+
+	loaded, err := session.LoadSession(sess.ID)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if got := loaded.Messages[1].Content; got != response {
+		t.Errorf("saved response has %d bytes, want the full %d", len(got), len(response))
+	}
+}
+
+func TestSelectExcessSessions(t *testing.T) {
+	now := time.Now()
+	newSession := func(id, parentID string, age int) session.Session {
+		return session.Session{ID: id, ParentID: parentID, UpdatedAt: now.Add(-time.Duration(age) * time.Hour)}
+	}
+
+	tests := []struct {
+		name          string
+		sessions      []session.Session
+		maxCount      int
+		wantDelete    []string
+		wantProtected []string
+	}{
+		{
+			name:       "keeps newest",
+			sessions:   []session.Session{newSession("c", "", 3), newSession("a", "", 1), newSession("d", "", 4), newSession("b", "", 2)},
+			maxCount:   2,
+			wantDelete: []string{"c", "d"},
+		},
+		{
+			name:     "under limit",
+			sessions: []session.Session{newSession("a", "", 1), newSession("b", "", 2)},
+			maxCount: 2,
+		},
+		{
+			name:       "zero deletes all",
+			sessions:   []session.Session{newSession("a", "", 1), newSession("b", "", 2)},
+			maxCount:   0,
+			wantDelete: []string{"a", "b"},
+		},
+		{
+			name:          "parent of kept session",
+			sessions:      []session.Session{newSession("a", "c", 1), newSession("b", "", 2), newSession("c", "", 3), newSession("d", "", 4)},
+			maxCount:      2,
+			wantDelete:    []string{"d"},
+			wantProtected: []string{"c"},
+		},
+		{
+			name:          "grandparent of kept session",
+			sessions:      []session.Session{newSession("a", "b", 1), newSession("b", "c", 2), newSession("c", "", 3), newSession("d", "", 4)},
+			maxCount:      1,
+			wantDelete:    []string{"d"},
+			wantProtected: []string{"b", "c"},
+		},
+		{
+			name:       "parent of deleted session",
+			sessions:   []session.Session{newSession("a", "", 1), newSession("b", "c", 2), newSession("c", "", 3)},
+			maxCount:   1,
+			wantDelete: []string{"b", "c"},
+		},
+	}
+
+	ids := func(sessions []session.Session) []string {
+		var ids []string
+		for _, s := range sessions {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toDelete, protected := selectExcessSessions(tt.sessions, tt.maxCount)
+			if !reflect.DeepEqual(ids(toDelete), tt.wantDelete) {
+				t.Errorf("selectExcessSessions() toDelete = %v, want %v", ids(toDelete), tt.wantDelete)
+			}
+			if !reflect.DeepEqual(ids(protected), tt.wantProtected) {
+				t.Errorf("selectExcessSessions() protected = %v, want %v", ids(protected), tt.wantProtected)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2h", want: now.Add(-2 * time.Hour)},
+		{value: "90m", want: now.Add(-90 * time.Minute)},
+		{value: "3d", want: now.AddDate(0, 0, -3)},
+		{value: "2025-01-01", want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2025-03", want: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2025-06-15T09:30:00Z", want: time.Date(2025, 6, 15, 9, 30, 0, 0, time.UTC)},
+		{value: "-2h", wantErr: true},
+		{value: "yesterday", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("parseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMessageHistorySince(t *testing.T) {
+	now := time.Now()
+	sess := session.NewSession("openai:gpt-4")
+	for i, age := range []time.Duration{72 * time.Hour, 5 * time.Hour, 90 * time.Minute, 10 * time.Minute} {
+		sess.AddMessage("user", fmt.Sprintf("message %d", i+1))
+		sess.Messages[i].Timestamp = now.Add(-age)
+	}
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tests := []struct {
+		name       string
+		since      string
+		wantLabels []string
+		wantHidden string
+	}{
+		{name: "relative", since: "2h", wantLabels: []string{"[3]", "[4]"}, wantHidden: "(2 messages before"},
+		{name: "days", since: "1d", wantLabels: []string{"[2]", "[3]", "[4]"}, wantHidden: "(1 messages before"},
+		{name: "absolute", since: now.Add(-time.Hour).UTC().Format(time.RFC3339), wantLabels: []string{"[4]"}, wantHidden: "(3 messages before"},
+		{name: "all", since: "2000-01-01", wantLabels: []string{"[1]", "[2]", "[3]", "[4]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, err := parseSince(tt.since, now)
+			if err != nil {
+				t.Fatalf("parseSince() error = %v", err)
+			}
+			var buf bytes.Buffer
+			writeMessageHistory(&buf, sess, cfg, 0, 0, since)
+			out := buf.String()
+
+			var gotLabels []string
+			for _, line := range strings.Split(out, "\n") {
+				if strings.HasPrefix(line, "[") {
+					gotLabels = append(gotLabels, strings.Fields(line)[0])
+				}
+			}
+			if strings.Join(gotLabels, ",") != strings.Join(tt.wantLabels, ",") {
+				t.Errorf("labels = %v, want %v", gotLabels, tt.wantLabels)
+			}
+			if tt.wantHidden == "" && strings.Contains(out, "omitted") {
+				t.Errorf("unexpected omitted marker in output:\n%s", out)
+			}
+			if tt.wantHidden != "" && !strings.Contains(out, tt.wantHidden) {
+				t.Errorf("output missing %q:\n%s", tt.wantHidden, out)
+			}
+		})
+	}
+}
+
+func TestGroupSessionStats(t *testing.T) {
+	newSession := func(model, template string, created time.Time, messages int) session.Session {
+		sess := session.NewSession(model)
+		sess.TemplateName = template
+		sess.CreatedAt = created
+		for i := 0; i < messages; i++ {
+			sess.AddMessage("user", "hello")
+		}
+		return *sess
+	}
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	sessions := []session.Session{
+		newSession("openai:gpt-4", "review", day, 2),
+		newSession("anthropic:claude-3-5-sonnet", "", day.Add(13*time.Hour+30*time.Minute), 1), // 23:30 UTC
+		newSession("openai:gpt-4", "", day.AddDate(0, 0, 1), 4),
+		newSession("gemini:gemini-pro", "review", day.AddDate(0, 0, -1), 3),
+	}
+
+	tests := []struct {
+		name string
+		by   string
+		loc  *time.Location
+		want []sessionStatsGroup
+	}{
+		{
+			name: "model",
+			by:   statsByModel,
+			loc:  time.UTC,
+			want: []sessionStatsGroup{
+				{Key: "openai:gpt-4", Sessions: 2, Messages: 6},
+				{Key: "anthropic:claude-3-5-sonnet", Sessions: 1, Messages: 1},
+				{Key: "gemini:gemini-pro", Sessions: 1, Messages: 3},
+			},
+		},
+		{
+			name: "template",
+			by:   statsByTemplate,
+			loc:  time.UTC,
+			want: []sessionStatsGroup{
+				{Key: noTemplateKey, Sessions: 2, Messages: 5},
+				{Key: "review", Sessions: 2, Messages: 5},
+			},
+		},
+		{
+			name: "day",
+			by:   statsByDay,
+			loc:  time.UTC,
+			want: []sessionStatsGroup{
+				{Key: "2024-12-31", Sessions: 1, Messages: 3},
+				{Key: "2025-01-01", Sessions: 2, Messages: 3},
+				{Key: "2025-01-02", Sessions: 1, Messages: 4},
+			},
+		},
+		{
+			name: "day in timezone",
+			by:   statsByDay,
+			loc:  time.FixedZone("UTC+9", 9*60*60),
+			want: []sessionStatsGroup{
+				{Key: "2024-12-31", Sessions: 1, Messages: 3},
+				{Key: "2025-01-01", Sessions: 1, Messages: 2},
+				{Key: "2025-01-02", Sessions: 2, Messages: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupSessionStats(sessions, tt.by, tt.loc)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupSessionStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := groupSessionStats(nil, statsByModel, time.UTC); got == nil || len(got) != 0 {
+		t.Errorf("groupSessionStats(nil) = %#v, want an empty slice", got)
+	}
+}
+
+func TestWriteSessionUsage(t *testing.T) {
+	var total llmc.Usage
+	var buf bytes.Buffer
+	writeSessionUsage(&buf, &llmc.Usage{InputTokens: 10, OutputTokens: 2, TotalTokens: 12}, &total)
+	writeSessionUsage(&buf, nil, &total)
+	writeSessionUsage(&buf, &llmc.Usage{InputTokens: 20, OutputTokens: 3, TotalTokens: 23}, &total)
+
+	want := llmc.Usage{InputTokens: 30, OutputTokens: 5, TotalTokens: 35}
+	if total != want {
+		t.Errorf("session total = %v, want %v", total, want)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+	if len(lines) != 3 {
+		t.Fatalf("writeSessionUsage() wrote %d line(s), want 3:\n%s", len(lines), buf.String())
+	}
+	if wantLine := "Tokens: 20 input, 3 output (23 total); session total: 30 input, 5 output (35 total)"; lines[2] != wantLine {
+		t.Errorf("writeSessionUsage() = %q, want %q", lines[2], wantLine)
+	}
+	if !strings.Contains(lines[1], "not reported") {
+		t.Errorf("writeSessionUsage(nil) = %q, want a not reported notice", lines[1])
+	}
+}
+
+func TestSummarizeSessionInto(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	source := session.NewSession("openai:gpt-4")
+	source.AddMessage("user", "question")
+	source.AddAssistantMessage("answer", source.Model)
+	target := session.NewSession("openai:gpt-4")
+	target.Name = "notes"
+	target.AddMessage("user", "earlier note")
+	for _, s := range []*session.Session{source, target} {
+		if err := session.SaveSession(s); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+	}
+
+	if err := summarizeSessionInto(context.Background(), source, target, &contextLimitProvider{}); err != nil {
+		t.Fatalf("summarizeSessionInto() error = %v", err)
+	}
+
+	loaded, err := session.LoadSession(target.ID)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if len(loaded.Messages) != 2 || !strings.Contains(loaded.Messages[1].Content, "the summary") {
+		t.Errorf("target messages = %v, want the summary appended", loaded.Messages)
+	}
+
+	unchanged, err := session.LoadSession(source.ID)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if len(unchanged.Messages) != 2 || unchanged.Messages[1].Content != "answer" {
+		t.Errorf("source messages = %v, want them unchanged", unchanged.Messages)
+	}
+}
+
+func TestShowSessionWithInvalidModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sess := session.NewSession("gpt-4")
+	sess.AddMessage("user", "question")
+	sess.AddAssistantMessage("answer", "")
+	if err := session.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	loaded, err := session.FindSessionByPrefix(sess.GetShortID())
+	if err != nil {
+		t.Fatalf("FindSessionByPrefix() error = %v", err)
+	}
+	cfg := &config.Config{Timezone: "UTC"}
+	var buf bytes.Buffer
+	writeSessionHeader(&buf, loaded, cfg)
+	writeMessageHistory(&buf, loaded, cfg, 0, 0, time.Time{})
+
+	for _, want := range []string{"Model: gpt-4 (invalid", "question", "answer"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestResolveSessionModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	newSess := func(model string) *session.Session {
+		sess := session.NewSession(model)
+		if err := session.SaveSession(sess); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+		return sess
+	}
+	storedModel := func(sess *session.Session) string {
+		loaded, err := session.LoadSession(sess.ID)
+		if err != nil {
+			t.Fatalf("LoadSession() error = %v", err)
+		}
+		return loaded.Model
+	}
+
+	t.Run("valid model is kept", func(t *testing.T) {
+		sess := newSess("openai:gpt-4")
+		got, err := resolveSessionModel(sess, "gemini:gemini-pro", strings.NewReader(""), io.Discard, false)
+		if err != nil || got != "openai:gpt-4" {
+			t.Errorf("resolveSessionModel() = %q, %v, want openai:gpt-4", got, err)
+		}
+	})
+
+	t.Run("override without repair", func(t *testing.T) {
+		sess := newSess("gpt-4")
+		got, err := resolveSessionModel(sess, "openai:gpt-4", strings.NewReader(""), io.Discard, false)
+		if err != nil || got != "openai:gpt-4" {
+			t.Errorf("resolveSessionModel() = %q, %v, want openai:gpt-4", got, err)
+		}
+		if stored := storedModel(sess); stored != "gpt-4" {
+			t.Errorf("stored model = %q, want it unchanged", stored)
+		}
+	})
+
+	t.Run("prompt and repair", func(t *testing.T) {
+		sess := newSess("gpt-4")
+		got, err := resolveSessionModel(sess, "", strings.NewReader("openai:gpt-4\ny\n"), io.Discard, true)
+		if err != nil || got != "openai:gpt-4" {
+			t.Errorf("resolveSessionModel() = %q, %v, want openai:gpt-4", got, err)
+		}
+		if stored := storedModel(sess); stored != "openai:gpt-4" {
+			t.Errorf("stored model = %q, want openai:gpt-4", stored)
+		}
+	})
+
+	t.Run("no model given", func(t *testing.T) {
+		sess := newSess("gpt-4")
+		if _, err := resolveSessionModel(sess, "", strings.NewReader(""), io.Discard, false); exitCode(err) != 2 {
+			t.Errorf("resolveSessionModel() error = %v, want a usage error", err)
+		}
+	})
+}
+
+func TestFilterSessionsByTags(t *testing.T) {
+	sessions := []session.Session{
+		{ID: "a", Tags: []string{"work", "go"}},
+		{ID: "b", Tags: []string{"work"}},
+		{ID: "c"},
+	}
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: nil, want: []string{"a", "b", "c"}},
+		{tags: []string{"work"}, want: []string{"a", "b"}},
+		{tags: []string{"work", "go"}, want: []string{"a"}},
+		{tags: []string{"missing"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, sess := range filterSessionsByTags(sessions, tt.tags) {
+			got = append(got, sess.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterSessionsByTags(%q) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestAncestorsSinceBranch(t *testing.T) {
+	root := &session.Session{ID: "root"}
+	summary := &session.Session{ID: "summary", ParentID: "root"}
+	branch := &session.Session{ID: "branch", ParentID: "summary", BranchedAt: 3}
+	child := &session.Session{ID: "child", ParentID: "branch"}
+
+	ids := func(sessions []*session.Session) []string {
+		var ids []string
+		for _, s := range sessions {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+
+	if got := ids(ancestorsSinceBranch([]*session.Session{root, summary}, branch)); got != nil {
+		t.Errorf("ancestorsSinceBranch() for a branch = %q, want none", got)
+	}
+	if got, want := ids(ancestorsSinceBranch([]*session.Session{root, summary, branch}, child)), []string{"branch"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ancestorsSinceBranch() = %q, want %q", got, want)
+	}
+	if got, want := ids(ancestorsSinceBranch([]*session.Session{root}, summary)), []string{"root"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ancestorsSinceBranch() without branches = %q, want %q", got, want)
+	}
+}
+
+func TestSummarizeAtThreshold(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sess := session.NewSession("openai:gpt-4")
+	for i := 0; i < 2; i++ {
+		sess.AddMessage("user", "question")
+		sess.AddAssistantMessage("answer", sess.Model)
+	}
+	provider := &contextLimitProvider{}
+
+	for _, threshold := range []int{0, 4} {
+		got, err := summarizeAtThreshold(context.Background(), sess, provider, threshold, io.Discard)
+		if err != nil || got != sess {
+			t.Errorf("summarizeAtThreshold(threshold %d) = %v, %v, want the same session", threshold, got, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	got, err := summarizeAtThreshold(context.Background(), sess, provider, 3, &buf)
+	if err != nil {
+		t.Fatalf("summarizeAtThreshold() error = %v", err)
+	}
+	if got == sess || got.ParentID != sess.ID {
+		t.Fatalf("summarizeAtThreshold() parent = %q, want a new session with parent %q", got.ParentID, sess.ID)
+	}
+	if _, err := session.LoadSession(got.ID); err != nil {
+		t.Errorf("summarized session was not saved: %v", err)
+	}
+	if !strings.Contains(buf.String(), "threshold: 3") {
+		t.Errorf("notice = %q, want the threshold mentioned", buf.String())
+	}
+}
+
+func TestParseMessageRange(t *testing.T) {
+	tests := []struct {
+		value       string
+		first, last int
+		wantErr     bool
+	}{
+		{value: "4", first: 4, last: 4},
+		{value: "3-5", first: 3, last: 5},
+		{value: "2-2", first: 2, last: 2},
+		{value: "0", wantErr: true},
+		{value: "5-3", wantErr: true},
+		{value: "3-", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "-2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		first, last, err := parseMessageRange(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseMessageRange(%q) error = nil, want error", tt.value)
+			}
+			continue
+		}
+		if err != nil || first != tt.first || last != tt.last {
+			t.Errorf("parseMessageRange(%q) = %d, %d, %v; want %d, %d", tt.value, first, last, err, tt.first, tt.last)
+		}
+	}
+}
+
+func TestOrphanedMessageWarnings(t *testing.T) {
+	messages := []llmc.Message{
+		{Role: "user", Content: "q1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "q2"},
+		{Role: "assistant", Content: "a2"},
+	}
+
+	tests := []struct {
+		name        string
+		first, last int
+		want        []string
+	}{
+		{name: "whole exchange", first: 3, last: 4, want: nil},
+		{name: "response", first: 2, last: 2, want: []string{"message 1 (user) is left without its response"}},
+		{name: "user message", first: 3, last: 3, want: []string{"message 4 (assistant) is left without its user message"}},
+		{
+			name:  "across exchanges",
+			first: 2, last: 3,
+			want: []string{
+				"message 1 (user) is left without its response",
+				"message 4 (assistant) is left without its user message",
+			},
+		},
+		{name: "out of range", first: 4, last: 5, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orphanedMessageWarnings(messages, tt.first, tt.last); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orphanedMessageWarnings(%d, %d) = %q, want %q", tt.first, tt.last, got, tt.want)
+			}
+		})
+	}
+}
+
+// historyProvider answers with a numbered response and records the history it was sent
+type historyProvider struct {
+	fakeProvider
+	calls    int
+	messages []llmc.Message
+	message  string
+	err      error
+}
+
+func (p *historyProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	p.calls++
+	p.messages, p.message = messages, newMessage
+	if p.err != nil {
+		return "", p.err
+	}
+	return fmt.Sprintf("answer %d", p.calls), nil
+}
+
+func TestRetryLastResponse(t *testing.T) {
+	newSess := func() *session.Session {
+		sess := session.NewSession("openai:gpt-4")
+		sess.AddMessage("user", "q1")
+		sess.AddAssistantMessage("a1", "openai:gpt-4")
+		sess.AddMessage("user", "q2")
+		sess.AddAssistantMessage("poor answer", "openai:gpt-4")
+		return sess
+	}
+
+	t.Run("replaces the last response", func(t *testing.T) {
+		sess := newSess()
+		provider := &historyProvider{}
+		response, err := retryLastResponse(context.Background(), sess, provider, "openai:gpt-4")
+		if err != nil {
+			t.Fatalf("retryLastResponse() error = %v", err)
+		}
+		if response != "answer 1" {
+			t.Errorf("response = %q, want %q", response, "answer 1")
+		}
+		if provider.message != "q2" || len(provider.messages) != 2 || provider.messages[1].Content != "a1" {
+			t.Errorf("sent %q with history %v, want q2 after the first exchange", provider.message, provider.messages)
+		}
+		if len(sess.Messages) != 4 || sess.Messages[2].Content != "q2" || sess.Messages[3].Content != "answer 1" || sess.Messages[3].Role != "assistant" {
+			t.Errorf("messages = %v, want the last response replaced", sess.Messages)
+		}
+	})
+
+	t.Run("failed request keeps the response", func(t *testing.T) {
+		sess := newSess()
+		provider := &historyProvider{err: errors.New("unavailable")}
+		if _, err := retryLastResponse(context.Background(), sess, provider, "openai:gpt-4"); err == nil {
+			t.Fatal("retryLastResponse() error = nil, want the request error")
+		}
+		if len(sess.Messages) != 4 || sess.Messages[3].Content != "poor answer" {
+			t.Errorf("messages = %v, want them unchanged", sess.Messages)
+		}
+	})
+
+	t.Run("no previous exchange", func(t *testing.T) {
+		sess := session.NewSession("openai:gpt-4")
+		provider := &historyProvider{}
+		if _, err := retryLastResponse(context.Background(), sess, provider, "openai:gpt-4"); err == nil {
+			t.Error("retryLastResponse() error = nil for an empty session, want an error")
+		}
+		sess.AddMessage("user", "summary of the parent")
+		if _, err := retryLastResponse(context.Background(), sess, provider, "openai:gpt-4"); err == nil {
+			t.Error("retryLastResponse() error = nil for a session ending with a user message, want an error")
+		}
+		if provider.calls != 0 {
+			t.Errorf("provider called %d time(s), want 0", provider.calls)
+		}
+	})
+}
+
+func TestTurnRecordsOverrideModel(t *testing.T) {
+	// A hand-edited session whose stored model is invalid, continued with --model
+	sess := session.NewSession("gpt-4")
+	model, err := resolveSessionModel(sess, "openai:gpt-4o", strings.NewReader(""), io.Discard, false)
+	if err != nil {
+		t.Fatalf("resolveSessionModel() error = %v", err)
+	}
+
+	provider := &historyProvider{}
+	addTurn(sess, "q1", "a1", provider, model)
+	if got := sess.Messages[1].Model; got != "openai:gpt-4o" {
+		t.Errorf("turn model = %q, want the override %q", got, "openai:gpt-4o")
+	}
+
+	if _, err := retryLastResponse(context.Background(), sess, provider, model); err != nil {
+		t.Fatalf("retryLastResponse() error = %v", err)
+	}
+	if got := sess.Messages[1].Model; got != "openai:gpt-4o" {
+		t.Errorf("retried turn model = %q, want the override %q", got, "openai:gpt-4o")
+	}
+	if sess.Model != "gpt-4" {
+		t.Errorf("session model = %q, want the stored model unchanged", sess.Model)
+	}
+}
+
+func TestSessionModel(t *testing.T) {
+	if got := sessionModel(session.NewSession("anthropic:claude-3-5-sonnet-20241022"), "openai:gpt-4o"); got != "anthropic:claude-3-5-sonnet-20241022" {
+		t.Errorf("sessionModel() = %q, want the stored model", got)
+	}
+	if got := sessionModel(session.NewSession("gpt-4"), "openai:gpt-4o"); got != "openai:gpt-4o" {
+		t.Errorf("sessionModel() = %q, want the fallback for an invalid stored model", got)
+	}
+}
+
+// samplingProvider records the temperature each request was sent with
+type samplingProvider struct {
+	historyProvider
+	sampling     llmc.SamplingParams
+	temperatures []*float64
+}
+
+func (p *samplingProvider) SetSampling(params llmc.SamplingParams) { p.sampling = params }
+
+func (p *samplingProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	p.temperatures = append(p.temperatures, p.sampling.Temperature)
+	return p.historyProvider.ChatWithHistoryContext(ctx, systemPrompt, messages, newMessage)
+}
+
+func TestRetryWithTemperature(t *testing.T) {
+	sess := session.NewSession("openai:gpt-4")
+	sess.AddMessage("user", "q1")
+	sess.AddAssistantMessage("a1", "openai:gpt-4")
+
+	base := 0.2
+	sampling := llmc.SamplingParams{Temperature: &base}
+	provider := &samplingProvider{sampling: sampling}
+
+	override := 0.9
+	if _, err := retryWithTemperature(context.Background(), sess, provider, "openai:gpt-4", sampling, &override); err != nil {
+		t.Fatalf("retryWithTemperature() error = %v", err)
+	}
+	if _, err := retryWithTemperature(context.Background(), sess, provider, "openai:gpt-4", sampling, nil); err != nil {
+		t.Fatalf("retryWithTemperature() error = %v", err)
+	}
+
+	if len(provider.temperatures) != 2 || provider.temperatures[0] == nil || *provider.temperatures[0] != 0.9 {
+		t.Fatalf("temperatures = %v, want 0.9 for the first request", provider.temperatures)
+	}
+	if provider.temperatures[1] == nil || *provider.temperatures[1] != 0.2 {
+		t.Errorf("second request temperature = %v, want the session temperature 0.2", provider.temperatures[1])
+	}
+	if provider.sampling.Temperature == nil || *provider.sampling.Temperature != 0.2 {
+		t.Errorf("sampling after retry = %v, want the session temperature restored", provider.sampling)
+	}
+	if sess.Sampling != nil {
+		t.Errorf("session sampling = %v, want it unchanged", sess.Sampling)
+	}
+}
+
+func TestParseRetryTemperature(t *testing.T) {
+	if got, err := parseRetryTemperature(nil); err != nil || got != nil {
+		t.Errorf("parseRetryTemperature() = %v, %v, want nil", got, err)
+	}
+	if got, err := parseRetryTemperature([]string{"0.9"}); err != nil || got == nil || *got != 0.9 {
+		t.Errorf("parseRetryTemperature(0.9) = %v, %v, want 0.9", got, err)
+	}
+	for _, args := range [][]string{{"hot"}, {"3"}, {"0.5", "1"}} {
+		if _, err := parseRetryTemperature(args); err == nil {
+			t.Errorf("parseRetryTemperature(%q) error = nil, want an error", args)
+		}
+	}
+}
+
+// scriptedReader returns its lines (or errors) in order, then io.EOF
+type scriptedReader struct {
+	lines   []string
+	errs    map[int]error // Error returned with the line of the same index
+	next    int
+	prompts []string
+}
+
+func (r *scriptedReader) Readline() (string, error) {
+	if r.next >= len(r.lines) {
+		return "", io.EOF
+	}
+	i := r.next
+	r.next++
+	return r.lines[i], r.errs[i]
+}
+
+func (r *scriptedReader) SetPrompt(prompt string) {
+	r.prompts = append(r.prompts, prompt)
+}
+
+func TestReadTurnInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		errs     map[int]error
+		want     string
+		wantErr  error
+		recorded []string
+	}{
+		{name: "single line", lines: []string{"hello", "next"}, want: "hello", recorded: []string{"hello"}},
+		{name: "backslash continuation", lines: []string{`first \`, "second"}, want: "first \nsecond", recorded: []string{`first \`, "second"}},
+		{
+			name:     "multi-line mode",
+			lines:    []string{"/multi", "line 1", "", "/not a command", " . ", "next"},
+			want:     "line 1\n\n/not a command",
+			recorded: []string{"/multi", "line 1", "/not a command"},
+		},
+		{name: "EOF during multi-line mode keeps the lines", lines: []string{"/MULTI", "line 1", "line 2"}, want: "line 1\nline 2", recorded: []string{"/MULTI", "line 1", "line 2"}},
+		{name: "EOF during continuation keeps the lines", lines: []string{`first \`}, want: "first", recorded: []string{`first \`}},
+		{name: "EOF on an empty prompt", lines: nil, wantErr: io.EOF},
+		{name: "EOF after /multi without lines", lines: []string{"/multi"}, wantErr: io.EOF, recorded: []string{"/multi"}},
+		{name: "Ctrl+C on an empty prompt", lines: []string{""}, errs: map[int]error{0: readline.ErrInterrupt}, wantErr: io.EOF},
+		{
+			name:     "Ctrl+C cancels multi-line input",
+			lines:    []string{"/multi", "line 1", ""},
+			errs:     map[int]error{2: readline.ErrInterrupt},
+			want:     "",
+			recorded: []string{"/multi", "line 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &scriptedReader{lines: tt.lines, errs: tt.errs}
+			var recorded []string
+			got, err := readTurnInput(r, "You> ", func(line string) { recorded = append(recorded, line) }, io.Discard)
+			if err != tt.wantErr {
+				t.Fatalf("readTurnInput() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readTurnInput() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(recorded, tt.recorded) {
+				t.Errorf("recorded history = %q, want %q", recorded, tt.recorded)
+			}
+		})
+	}
+}
+
+func TestShowMarkdown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sess := session.NewSession("openai:gpt-4")
+	sess.Name = "docs"
+	sess.SystemPrompt = "Be brief."
+	sess.AddMessage("user", "How do I list files?")
+	sess.AddAssistantMessage("Use `ls`:\n\n```sh\nls -la\n```", "openai:gpt-4")
+	if err := session.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	if err := sessionsShowCmd.Flags().Set("markdown", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsShowCmd.Flags().Set("markdown", "false")
+
+	// Capture stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := sessionsShowCmd.RunE(sessionsShowCmd, []string{sess.GetShortID()})
+	os.Stdout = stdout
+	w.Close()
+	got, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("sessions show --markdown error = %v", runErr)
+	}
+
+	// The output is the same as the exported Markdown
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "session.md")
+	if _, err := exportSession(sess, cfg, []string{"markdown", path}); err != nil {
+		t.Fatalf("exportSession() error = %v", err)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("sessions show --markdown =\n%s\nwant the exported Markdown:\n%s", got, want)
+	}
+	if !strings.Contains(string(got), "- **Session:** "+sess.ID) {
+		t.Errorf("sessions show --markdown has no metadata header:\n%s", got)
+	}
+}
+
+func TestSessionsInfoDefaultsToLatest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	older := session.NewSession("openai:gpt-4")
+	older.Name = "older"
+	older.UpdatedAt = time.Now().Add(-time.Hour)
+	latest := session.NewSession("anthropic:claude-3-5-sonnet-20241022")
+	latest.Name = "current"
+	latest.ParentID = older.ID
+	latest.TemplateName = "review"
+	latest.AddMessage("user", "Hello")
+	latest.AddAssistantMessage("Hi", latest.Model)
+	for _, sess := range []*session.Session{older, latest} {
+		if err := session.SaveSession(sess); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+	}
+
+	// Capture stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := sessionsInfoCmd.RunE(sessionsInfoCmd, nil)
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("sessions info error = %v", runErr)
+	}
+
+	got := string(out)
+	for _, want := range []string{
+		"ID: " + latest.GetShortID(),
+		"Name: current",
+		"Parent: " + older.ID,
+		"Model: anthropic:claude-3-5-sonnet-20241022",
+		"Messages: 2",
+		"Created: ",
+		"Template: review",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("sessions info output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Hello") || strings.Contains(got, "Continue this session") {
+		t.Errorf("sessions info output has the history or the continue hint:\n%s", got)
+	}
+}
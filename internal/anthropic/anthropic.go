@@ -2,15 +2,19 @@ package anthropic
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
 )
 
 const (
@@ -39,6 +43,10 @@ type MessagesAPIRequest struct {
 	MaxTokens int            `json:"max_tokens"`
 	System    string         `json:"system,omitempty"` // System prompt (optional)
 	Messages  []MessageInput `json:"messages"`
+	Stream    bool           `json:"stream,omitempty"` // Stream the response as server-sent events
+
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
 }
 
 // MessageInput represents a message in the conversation
@@ -78,6 +86,11 @@ type Usage struct {
 	OutputTokens int `json:"output_tokens"`
 }
 
+// toUsage converts the usage of a response (the API does not report a total)
+func (u Usage) toUsage() *llmc.Usage {
+	return &llmc.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, TotalTokens: u.InputTokens + u.OutputTokens}
+}
+
 // APIError represents an error in the API response
 type APIError struct {
 	Type    string `json:"type"`
@@ -89,6 +102,7 @@ type Config interface {
 	GetModel() string
 	GetBaseURL(provider string) (string, error)
 	GetToken(provider string) (string, error)
+	FormatTime(t time.Time) string
 }
 
 // Provider implements the llmc.Provider interface for Anthropic
@@ -96,6 +110,10 @@ type Provider struct {
 	config           Config
 	webSearchEnabled bool
 	debug            bool
+	timeout          time.Duration // HTTP request timeout (0 = no timeout)
+	sampling         llmc.SamplingParams
+	prefill          string      // Text the assistant's response starts with ("" = none)
+	lastUsage        *llmc.Usage // Token usage of the last successful request
 }
 
 // NewProvider creates a new Anthropic provider instance
@@ -125,6 +143,109 @@ func (p *Provider) SetDebug(enabled bool) {
 	p.debug = enabled
 }
 
+// SetTimeout sets the HTTP request timeout (0 = no timeout)
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// SetSampling sets the sampling parameters
+func (p *Provider) SetSampling(params llmc.SamplingParams) {
+	p.sampling = params
+}
+
+// applySampling sets the configured sampling parameters on the request
+func (p *Provider) applySampling(req *MessagesAPIRequest) {
+	req.Temperature = p.sampling.Temperature
+	req.TopP = p.sampling.TopP
+	if p.sampling.MaxTokens != nil {
+		req.MaxTokens = *p.sampling.MaxTokens
+	}
+}
+
+// SetPrefill sets the text the assistant's response starts with ("" = none)
+func (p *Provider) SetPrefill(text string) {
+	p.prefill = text
+}
+
+// applyPrefill ends the request with an assistant turn holding the prefill, which the
+// model continues. The API rejects a final assistant turn that ends with whitespace.
+func (p *Provider) applyPrefill(req *MessagesAPIRequest) {
+	prefill := strings.TrimRight(p.prefill, " \t\r\n")
+	if prefill == "" {
+		return
+	}
+	req.Messages = append(req.Messages, MessageInput{
+		Role:    "assistant",
+		Content: []Content{{Type: "text", Text: prefill}},
+	})
+}
+
+// LastUsage returns the token usage of the last successful request
+func (p *Provider) LastUsage() *llmc.Usage {
+	return p.lastUsage
+}
+
+// setAuthHeader sets the API key of req. Without a token (e.g. for a local
+// server behind the custom provider) no x-api-key header is sent.
+func setAuthHeader(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("x-api-key", token)
+	}
+}
+
+// httpClient returns an HTTP client configured with the provider's timeout.
+// It uses the shared transport so that connections are reused across requests.
+// In debug mode, the timing of each request is printed.
+func (p *Provider) httpClient() *http.Client {
+	client := &http.Client{Timeout: p.timeout, Transport: llmc.ProviderTransport()}
+	if p.debug {
+		client.Transport = llmc.NewTimingTransport(llmc.ProviderTransport(), func(t llmc.RequestTiming) {
+			fmt.Fprintf(os.Stderr, "Timing: %s\n", t)
+		})
+	}
+	return client
+}
+
+// HealthCheck confirms that the API is reachable and the token is accepted by
+// fetching a single model, without generating any content
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models?limit=1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	setAuthHeader(req, token)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+
+	// No retries: a health check should report the first failure
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		if p.debug {
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+		}
+		return llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+	return llmc.NonJSONResponseError(resp, body, p.debug)
+}
+
 // ListModels returns the list of supported models from the API
 func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 	// Get token for Anthropic
@@ -146,13 +267,17 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 	}
 
 	// Set headers
-	req.Header.Set("x-api-key", token)
+	setAuthHeader(req, token)
 	req.Header.Set("anthropic-version", AnthropicVersion)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
+		var timeoutErr *httpretry.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return nil, fmt.Errorf("failed to connect to API: %w", err)
+		}
 		if p.debug {
 			return nil, fmt.Errorf("failed to connect to API: %v", err)
 		}
@@ -168,10 +293,16 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return nil, fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return nil, fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return nil, llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return nil, err
 	}
 
 	// Parse response
@@ -192,10 +323,8 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 		// Use display name as description if available
 		description := model.DisplayName
 		if description == "" && !model.CreatedAt.IsZero() {
-			// Convert created timestamp to JST and use as description
-			jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-			createdTime := model.CreatedAt.In(jst)
-			description = fmt.Sprintf("Created: %s", createdTime.Format("2006-01-02 15:04:05 JST"))
+			// Use created timestamp (in the configured time format and timezone) as description
+			description = fmt.Sprintf("Created: %s", p.config.FormatTime(model.CreatedAt))
 		}
 
 		models = append(models, llmc.ModelInfo{
@@ -215,6 +344,11 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 
 // Chat sends a message to Anthropic's Messages API and returns the response
 func (p *Provider) Chat(message string) (string, error) {
+	return p.ChatContext(context.Background(), message)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (p *Provider) ChatContext(ctx context.Context, message string) (string, error) {
 	// Check if web search is enabled (not supported by Anthropic)
 	if p.webSearchEnabled {
 		return "", fmt.Errorf("web search is not supported by Anthropic provider")
@@ -242,6 +376,8 @@ func (p *Provider) Chat(message string) (string, error) {
 			},
 		},
 	}
+	p.applySampling(&reqBody)
+	p.applyPrefill(&reqBody)
 
 	// Convert request body to JSON
 	jsonData, err := json.Marshal(reqBody)
@@ -262,21 +398,21 @@ func (p *Provider) Chat(message string) (string, error) {
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", baseURL+"/messages", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", token)
+	setAuthHeader(req, token)
 	req.Header.Set("anthropic-version", AnthropicVersion)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %v", err)
+		return "", fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -288,19 +424,30 @@ func (p *Provider) Chat(message string) (string, error) {
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == httpretry.StatusOverloaded {
+			return "", overloadedError()
+		}
+
 		// Try to parse error message
 		var errResp MessagesAPIResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			message := fmt.Sprintf("API error: %s", errResp.Error.Message)
 			if p.debug {
-				return "", fmt.Errorf("API error [%s]: %s (HTTP %d)", errResp.Error.Type, errResp.Error.Message, resp.StatusCode)
+				message = fmt.Sprintf("API error [%s]: %s (HTTP %d)", errResp.Error.Type, errResp.Error.Message, resp.StatusCode)
 			}
-			return "", fmt.Errorf("API error: %s", errResp.Error.Message)
+			return "", llmc.NewAPIError(ProviderName, resp.StatusCode, errResp.Error.Type, message)
 		}
 
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return "", fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return "", fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return "", llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", err
 	}
 
 	// Parse response
@@ -314,11 +461,12 @@ func (p *Provider) Chat(message string) (string, error) {
 
 	// Check for API error in response
 	if result.Error != nil {
+		message := fmt.Sprintf("API error: %s", result.Error.Message)
 		if p.debug {
-			return "", fmt.Errorf("API error [%s]: %s (id=%s)",
+			message = fmt.Sprintf("API error [%s]: %s (id=%s)",
 				result.Error.Type, result.Error.Message, result.ID)
 		}
-		return "", fmt.Errorf("API error: %s", result.Error.Message)
+		return "", llmc.NewAPIError(ProviderName, 0, result.Error.Type, message)
 	}
 
 	if len(result.Content) == 0 {
@@ -345,53 +493,28 @@ func (p *Provider) Chat(message string) (string, error) {
 		return "", fmt.Errorf("no text content found in API response. Use --verbose for details")
 	}
 
+	p.lastUsage = result.Usage.toUsage()
 	return strings.Join(textBlocks, "\n"), nil
 }
 
+// overloadedError returns the error of an overloaded_error (HTTP 529) response, which is
+// only returned once the retries set by overloaded_retries have been used up
+func overloadedError() error {
+	message := fmt.Sprintf("API is overloaded (still overloaded after %d retries); try again later, raise overloaded_retries or set fallback_models", httpretry.CurrentPolicy().OverloadedRetries)
+	return llmc.NewAPIError(ProviderName, httpretry.StatusOverloaded, "overloaded_error", message)
+}
+
 // ChatWithHistory sends a conversation history with a new message to Anthropic's Messages API
 func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
-	// Check if web search is enabled (not supported by Anthropic)
-	if p.webSearchEnabled {
-		return "", fmt.Errorf("web search is not supported by Anthropic provider")
-	}
-
-	// Extract model name from provider:model format
-	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
-	if err != nil {
-		return "", fmt.Errorf("invalid model format: %w", err)
-	}
-
-	// Convert messages to MessageInput array
-	inputMessages := make([]MessageInput, 0, len(messages)+1)
-	for _, msg := range messages {
-		inputMessages = append(inputMessages, MessageInput{
-			Role: msg.Role,
-			Content: []Content{
-				{
-					Type: "text",
-					Text: msg.Content,
-				},
-			},
-		})
-	}
-
-	// Add new user message
-	inputMessages = append(inputMessages, MessageInput{
-		Role: "user",
-		Content: []Content{
-			{
-				Type: "text",
-				Text: newMessage,
-			},
-		},
-	})
+	return p.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
 
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (p *Provider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
 	// Prepare the request body
-	reqBody := MessagesAPIRequest{
-		Model:     modelName,
-		MaxTokens: 8192, // Default max tokens
-		System:    systemPrompt,
-		Messages:  inputMessages,
+	reqBody, err := p.newHistoryRequest(systemPrompt, messages, newMessage)
+	if err != nil {
+		return "", err
 	}
 
 	// Convert request body to JSON
@@ -413,21 +536,21 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", baseURL+"/messages", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", token)
+	setAuthHeader(req, token)
 	req.Header.Set("anthropic-version", AnthropicVersion)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %v", err)
+		return "", fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -439,19 +562,30 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == httpretry.StatusOverloaded {
+			return "", overloadedError()
+		}
+
 		// Try to parse error message
 		var errResp MessagesAPIResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			message := fmt.Sprintf("API error: %s", errResp.Error.Message)
 			if p.debug {
-				return "", fmt.Errorf("API error [%s]: %s (HTTP %d)", errResp.Error.Type, errResp.Error.Message, resp.StatusCode)
+				message = fmt.Sprintf("API error [%s]: %s (HTTP %d)", errResp.Error.Type, errResp.Error.Message, resp.StatusCode)
 			}
-			return "", fmt.Errorf("API error: %s", errResp.Error.Message)
+			return "", llmc.NewAPIError(ProviderName, resp.StatusCode, errResp.Error.Type, message)
 		}
 
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return "", fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return "", fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return "", llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", err
 	}
 
 	// Parse response
@@ -465,11 +599,12 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 
 	// Check for API error in response
 	if result.Error != nil {
+		message := fmt.Sprintf("API error: %s", result.Error.Message)
 		if p.debug {
-			return "", fmt.Errorf("API error [%s]: %s (id=%s)",
+			message = fmt.Sprintf("API error [%s]: %s (id=%s)",
 				result.Error.Type, result.Error.Message, result.ID)
 		}
-		return "", fmt.Errorf("API error: %s", result.Error.Message)
+		return "", llmc.NewAPIError(ProviderName, 0, result.Error.Type, message)
 	}
 
 	if len(result.Content) == 0 {
@@ -496,5 +631,57 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 		return "", fmt.Errorf("no text content found in API response. Use --verbose for details")
 	}
 
+	p.lastUsage = result.Usage.toUsage()
 	return strings.Join(textBlocks, "\n"), nil
 }
+
+// newHistoryRequest builds a Messages API request from a conversation history and a new message
+func (p *Provider) newHistoryRequest(systemPrompt string, messages []llmc.Message, newMessage string) (MessagesAPIRequest, error) {
+	// Check if web search is enabled (not supported by Anthropic)
+	if p.webSearchEnabled {
+		return MessagesAPIRequest{}, fmt.Errorf("web search is not supported by Anthropic provider")
+	}
+
+	// Extract model name from provider:model format
+	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
+	if err != nil {
+		return MessagesAPIRequest{}, fmt.Errorf("invalid model format: %w", err)
+	}
+
+	// Convert messages to MessageInput array
+	inputMessages := make([]MessageInput, 0, len(messages)+1)
+	for _, msg := range messages {
+		inputMessages = append(inputMessages, MessageInput{
+			Role: msg.Role,
+			Content: []Content{
+				{
+					Type: "text",
+					Text: msg.Content,
+				},
+			},
+		})
+	}
+
+	// Add new user message
+	inputMessages = append(inputMessages, MessageInput{
+		Role: "user",
+		Content: []Content{
+			{
+				Type: "text",
+				Text: newMessage,
+			},
+		},
+	})
+
+	// Prepare the request body
+	reqBody := MessagesAPIRequest{
+		Model:     modelName,
+		MaxTokens: 8192, // Default max tokens
+		System:    systemPrompt,
+		Messages:  inputMessages,
+	}
+	p.applySampling(&reqBody)
+	p.applyPrefill(&reqBody)
+
+	return reqBody, nil
+}
@@ -0,0 +1,139 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+// testConfig is a Config for tests that points the provider at a local server
+type testConfig struct {
+	baseURL string
+}
+
+func (c *testConfig) GetModel() string {
+	return "anthropic:claude-3-5-sonnet"
+}
+
+func (c *testConfig) GetBaseURL(provider string) (string, error) {
+	return c.baseURL, nil
+}
+
+func (c *testConfig) GetToken(provider string) (string, error) {
+	return "test-token", nil
+}
+
+func (c *testConfig) FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func TestChatWithHistoryPrefill(t *testing.T) {
+	var body MessagesAPIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"\"answer\": 42}"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	if err := llmc.SetPrefill(p, "{ "); err != nil {
+		t.Fatalf("SetPrefill() error = %v", err)
+	}
+	history := []llmc.Message{{Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello"}}
+	response, err := p.ChatWithHistory("", history, "Reply in JSON")
+	if err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+	if response != `"answer": 42}` {
+		t.Errorf("ChatWithHistory() = %q, want the continuation only", response)
+	}
+
+	if len(body.Messages) != 4 {
+		t.Fatalf("request has %d messages, want 4: %+v", len(body.Messages), body.Messages)
+	}
+	last := body.Messages[3]
+	if last.Role != "assistant" || len(last.Content) != 1 || last.Content[0].Text != "{" {
+		t.Errorf("last request message = %+v, want an assistant turn with the prefill (trailing whitespace removed)", last)
+	}
+	if body.Messages[2].Role != "user" || body.Messages[2].Content[0].Text != "Reply in JSON" {
+		t.Errorf("request message 2 = %+v, want the new user message", body.Messages[2])
+	}
+
+	// Without a prefill the request ends with the user message
+	p.SetPrefill("")
+	if _, err := p.ChatWithHistory("", nil, "Reply in JSON"); err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+	if n := len(body.Messages); n != 1 || body.Messages[0].Role != "user" {
+		t.Errorf("request messages = %+v, want only the user message", body.Messages)
+	}
+}
+
+func TestLastUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":20,"output_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	if _, err := p.Chat("hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	want := llmc.Usage{InputTokens: 20, OutputTokens: 5, TotalTokens: 25}
+	if got := p.LastUsage(); got == nil || *got != want {
+		t.Errorf("LastUsage() = %v, want %v", got, want)
+	}
+}
+
+func TestChatRetriesOverloaded(t *testing.T) {
+	saved := httpretry.CurrentPolicy()
+	httpretry.Configure(httpretry.Policy{MaxRetries: 0, OverloadedRetries: 2, Backoff: time.Millisecond})
+	defer httpretry.Configure(saved)
+
+	var attempts, overloaded int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&attempts, 1) <= atomic.LoadInt32(&overloaded) {
+			w.WriteHeader(httpretry.StatusOverloaded)
+			w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+
+	// 529 then 200: the retry succeeds
+	atomic.StoreInt32(&overloaded, 1)
+	response, err := p.Chat("hello")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if response != "ok" || atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Chat() = %q after %d attempts, want ok after 2", response, attempts)
+	}
+
+	// Still overloaded when the retries are used up
+	atomic.StoreInt32(&attempts, 0)
+	atomic.StoreInt32(&overloaded, 10)
+	_, err = p.Chat("hello")
+	if err == nil {
+		t.Fatal("Chat() error = nil, want an overloaded error")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3 (1 + 2 retries)", got)
+	}
+	if llmc.ErrorKindOf(err) != llmc.ErrorKindUnavailable || !strings.Contains(err.Error(), "still overloaded after 2 retries") {
+		t.Errorf("Chat() error = %v (kind %v), want an unavailable error reporting the retries", err, llmc.ErrorKindOf(err))
+	}
+}
@@ -0,0 +1,162 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+// MessagesStreamEvent represents an event in a streaming Messages API response
+type MessagesStreamEvent struct {
+	Type    string               `json:"type"`              // e.g., "message_start", "content_block_delta", "message_stop"
+	Message *MessagesAPIResponse `json:"message,omitempty"` // Initial message with input usage (message_start)
+	Delta   *StreamDelta         `json:"delta,omitempty"`   // Text chunk or stop reason (content_block_delta, message_delta)
+	Usage   *Usage               `json:"usage,omitempty"`   // Output usage (message_delta)
+	Error   *APIError            `json:"error,omitempty"`   // Error (error)
+}
+
+// StreamDelta represents the delta of a content block or message
+type StreamDelta struct {
+	Type       string `json:"type,omitempty"` // "text_delta" for content blocks
+	Text       string `json:"text,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+}
+
+// ChatStream sends a conversation history with a new message to Anthropic's Messages API
+// and streams the response text through onEvent
+func (p *Provider) ChatStream(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string, onEvent func(llmc.StreamEvent) error) (string, error) {
+	// Prepare the request body
+	reqBody, err := p.newHistoryRequest(systemPrompt, messages, newMessage)
+	if err != nil {
+		return "", err
+	}
+	reqBody.Stream = true
+
+	// Convert request body to JSON
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	// Get token for Anthropic
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Get base URL for Anthropic
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	setAuthHeader(req, token)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+
+	// Send request
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for error response
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var errResp MessagesAPIResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			message := fmt.Sprintf("API error: %s", errResp.Error.Message)
+			if p.debug {
+				message = fmt.Sprintf("API error [%s]: %s (HTTP %d)", errResp.Error.Type, errResp.Error.Message, resp.StatusCode)
+			}
+			return "", llmc.NewAPIError(ProviderName, resp.StatusCode, errResp.Error.Type, message)
+		}
+
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		if p.debug {
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+		}
+		return "", llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of an empty stream
+	if err := llmc.NonJSONResponseError(resp, nil, p.debug); err != nil {
+		return "", err
+	}
+
+	// Read events
+	var text strings.Builder
+	var usage llmc.Usage
+	var stopReason string
+	completed := false
+	err = llmc.ReadSSE(resp.Body, func(_ string, data string) error {
+		var event MessagesStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			if p.debug {
+				return fmt.Errorf("failed to parse stream event: %v\nRaw event: %s", err, data)
+			}
+			return fmt.Errorf("failed to parse stream event. Use --verbose for details")
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				usage.InputTokens = event.Message.Usage.InputTokens
+				usage.OutputTokens = event.Message.Usage.OutputTokens
+			}
+
+		case "content_block_delta":
+			if event.Delta != nil && event.Delta.Type == "text_delta" {
+				text.WriteString(event.Delta.Text)
+				return onEvent(llmc.StreamEvent{Type: llmc.StreamEventDelta, Text: event.Delta.Text})
+			}
+
+		case "message_delta":
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				stopReason = event.Delta.StopReason
+			}
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+
+		case "message_stop":
+			completed = true
+			usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+			return onEvent(llmc.StreamEvent{Type: llmc.StreamEventDone, Usage: &usage, FinishReason: stopReason})
+
+		case "error":
+			if event.Error != nil {
+				return llmc.NewAPIError(ProviderName, 0, event.Error.Type, fmt.Sprintf("API error: %s", event.Error.Message))
+			}
+			return fmt.Errorf("API request failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !completed {
+		return "", fmt.Errorf("stream ended before the response was completed")
+	}
+
+	p.lastUsage = &usage
+	return text.String(), nil
+}
@@ -0,0 +1,199 @@
+package azureopenai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/chatcompletions"
+)
+
+const (
+	ProviderName      = "azure"
+	DefaultAPIVersion = "2024-10-21"
+)
+
+// Config defines the configuration interface for Azure OpenAI provider.
+// The base URL is the endpoint of the Azure OpenAI resource.
+type Config interface {
+	GetModel() string
+	GetBaseURL(provider string) (string, error)
+	GetToken(provider string) (string, error)
+	GetRoleMap(provider string) map[string]string
+	GetAPIVersion(provider string) string
+}
+
+// Provider implements the llmc.Provider interface for Azure OpenAI
+type Provider struct {
+	config           Config
+	webSearchEnabled bool
+	debug            bool
+	timeout          time.Duration // HTTP request timeout (0 = no timeout)
+	sampling         llmc.SamplingParams
+	lastUsage        *llmc.Usage // Token usage of the last successful request
+}
+
+// NewProvider creates a new Azure OpenAI provider instance
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config:           config,
+		webSearchEnabled: false,
+		debug:            false,
+	}
+}
+
+// SetWebSearch enables or disables web search
+// Note: web search is not supported by the Azure OpenAI provider; requests fail while it is enabled
+func (p *Provider) SetWebSearch(enabled bool) {
+	p.webSearchEnabled = enabled
+}
+
+// SetIgnoreWebSearchErrors is a no-op for Azure OpenAI (not applicable)
+func (p *Provider) SetIgnoreWebSearchErrors(enabled bool) {
+	// Not applicable for Azure OpenAI
+}
+
+// SetDebug enables or disables debug mode
+func (p *Provider) SetDebug(enabled bool) {
+	p.debug = enabled
+}
+
+// SetTimeout sets the HTTP request timeout (0 = no timeout)
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// SetSampling sets the sampling parameters
+func (p *Provider) SetSampling(params llmc.SamplingParams) {
+	p.sampling = params
+}
+
+// LastUsage returns the token usage of the last successful request (nil if not reported)
+func (p *Provider) LastUsage() *llmc.Usage {
+	return p.lastUsage
+}
+
+// endpointURL returns the URL of path under the endpoint of the resource, with the
+// configured api-version as its query
+func (p *Provider) endpointURL(path string) (string, error) {
+	endpoint, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base URL: %w", err)
+	}
+	apiVersion := p.config.GetAPIVersion(ProviderName)
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+	return strings.TrimSuffix(endpoint, "/") + path + "?" + url.Values{"api-version": {apiVersion}}.Encode(), nil
+}
+
+// HealthCheck confirms that the endpoint is reachable and the key is accepted by
+// listing the models of the resource, without generating any content
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	modelsURL, err := p.endpointURL("/openai/models")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", modelsURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("api-key", token)
+
+	// No retries: a health check should report the first failure
+	resp, err := chatcompletions.HTTPClient(p.timeout, p.debug).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return chatcompletions.APIError(ProviderName, resp.StatusCode, body, p.debug)
+	}
+	return llmc.NonJSONResponseError(resp, body, p.debug)
+}
+
+// ListModels is not supported: requests are sent to deployments, whose names are
+// chosen when they are created and cannot be listed with the API key
+func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
+	return nil, fmt.Errorf("listing models is not supported by Azure OpenAI provider; use the name of a deployment (azure:<deployment>)")
+}
+
+// Chat sends a message to the Chat Completions API of the deployment and returns the response
+func (p *Provider) Chat(message string) (string, error) {
+	return p.ChatContext(context.Background(), message)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (p *Provider) ChatContext(ctx context.Context, message string) (string, error) {
+	return p.ChatWithHistoryContext(ctx, "", nil, message)
+}
+
+// ChatWithHistory sends a conversation history with a new message to the Chat Completions API
+func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return p.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (p *Provider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	// Check if web search is enabled (not supported by Azure OpenAI)
+	if p.webSearchEnabled {
+		return "", fmt.Errorf("web search is not supported by Azure OpenAI provider")
+	}
+
+	// Extract deployment name from provider:deployment format
+	_, deployment, err := llmc.ParseModelString(p.config.GetModel())
+	if err != nil {
+		return "", fmt.Errorf("invalid model format: %w", err)
+	}
+
+	// Convert request body to JSON; the model is selected by the deployment in the URL
+	reqBody := chatcompletions.NewRequest("", systemPrompt, messages, newMessage, p.sampling, p.config.GetRoleMap(ProviderName))
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	// Get API key for Azure OpenAI
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	chatURL, err := p.endpointURL("/openai/deployments/" + url.PathEscape(deployment) + "/chat/completions")
+	if err != nil {
+		return "", err
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", chatURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("api-key", token)
+
+	// Send request and parse the response
+	response, usage, err := chatcompletions.Send(chatcompletions.HTTPClient(p.timeout, p.debug), req, ProviderName, p.debug)
+	if err != nil {
+		return "", err
+	}
+	p.lastUsage = usage
+	return response, nil
+}
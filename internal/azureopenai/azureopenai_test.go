@@ -0,0 +1,128 @@
+package azureopenai
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/chatcompletions"
+)
+
+// testConfig is a Config for tests that points the provider at a local server
+type testConfig struct {
+	endpoint   string
+	apiVersion string
+}
+
+func (c *testConfig) GetModel() string {
+	return "azure:my-gpt-4o"
+}
+
+func (c *testConfig) GetBaseURL(provider string) (string, error) {
+	return c.endpoint, nil
+}
+
+func (c *testConfig) GetToken(provider string) (string, error) {
+	return "test-key", nil
+}
+
+func (c *testConfig) GetRoleMap(provider string) map[string]string {
+	return nil
+}
+
+func (c *testConfig) GetAPIVersion(provider string) string {
+	return c.apiVersion
+}
+
+func TestChatWithHistory(t *testing.T) {
+	var body chatcompletions.Request
+	var path, apiVersion, apiKey, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiVersion = r.URL.Path, r.URL.Query().Get("api-version")
+		apiKey, auth = r.Header.Get("api-key"), r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"message":{"role":"assistant","content":"It is 10."},"finish_reason":"stop"}],"usage":{"prompt_tokens":30,"completion_tokens":4,"total_tokens":34}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{endpoint: server.URL + "/", apiVersion: "2024-06-01"})
+	temperature := 0.3
+	p.SetSampling(llmc.SamplingParams{Temperature: &temperature})
+	history := []llmc.Message{
+		{Role: "user", Content: "What is 2+3?"},
+		{Role: "assistant", Content: "5"},
+	}
+	response, err := p.ChatWithHistory("You are a calculator.", history, "And times 2?")
+	if err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+	if response != "It is 10." {
+		t.Errorf("ChatWithHistory() = %q, want the content of the first choice", response)
+	}
+
+	if path != "/openai/deployments/my-gpt-4o/chat/completions" || apiVersion != "2024-06-01" {
+		t.Errorf("request path = %q, api-version = %q, want the chat completions of the deployment", path, apiVersion)
+	}
+	if apiKey != "test-key" || auth != "" {
+		t.Errorf("api-key = %q, Authorization = %q, want only the api-key header", apiKey, auth)
+	}
+	want := chatcompletions.Request{
+		Messages: []chatcompletions.Message{
+			{Role: "system", Content: "You are a calculator."},
+			{Role: "user", Content: "What is 2+3?"},
+			{Role: "assistant", Content: "5"},
+			{Role: "user", Content: "And times 2?"},
+		},
+		Temperature: &temperature,
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request = %+v, want %+v", body, want)
+	}
+
+	wantUsage := llmc.Usage{InputTokens: 30, OutputTokens: 4, TotalTokens: 34}
+	if got := p.LastUsage(); got == nil || *got != wantUsage {
+		t.Errorf("LastUsage() = %v, want %v", got, wantUsage)
+	}
+}
+
+func TestDefaultAPIVersion(t *testing.T) {
+	var apiVersion string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiVersion = r.URL.Query().Get("api-version")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{endpoint: server.URL})
+	if _, err := p.Chat("hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if apiVersion != DefaultAPIVersion {
+		t.Errorf("api-version = %q, want %q", apiVersion, DefaultAPIVersion)
+	}
+}
+
+func TestChatError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"DeploymentNotFound","message":"The API deployment for this resource does not exist."}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{endpoint: server.URL})
+	_, err := p.Chat("hello")
+	var apiErr *llmc.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Chat() error = %v, want an APIError with HTTP 404", err)
+	}
+	if err.Error() != "API error: The API deployment for this resource does not exist." {
+		t.Errorf("Chat() error = %q, want the message of the response", err)
+	}
+}
@@ -0,0 +1,425 @@
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+const (
+	ProviderName   = "cohere"
+	DefaultBaseURL = "https://api.cohere.com/v1"
+	DefaultModel   = "command-r-plus"
+)
+
+// Roles of the chat history in Cohere's Chat API
+const (
+	RoleUser    = "USER"
+	RoleChatbot = "CHATBOT"
+)
+
+// ChatAPIRequest represents the request body for Cohere's Chat API.
+// Unlike the other APIs, the new message is sent separately from the history.
+type ChatAPIRequest struct {
+	Model       string        `json:"model"`
+	Message     string        `json:"message"`                // New user message
+	Preamble    string        `json:"preamble,omitempty"`     // System prompt (optional)
+	ChatHistory []ChatMessage `json:"chat_history,omitempty"` // Earlier turns (optional)
+
+	Temperature *float64 `json:"temperature,omitempty"`
+	P           *float64 `json:"p,omitempty"` // Top-p
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// ChatMessage represents a turn of the chat history
+type ChatMessage struct {
+	Role    string `json:"role"` // RoleUser or RoleChatbot
+	Message string `json:"message"`
+}
+
+// ChatAPIResponse represents the response from Cohere's Chat API
+type ChatAPIResponse struct {
+	Text         string `json:"text"`
+	GenerationID string `json:"generation_id"`
+	FinishReason string `json:"finish_reason"`
+	Meta         *Meta  `json:"meta,omitempty"`
+}
+
+// Meta contains information about a response, including the billed tokens
+type Meta struct {
+	BilledUnits *BilledUnits `json:"billed_units,omitempty"`
+}
+
+// BilledUnits represents the tokens billed for a response
+type BilledUnits struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// ErrorResponse represents an error returned by the API
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// ModelsAPIResponse represents the response from Cohere's models endpoint
+type ModelsAPIResponse struct {
+	Models []ModelData `json:"models"`
+}
+
+// ModelData represents a single model in the API response
+type ModelData struct {
+	Name          string   `json:"name"`
+	Endpoints     []string `json:"endpoints"`
+	ContextLength int      `json:"context_length"`
+}
+
+// Config defines the configuration interface for Cohere provider
+type Config interface {
+	GetModel() string
+	GetBaseURL(provider string) (string, error)
+	GetToken(provider string) (string, error)
+}
+
+// Provider implements the llmc.Provider interface for Cohere
+type Provider struct {
+	config           Config
+	webSearchEnabled bool
+	debug            bool
+	timeout          time.Duration // HTTP request timeout (0 = no timeout)
+	sampling         llmc.SamplingParams
+	lastUsage        *llmc.Usage // Token usage of the last successful request
+}
+
+// NewProvider creates a new Cohere provider instance
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config:           config,
+		webSearchEnabled: false,
+		debug:            false,
+	}
+}
+
+// SetWebSearch enables or disables web search
+// Note: web search is not supported by the Cohere provider; requests fail while it is enabled
+func (p *Provider) SetWebSearch(enabled bool) {
+	p.webSearchEnabled = enabled
+}
+
+// SetIgnoreWebSearchErrors is a no-op for Cohere (not applicable)
+func (p *Provider) SetIgnoreWebSearchErrors(enabled bool) {
+	// Not applicable for Cohere
+}
+
+// SetDebug enables or disables debug mode
+func (p *Provider) SetDebug(enabled bool) {
+	p.debug = enabled
+}
+
+// SetTimeout sets the HTTP request timeout (0 = no timeout)
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// SetSampling sets the sampling parameters
+func (p *Provider) SetSampling(params llmc.SamplingParams) {
+	p.sampling = params
+}
+
+// LastUsage returns the token usage of the last successful request (nil if not reported)
+func (p *Provider) LastUsage() *llmc.Usage {
+	return p.lastUsage
+}
+
+// httpClient returns an HTTP client configured with the provider's timeout.
+// It uses the shared transport so that connections are reused across requests.
+// In debug mode, the timing of each request is printed.
+func (p *Provider) httpClient() *http.Client {
+	client := &http.Client{Timeout: p.timeout, Transport: llmc.ProviderTransport()}
+	if p.debug {
+		client.Transport = llmc.NewTimingTransport(llmc.ProviderTransport(), func(t llmc.RequestTiming) {
+			fmt.Fprintf(os.Stderr, "Timing: %s\n", t)
+		})
+	}
+	return client
+}
+
+// HealthCheck confirms that the API is reachable and the token is accepted by
+// fetching a single model, without generating any content
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models?page_size=1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// No retries: a health check should report the first failure
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return p.apiError(resp.StatusCode, body)
+	}
+	return llmc.NonJSONResponseError(resp, body, p.debug)
+}
+
+// ListModels returns the models that support the Chat API
+func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
+	// Get token for Cohere
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Get base URL for Cohere
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// Create HTTP request (only models available for the chat endpoint)
+	req, err := http.NewRequest("GET", baseURL+"/models?endpoint=chat&page_size=1000", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// Send request
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
+	if err != nil {
+		var timeoutErr *httpretry.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return nil, fmt.Errorf("failed to connect to API: %w", err)
+		}
+		if p.debug {
+			return nil, fmt.Errorf("failed to connect to API: %v", err)
+		}
+		return nil, fmt.Errorf("failed to connect to API. Use --verbose for details")
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	// Check for error response
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.apiError(resp.StatusCode, body)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return nil, err
+	}
+
+	// Parse response
+	var result ModelsAPIResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		if p.debug {
+			return nil, fmt.Errorf("failed to parse API response: %v\nRaw response: %s", err, string(body))
+		}
+		return nil, fmt.Errorf("failed to parse API response. Use --verbose for details")
+	}
+
+	// Convert to ModelInfo format
+	models := make([]llmc.ModelInfo, 0, len(result.Models))
+	for _, model := range result.Models {
+		models = append(models, llmc.ModelInfo{
+			ID:            model.Name,
+			IsDefault:     false, // Set by caller
+			ContextWindow: model.ContextLength,
+		})
+	}
+
+	// Sort models by ID (descending order)
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].ID > models[j].ID
+	})
+
+	return models, nil
+}
+
+// Chat sends a message to Cohere's Chat API and returns the response
+func (p *Provider) Chat(message string) (string, error) {
+	return p.ChatContext(context.Background(), message)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (p *Provider) ChatContext(ctx context.Context, message string) (string, error) {
+	return p.ChatWithHistoryContext(ctx, "", nil, message)
+}
+
+// ChatWithHistory sends a conversation history with a new message to Cohere's Chat API
+func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return p.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (p *Provider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	reqBody, err := p.newChatRequest(systemPrompt, messages, newMessage)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert request body to JSON
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	// Get token for Cohere
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Get base URL for Cohere
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// Send request
+	resp, err := httpretry.Do(p.httpClient(), req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %v", err)
+	}
+
+	// Check for error response
+	if resp.StatusCode != http.StatusOK {
+		return "", p.apiError(resp.StatusCode, body)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", err
+	}
+
+	// Parse response
+	var result ChatAPIResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		if p.debug {
+			return "", fmt.Errorf("failed to parse API response: %v\nRaw response: %s", err, string(body))
+		}
+		return "", fmt.Errorf("failed to parse API response. Use --verbose for details")
+	}
+
+	if result.Text == "" {
+		if p.debug {
+			return "", fmt.Errorf("API returned empty response (generation_id=%s, finish_reason=%s)\nRaw response: %s",
+				result.GenerationID, result.FinishReason, string(body))
+		}
+		return "", fmt.Errorf("API returned empty response. Use --verbose for details")
+	}
+
+	p.lastUsage = result.Meta.toUsage()
+	return result.Text, nil
+}
+
+// newChatRequest builds a Chat API request from a conversation history and a new message
+func (p *Provider) newChatRequest(systemPrompt string, messages []llmc.Message, newMessage string) (ChatAPIRequest, error) {
+	// Check if web search is enabled (not supported by Cohere)
+	if p.webSearchEnabled {
+		return ChatAPIRequest{}, fmt.Errorf("web search is not supported by Cohere provider")
+	}
+
+	// Extract model name from provider:model format
+	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
+	if err != nil {
+		return ChatAPIRequest{}, fmt.Errorf("invalid model format: %w", err)
+	}
+
+	history := make([]ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		history = append(history, ChatMessage{Role: wireRole(msg.Role), Message: msg.Content})
+	}
+
+	return ChatAPIRequest{
+		Model:       modelName,
+		Message:     newMessage,
+		Preamble:    systemPrompt,
+		ChatHistory: history,
+		Temperature: p.sampling.Temperature,
+		P:           p.sampling.TopP,
+		MaxTokens:   p.sampling.MaxTokens,
+	}, nil
+}
+
+// wireRole maps a session role to the role name of the chat history
+func wireRole(role string) string {
+	if role == "assistant" {
+		return RoleChatbot
+	}
+	return RoleUser
+}
+
+// apiError converts an error response to an APIError, using the message of the
+// response body if it has one
+func (p *Provider) apiError(status int, body []byte) error {
+	var errResp ErrorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+		message := fmt.Sprintf("API error: %s", errResp.Message)
+		if p.debug {
+			message = fmt.Sprintf("API error: %s (HTTP %d)", errResp.Message, status)
+		}
+		return llmc.NewAPIError(ProviderName, status, "", message)
+	}
+
+	message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", status)
+	if p.debug {
+		message = fmt.Sprintf("API request failed (HTTP %d): %s", status, string(body))
+	}
+	return llmc.NewAPIError(ProviderName, status, "", message)
+}
+
+// toUsage converts the billed tokens of a response (nil if not reported)
+func (m *Meta) toUsage() *llmc.Usage {
+	if m == nil || m.BilledUnits == nil {
+		return nil
+	}
+	u := m.BilledUnits
+	return &llmc.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, TotalTokens: u.InputTokens + u.OutputTokens}
+}
@@ -0,0 +1,135 @@
+package cohere
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+// testConfig is a Config for tests that points the provider at a local server
+type testConfig struct {
+	baseURL string
+}
+
+func (c *testConfig) GetModel() string {
+	return "cohere:command-r"
+}
+
+func (c *testConfig) GetBaseURL(provider string) (string, error) {
+	return c.baseURL, nil
+}
+
+func (c *testConfig) GetToken(provider string) (string, error) {
+	return "test-token", nil
+}
+
+func TestChatWithHistoryMapping(t *testing.T) {
+	var body ChatAPIRequest
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"It is 10.","generation_id":"gen-1","finish_reason":"COMPLETE","meta":{"billed_units":{"input_tokens":30,"output_tokens":4}}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	temperature := 0.3
+	p.SetSampling(llmc.SamplingParams{Temperature: &temperature})
+	history := []llmc.Message{
+		{Role: "user", Content: "What is 2+3?"},
+		{Role: "assistant", Content: "5"},
+	}
+	response, err := p.ChatWithHistory("You are a calculator.", history, "And times 2?")
+	if err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+	if response != "It is 10." {
+		t.Errorf("ChatWithHistory() = %q, want the text of the response", response)
+	}
+
+	if path != "/chat" || auth != "Bearer test-token" {
+		t.Errorf("request path = %q, authorization = %q, want /chat with a bearer token", path, auth)
+	}
+	want := ChatAPIRequest{
+		Model:    "command-r",
+		Message:  "And times 2?",
+		Preamble: "You are a calculator.",
+		ChatHistory: []ChatMessage{
+			{Role: RoleUser, Message: "What is 2+3?"},
+			{Role: RoleChatbot, Message: "5"},
+		},
+		Temperature: &temperature,
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request = %+v, want %+v", body, want)
+	}
+
+	wantUsage := llmc.Usage{InputTokens: 30, OutputTokens: 4, TotalTokens: 34}
+	if got := p.LastUsage(); got == nil || *got != wantUsage {
+		t.Errorf("LastUsage() = %v, want %v", got, wantUsage)
+	}
+
+	// A one-shot Chat sends no history or preamble
+	body = ChatAPIRequest{}
+	if _, err := p.Chat("hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if body.Message != "hello" || body.ChatHistory != nil || body.Preamble != "" {
+		t.Errorf("Chat() request = %+v, want only the message", body)
+	}
+}
+
+func TestChatError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"invalid api token"}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	_, err := p.Chat("hello")
+	var apiErr *llmc.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Chat() error = %v, want an APIError with HTTP 401", err)
+	}
+	if llmc.ErrorKindOf(err) != llmc.ErrorKindAuth {
+		t.Errorf("ErrorKindOf() = %v, want ErrorKindAuth", llmc.ErrorKindOf(err))
+	}
+	if err.Error() != "API error: invalid api token" {
+		t.Errorf("Chat() error = %q, want the message of the response", err)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("endpoint")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"models":[{"name":"command-r","endpoints":["chat"],"context_length":128000},{"name":"command-r-plus","endpoints":["chat"]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	models, err := p.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if query != "chat" {
+		t.Errorf("request endpoint filter = %q, want chat", query)
+	}
+	want := []llmc.ModelInfo{
+		{ID: "command-r-plus"},
+		{ID: "command-r", ContextWindow: 128000},
+	}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("ListModels() = %+v, want %+v", models, want)
+	}
+}
@@ -2,15 +2,19 @@ package gemini
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
 )
 
 const (
@@ -37,14 +41,32 @@ type GeminiModelData struct {
 	Name                       string   `json:"name"`
 	DisplayName                string   `json:"displayName"`
 	Description                string   `json:"description"`
+	InputTokenLimit            int      `json:"inputTokenLimit"`
 	SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
 }
 
+// GeminiErrorResponse represents an error returned by the API
+type GeminiErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"` // e.g. INVALID_ARGUMENT, PERMISSION_DENIED
+	} `json:"error"`
+}
+
 // GeminiRequest represents the request body for Gemini's generate content API
 type GeminiRequest struct {
 	Contents          []GeminiContent          `json:"contents"`
 	SystemInstruction *GeminiSystemInstruction `json:"system_instruction,omitempty"`
 	Tools             []GeminiTool             `json:"tools,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig  `json:"generationConfig,omitempty"`
+}
+
+// GeminiGenerationConfig represents sampling parameters for Gemini
+type GeminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
 }
 
 // GeminiSystemInstruction represents system instruction for Gemini
@@ -77,6 +99,22 @@ type GeminiGoogleSearch struct {
 type GeminiResponse struct {
 	Candidates        []GeminiCandidate        `json:"candidates"`
 	GroundingMetadata *GeminiGroundingMetadata `json:"groundingMetadata,omitempty"`
+	UsageMetadata     *GeminiUsageMetadata     `json:"usageMetadata,omitempty"`
+}
+
+// GeminiUsageMetadata represents token usage in the API response
+type GeminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// toUsage converts the usage of a response (nil if not reported)
+func (u *GeminiUsageMetadata) toUsage() *llmc.Usage {
+	if u == nil {
+		return nil
+	}
+	return &llmc.Usage{InputTokens: u.PromptTokenCount, OutputTokens: u.CandidatesTokenCount, TotalTokens: u.TotalTokenCount}
 }
 
 // GeminiCandidate represents a candidate response
@@ -143,6 +181,9 @@ type Provider struct {
 	config           Config
 	webSearchEnabled bool
 	debug            bool
+	timeout          time.Duration // HTTP request timeout (0 = no timeout)
+	sampling         llmc.SamplingParams
+	lastUsage        *llmc.Usage // Token usage of the last successful request
 }
 
 // NewProvider creates a new Gemini provider instance
@@ -169,6 +210,84 @@ func (p *Provider) SetDebug(enabled bool) {
 	p.debug = enabled
 }
 
+// SetTimeout sets the HTTP request timeout (0 = no timeout)
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// SetSampling sets the sampling parameters
+func (p *Provider) SetSampling(params llmc.SamplingParams) {
+	p.sampling = params
+}
+
+// generationConfig returns the generation config for the sampling parameters (nil if none is set)
+func (p *Provider) generationConfig() *GeminiGenerationConfig {
+	if p.sampling.IsZero() {
+		return nil
+	}
+	return &GeminiGenerationConfig{
+		Temperature:     p.sampling.Temperature,
+		TopP:            p.sampling.TopP,
+		MaxOutputTokens: p.sampling.MaxTokens,
+	}
+}
+
+// LastUsage returns the token usage of the last successful request (nil if not reported)
+func (p *Provider) LastUsage() *llmc.Usage {
+	return p.lastUsage
+}
+
+// httpClient returns an HTTP client configured with the provider's timeout.
+// It uses the shared transport so that connections are reused across requests.
+// In debug mode, the timing of each request is printed.
+func (p *Provider) httpClient() *http.Client {
+	client := &http.Client{Timeout: p.timeout, Transport: llmc.ProviderTransport()}
+	if p.debug {
+		client.Transport = llmc.NewTimingTransport(llmc.ProviderTransport(), func(t llmc.RequestTiming) {
+			fmt.Fprintf(os.Stderr, "Timing: %s\n", t)
+		})
+	}
+	return client
+}
+
+// HealthCheck confirms that the API is reachable and the token is accepted by
+// fetching a single model, without generating any content
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models?pageSize=1&key="+token, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	// No retries: a health check should report the first failure
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		if p.debug {
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+		}
+		return llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+	return llmc.NonJSONResponseError(resp, body, p.debug)
+}
+
 // ListModels returns the list of supported models from the API
 func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 	// Get token for Gemini
@@ -193,9 +312,13 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 	}
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
+		var timeoutErr *httpretry.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return nil, fmt.Errorf("failed to connect to API: %w", err)
+		}
 		if p.debug {
 			return nil, fmt.Errorf("failed to connect to API: %v", err)
 		}
@@ -211,10 +334,16 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return nil, fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return nil, fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return nil, llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return nil, err
 	}
 
 	// Parse response
@@ -246,9 +375,10 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 		// If no description available, leave empty
 
 		models = append(models, llmc.ModelInfo{
-			ID:          id,
-			Description: description,
-			IsDefault:   false, // Set by caller
+			ID:            id,
+			Description:   description,
+			IsDefault:     false, // Set by caller
+			ContextWindow: model.InputTokenLimit,
 		})
 	}
 
@@ -272,7 +402,12 @@ func contains(slice []string, item string) bool {
 
 // Chat sends a message to Gemini's API and returns the response
 func (p *Provider) Chat(message string) (string, error) {
-	response, retry, err := p.sendRequest(message, p.webSearchEnabled)
+	return p.ChatContext(context.Background(), message)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (p *Provider) ChatContext(ctx context.Context, message string) (string, error) {
+	response, retry, err := p.sendRequest(ctx, message, p.webSearchEnabled)
 
 	// If web search was enabled but returned empty response
 	if retry && p.webSearchEnabled {
@@ -284,7 +419,7 @@ func (p *Provider) Chat(message string) (string, error) {
 
 // sendRequest sends a request to Gemini's API and returns the response
 // Returns: (response text, should retry without web search, error)
-func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bool, error) {
+func (p *Provider) sendRequest(ctx context.Context, message string, enableWebSearch bool) (string, bool, error) {
 	// Prepare the request body
 	reqBody := GeminiRequest{
 		Contents: []GeminiContent{
@@ -296,6 +431,7 @@ func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bo
 				},
 			},
 		},
+		GenerationConfig: p.generationConfig(),
 	}
 
 	// Add Google Search tool if enabled
@@ -334,7 +470,7 @@ func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bo
 		baseURL = DefaultBaseURL
 	}
 	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", baseURL, modelName, token)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", false, fmt.Errorf("error creating request: %v", err)
 	}
@@ -343,10 +479,10 @@ func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bo
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
-		return "", false, fmt.Errorf("error sending request: %v", err)
+		return "", false, fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -358,10 +494,7 @@ func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bo
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
-		if p.debug {
-			return "", false, fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
-		}
-		return "", false, fmt.Errorf("API error: %s", string(body))
+		return "", false, p.apiError(resp.StatusCode, body)
 	}
 
 	// Debug: print raw response
@@ -369,6 +502,11 @@ func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bo
 		fmt.Fprintf(os.Stderr, "Raw API response: %s\n", string(body))
 	}
 
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", false, err
+	}
+
 	// Parse response
 	var result GeminiResponse
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -425,11 +563,17 @@ func (p *Provider) sendRequest(message string, enableWebSearch bool) (string, bo
 		}
 	}
 
+	p.lastUsage = result.UsageMetadata.toUsage()
 	return responseText, shouldRetry, nil
 }
 
 // ChatWithHistory sends a conversation history with a new message to Gemini's API
 func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return p.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (p *Provider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
 	// Convert messages to GeminiContent array
 	contents := make([]GeminiContent, 0, len(messages)+1)
 	for _, msg := range messages {
@@ -452,7 +596,8 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 
 	// Prepare the request body
 	reqBody := GeminiRequest{
-		Contents: contents,
+		Contents:         contents,
+		GenerationConfig: p.generationConfig(),
 	}
 
 	// Add system instruction if provided
@@ -498,7 +643,7 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 		baseURL = DefaultBaseURL
 	}
 	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", baseURL, modelName, token)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %v", err)
 	}
@@ -507,10 +652,10 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %v", err)
+		return "", fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -522,10 +667,7 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
-		if p.debug {
-			return "", fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
-		}
-		return "", fmt.Errorf("API error: %s", string(body))
+		return "", p.apiError(resp.StatusCode, body)
 	}
 
 	// Debug: print raw response
@@ -533,6 +675,11 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 		fmt.Fprintf(os.Stderr, "Raw API response: %s\n", string(body))
 	}
 
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", err
+	}
+
 	// Parse response
 	var result GeminiResponse
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -592,6 +739,7 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 		}
 	}
 
+	p.lastUsage = result.UsageMetadata.toUsage()
 	return responseText, nil
 }
 
@@ -618,3 +766,19 @@ func extractGroundingCitations(metadata *GeminiGroundingMetadata) string {
 
 	return strings.Join(citations, "\n")
 }
+
+// apiError converts an error response to an APIError. The raw response body, which
+// may quote the prompt, is only included in debug mode; otherwise the message of
+// the response is used if it has one.
+func (p *Provider) apiError(status int, body []byte) error {
+	var errResp GeminiErrorResponse
+	parsed := json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != ""
+	switch {
+	case p.debug:
+		return llmc.NewAPIError(ProviderName, status, errResp.Error.Status, fmt.Sprintf("API error (HTTP %d): %s", status, string(body)))
+	case parsed:
+		return llmc.NewAPIError(ProviderName, status, errResp.Error.Status, fmt.Sprintf("API error: %s", errResp.Error.Message))
+	default:
+		return llmc.NewAPIError(ProviderName, status, "", fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", status))
+	}
+}
@@ -0,0 +1,82 @@
+package gemini
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+// testConfig is a Config for tests that points the provider at a local server
+type testConfig struct {
+	baseURL string
+}
+
+func (c *testConfig) GetModel() string {
+	return "gemini:gemini-2.0-flash"
+}
+
+func (c *testConfig) GetBaseURL(provider string) (string, error) {
+	return c.baseURL, nil
+}
+
+func (c *testConfig) GetToken(provider string) (string, error) {
+	return "test-key", nil
+}
+
+func TestListModels(t *testing.T) {
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.URL.Query().Get("key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"models":[
+			{"name":"models/gemini-1.5-flash","displayName":"Gemini 1.5 Flash","inputTokenLimit":1000000,"supportedGenerationMethods":["generateContent","countTokens"]},
+			{"name":"models/text-embedding-004","displayName":"Text Embedding 004","supportedGenerationMethods":["embedContent"]},
+			{"name":"models/gemini-2.0-flash","displayName":"Gemini 2.0 Flash","description":"Fast and versatile","inputTokenLimit":1048576,"supportedGenerationMethods":["generateContent"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	models, err := p.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if path != "/models" || key != "test-key" {
+		t.Errorf("request path = %q, key = %q, want /models with the API key", path, key)
+	}
+
+	// Only generateContent models, without the "models/" prefix, sorted by ID (descending)
+	want := []llmc.ModelInfo{
+		{ID: "gemini-2.0-flash", Description: "Fast and versatile", ContextWindow: 1048576},
+		{ID: "gemini-1.5-flash", Description: "Gemini 1.5 Flash", ContextWindow: 1000000},
+	}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("ListModels() = %+v, want %+v", models, want)
+	}
+}
+
+func TestChatErrorHidesBodyWithoutDebug(t *testing.T) {
+	const body = `{"error":{"code":400,"message":"Invalid value at 'contents'","status":"INVALID_ARGUMENT","details":["secret prompt text"]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	_, err := p.Chat("secret prompt text")
+	if err == nil || err.Error() != "API error: Invalid value at 'contents'" {
+		t.Errorf("Chat() error = %v, want only the message of the response", err)
+	}
+
+	p.SetDebug(true)
+	_, err = p.Chat("secret prompt text")
+	if err == nil || !strings.Contains(err.Error(), "secret prompt text") || !strings.Contains(err.Error(), "HTTP 400") {
+		t.Errorf("Chat() error in debug mode = %v, want the raw response", err)
+	}
+}
@@ -0,0 +1,272 @@
+// Package cassette records HTTP requests to providers and their responses to a file
+// and replays them, so that the full request flow can run without a live API call.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/fsutil"
+)
+
+// secretQueryParams are the URL query parameters that carry credentials (e.g. the Gemini API key)
+var secretQueryParams = []string{"key", "api_key", "token"}
+
+// Cassette is a list of recorded request/response pairs
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request. Credentials are redacted;
+// headers are not recorded since they carry the API tokens.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body"`
+}
+
+// Response is a recorded HTTP response. Credentials in the body are redacted.
+type Response struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize cassette: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder is an http.RoundTripper that sends requests with its base transport
+// and saves every request/response pair to a cassette file as soon as the response
+// body has been read. Credentials in URLs and bodies are redacted.
+type Recorder struct {
+	base     http.RoundTripper
+	path     string
+	redactor *llmc.Redactor
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a Recorder that writes to path (replacing an existing file).
+// base can be nil to send requests with the shared provider transport.
+func NewRecorder(path string, base http.RoundTripper) (*Recorder, error) {
+	redactor, err := llmc.NewRedactor(nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{base: base, path: path, redactor: redactor, cassette: Cassette{Interactions: []Interaction{}}}
+	// Create the file up front so that an unwritable path is reported before any request
+	if err := r.cassette.Save(path); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RoundTrip sends the request and records it with its response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	base := r.base
+	if base == nil {
+		// Looked up per request since the shared transport is configured after the flags are parsed
+		base = llmc.SharedTransport()
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Request{
+		Method: req.Method,
+		URL:    r.redactURL(req.URL),
+		Body:   r.redactText(string(reqBody)),
+	}
+	// Record the response once it has been read, so that streamed responses are not delayed
+	resp.Body = &teeBody{ReadCloser: resp.Body, done: func(body []byte) {
+		r.add(Interaction{
+			Request: recorded,
+			Response: Response{
+				StatusCode:  resp.StatusCode,
+				ContentType: resp.Header.Get("Content-Type"),
+				Body:        r.redactText(string(body)),
+			},
+		})
+	}}
+	return resp, nil
+}
+
+// add appends an interaction and saves the cassette
+func (r *Recorder) add(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.cassette.Save(r.path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// redactURL returns u as a string with credential query parameters and other secrets redacted
+func (r *Recorder) redactURL(u *url.URL) string {
+	return redactURL(u, r.redactor)
+}
+
+// redactText redacts secrets in text
+func (r *Recorder) redactText(text string) string {
+	redacted, _ := r.redactor.Redact(text)
+	return redacted
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette without
+// a network call. Each recorded interaction is used once, in order, for a request
+// with the same method, URL and body.
+type Replayer struct {
+	redactor *llmc.Redactor
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer creates a Replayer for the cassette at path
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := llmc.NewRedactor(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{redactor: redactor, cassette: c, used: make([]bool, len(c.Interactions))}, nil
+}
+
+// RoundTrip returns the recorded response for the request
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	// Requests are compared in their recorded (redacted) form
+	method := req.Method
+	reqURL := redactURL(req.URL, r.redactor)
+	reqBody, _ := r.redactor.Redact(string(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		recorded := interaction.Request
+		if r.used[i] || recorded.Method != method || recorded.URL != reqURL || recorded.Body != reqBody {
+			continue
+		}
+		r.used[i] = true
+
+		header := make(http.Header)
+		if interaction.Response.ContentType != "" {
+			header.Set("Content-Type", interaction.Response.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded response in cassette for %s %s", method, reqURL)
+}
+
+// readRequestBody reads the request body and replaces it so that it can still be sent
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// redactURL returns u as a string with credential query parameters replaced and
+// other secrets redacted by redactor
+func redactURL(u *url.URL, redactor *llmc.Redactor) string {
+	redacted := *u
+	query := redacted.Query()
+	for _, param := range secretQueryParams {
+		if query.Has(param) {
+			query.Set(param, llmc.RedactedText)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	text, _ := redactor.Redact(redacted.String())
+	return text
+}
+
+// teeBody keeps a copy of what is read and calls done once with it,
+// when the body has been read to the end or closed
+type teeBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b.buf.Bytes()) })
+	}
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+	return err
+}
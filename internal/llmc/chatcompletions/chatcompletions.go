@@ -0,0 +1,181 @@
+// Package chatcompletions implements the request and response format of the OpenAI
+// compatible Chat Completions API, shared by the providers that use it (Azure OpenAI,
+// OpenRouter). The providers differ in their URLs and authentication headers.
+package chatcompletions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+// Request represents the request body of the Chat Completions API
+type Request struct {
+	Model    string    `json:"model,omitempty"` // Empty when the model is selected by the URL (Azure deployments)
+	Messages []Message `json:"messages"`
+
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// Message represents a message in the request or the response
+type Message struct {
+	Role    string `json:"role"` // system, user or assistant
+	Content string `json:"content"`
+}
+
+// Response represents the response from the Chat Completions API
+type Response struct {
+	ID      string   `json:"id"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   *Usage   `json:"usage,omitempty"`
+}
+
+// Choice represents a generated message of the response
+type Choice struct {
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// Usage represents the token usage of a response
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ErrorResponse represents an error returned by the API.
+// The code is a string for Azure OpenAI and a number for OpenRouter.
+type ErrorResponse struct {
+	Error struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+	} `json:"error"`
+}
+
+// NewRequest builds a request from a conversation history and a new message.
+// Message roles are sent as mapped by roleMap (nil = unchanged).
+func NewRequest(model, systemPrompt string, messages []llmc.Message, newMessage string, sampling llmc.SamplingParams, roleMap map[string]string) Request {
+	wireRole := func(role string) string {
+		if mapped, ok := roleMap[role]; ok {
+			return mapped
+		}
+		return role
+	}
+
+	chatMessages := make([]Message, 0, len(messages)+2)
+	if systemPrompt != "" {
+		chatMessages = append(chatMessages, Message{Role: wireRole("system"), Content: systemPrompt})
+	}
+	for _, msg := range messages {
+		chatMessages = append(chatMessages, Message{Role: wireRole(msg.Role), Content: msg.Content})
+	}
+	chatMessages = append(chatMessages, Message{Role: wireRole("user"), Content: newMessage})
+
+	return Request{
+		Model:       model,
+		Messages:    chatMessages,
+		Temperature: sampling.Temperature,
+		TopP:        sampling.TopP,
+		MaxTokens:   sampling.MaxTokens,
+	}
+}
+
+// HTTPClient returns an HTTP client with the given timeout (0 = no timeout).
+// It uses the shared transport so that connections are reused across requests.
+// In debug mode, the timing of each request is printed.
+func HTTPClient(timeout time.Duration, debug bool) *http.Client {
+	client := &http.Client{Timeout: timeout, Transport: llmc.ProviderTransport()}
+	if debug {
+		client.Transport = llmc.NewTimingTransport(llmc.ProviderTransport(), func(t llmc.RequestTiming) {
+			fmt.Fprintf(os.Stderr, "Timing: %s\n", t)
+		})
+	}
+	return client
+}
+
+// Send sends a Chat Completions request of provider with retries and returns the
+// text of the response and its token usage (nil if not reported).
+// In debug mode, errors include the raw response body.
+func Send(client *http.Client, req *http.Request, provider string, debug bool) (string, *llmc.Usage, error) {
+	resp, err := httpretry.Do(client, req)
+	if err != nil {
+		return "", nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, APIError(provider, resp.StatusCode, body, debug)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, debug); err != nil {
+		return "", nil, err
+	}
+
+	var result Response
+	if err := json.Unmarshal(body, &result); err != nil {
+		if debug {
+			return "", nil, fmt.Errorf("failed to parse API response: %v\nRaw response: %s", err, string(body))
+		}
+		return "", nil, fmt.Errorf("failed to parse API response. Use --verbose for details")
+	}
+
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		if debug {
+			return "", nil, fmt.Errorf("API returned empty response (id=%s)\nRaw response: %s", result.ID, string(body))
+		}
+		return "", nil, fmt.Errorf("API returned empty response. Use --verbose for details")
+	}
+
+	return result.Choices[0].Message.Content, result.Usage.toUsage(), nil
+}
+
+// APIError converts an error response of provider to an APIError, using the message
+// of the response body if it has one
+func APIError(provider string, status int, body []byte, debug bool) error {
+	var errResp ErrorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != "" {
+		message := fmt.Sprintf("API error: %s", errResp.Error.Message)
+		if debug {
+			message = fmt.Sprintf("API error: %s (HTTP %d)", errResp.Error.Message, status)
+		}
+		return llmc.NewAPIError(provider, status, errResp.code(), message)
+	}
+
+	message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", status)
+	if debug {
+		message = fmt.Sprintf("API request failed (HTTP %d): %s", status, string(body))
+	}
+	return llmc.NewAPIError(provider, status, "", message)
+}
+
+// code returns the error code if it is a string (numeric codes only repeat the HTTP status)
+func (e ErrorResponse) code() string {
+	var code string
+	if json.Unmarshal(e.Error.Code, &code) != nil {
+		return ""
+	}
+	return code
+}
+
+// toUsage converts the token usage of a response (nil if not reported)
+func (u *Usage) toUsage() *llmc.Usage {
+	if u == nil {
+		return nil
+	}
+	return &llmc.Usage{InputTokens: u.PromptTokens, OutputTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+}
@@ -0,0 +1,94 @@
+package chatcompletions
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+func TestNewRequest(t *testing.T) {
+	history := []llmc.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+	temp := 0.5
+
+	req := NewRequest("", "be brief", history, "bye", llmc.SamplingParams{Temperature: &temp}, map[string]string{"assistant": "model"})
+	want := []Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "hi"},
+		{Role: "model", Content: "hello"},
+		{Role: "user", Content: "bye"},
+	}
+	if !reflect.DeepEqual(req.Messages, want) {
+		t.Errorf("Messages = %v, want %v", req.Messages, want)
+	}
+	if req.Temperature == nil || *req.Temperature != 0.5 {
+		t.Errorf("Temperature = %v, want 0.5", req.Temperature)
+	}
+
+	// Without a model (Azure deployments), the field is left out of the request
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"model":`) {
+		t.Errorf("request %s has a model, want none", data)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantType string
+		wantMsg  string
+	}{
+		{name: "string code", body: `{"error":{"code":"DeploymentNotFound","message":"no deployment"}}`, wantType: "DeploymentNotFound", wantMsg: "API error: no deployment"},
+		{name: "numeric code", body: `{"error":{"code":404,"message":"no model"}}`, wantMsg: "API error: no model"},
+		{name: "no message", body: `oops`, wantMsg: "API request failed (HTTP 404). Use --verbose for details"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var apiErr *llmc.APIError
+			if !errors.As(APIError("azure", http.StatusNotFound, []byte(tt.body), false), &apiErr) {
+				t.Fatal("APIError() is not an *llmc.APIError")
+			}
+			if apiErr.Type != tt.wantType || apiErr.Message != tt.wantMsg || apiErr.Provider != "azure" {
+				t.Errorf("APIError() = %+v, want type %q and message %q", apiErr, tt.wantType, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/empty" {
+			w.Write([]byte(`{"id":"x","choices":[]}`))
+			return
+		}
+		w.Write([]byte(`{"id":"x","choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("POST", server.URL+"/chat/completions", nil)
+	response, usage, err := Send(server.Client(), req, "openrouter", false)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if response != "hi" {
+		t.Errorf("response = %q, want %q", response, "hi")
+	}
+	if usage == nil || *usage != (llmc.Usage{InputTokens: 3, OutputTokens: 1, TotalTokens: 4}) {
+		t.Errorf("usage = %v, want 3 input, 1 output", usage)
+	}
+
+	req, _ = http.NewRequest("POST", server.URL+"/empty", nil)
+	if _, _, err := Send(server.Client(), req, "openrouter", false); err == nil || !strings.Contains(err.Error(), "empty response") {
+		t.Errorf("Send() error = %v, want an empty response error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package llmc
+
+import "strings"
+
+// ExtractCodeBlocks returns the contents of the fenced code blocks (``` or ~~~)
+// in a Markdown text, without the fences and info strings.
+// A block that is not closed extends to the end of the text.
+func ExtractCodeBlocks(text string) []string {
+	var blocks []string
+	var current []string
+	var fence string // Opening fence of the current block ("" = outside a block)
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if f := codeFence(trimmed); f != "" {
+				fence = f
+				current = nil
+			}
+			continue
+		}
+
+		// A closing fence uses the same character, is at least as long and has no info string
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			fence = ""
+			continue
+		}
+		current = append(current, strings.TrimSuffix(line, "\r"))
+	}
+
+	if fence != "" {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// codeFence returns the fence that opens a code block on line, or "" if there is none
+func codeFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
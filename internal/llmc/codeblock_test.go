@@ -0,0 +1,48 @@
+package llmc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "single block",
+			text: "Here is the script:\n\n```bash\n#!/bin/sh\necho hello\n```\n\nRun it with sh.",
+			want: []string{"#!/bin/sh\necho hello"},
+		},
+		{
+			name: "multiple blocks",
+			text: "First:\n```go\nfmt.Println(1)\n```\nThen:\n~~~\nfmt.Println(2)\n~~~\n",
+			want: []string{"fmt.Println(1)", "fmt.Println(2)"},
+		},
+		{
+			name: "no block",
+			text: "Just prose, with `inline code`.",
+			want: nil,
+		},
+		{
+			name: "nested fence inside longer fence",
+			text: "````markdown\n```go\nx := 1\n```\n````",
+			want: []string{"```go\nx := 1\n```"},
+		},
+		{
+			name: "unclosed block",
+			text: "```python\nprint('hi')",
+			want: []string{"print('hi')"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractCodeBlocks(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCodeBlocks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,24 +2,61 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
 	"github.com/spf13/viper"
 )
 
 // Config holds the configuration for the LLM provider
 type Config struct {
 	Model                   string   `toml:"model" mapstructure:"model"` // Format: "provider:model" (e.g., "openai:gpt-4")
+	FallbackModels          []string `toml:"fallback_models" mapstructure:"fallback_models"`
+	DefaultProvider         string   `toml:"default_provider" mapstructure:"default_provider"` // Provider of model names given without one (empty = a provider is required)
 	OpenAIBaseURL           string   `toml:"openai_base_url" mapstructure:"openai_base_url"`
 	OpenAIToken             string   `toml:"openai_token" mapstructure:"openai_token"`
 	GeminiBaseURL           string   `toml:"gemini_base_url" mapstructure:"gemini_base_url"`
 	GeminiToken             string   `toml:"gemini_token" mapstructure:"gemini_token"`
 	AnthropicBaseURL        string   `toml:"anthropic_base_url" mapstructure:"anthropic_base_url"`
 	AnthropicToken          string   `toml:"anthropic_token" mapstructure:"anthropic_token"`
+	CohereBaseURL           string   `toml:"cohere_base_url" mapstructure:"cohere_base_url"`
+	CohereToken             string   `toml:"cohere_token" mapstructure:"cohere_token"`
+	OpenRouterBaseURL       string   `toml:"openrouter_base_url" mapstructure:"openrouter_base_url"`
+	OpenRouterToken         string   `toml:"openrouter_token" mapstructure:"openrouter_token"`
+	AzureEndpoint           string   `toml:"azure_endpoint" mapstructure:"azure_endpoint"`       // Endpoint of the Azure OpenAI resource
+	AzureAPIVersion         string   `toml:"azure_api_version" mapstructure:"azure_api_version"` // api-version of Azure OpenAI requests
+	AzureToken              string   `toml:"azure_token" mapstructure:"azure_token"`             // API key of the Azure OpenAI resource
+	CustomBaseURL           string   `toml:"custom_base_url" mapstructure:"custom_base_url"`     // Base URL of the custom provider
+	CustomToken             string   `toml:"custom_token" mapstructure:"custom_token"`
+	APIStyle                string   `toml:"api_style" mapstructure:"api_style"` // API used by the custom provider (openai, anthropic or gemini)
 	PromptDirs              []string `toml:"prompt_dirs" mapstructure:"prompt_dirs"`
 	EnableWebSearch         bool     `toml:"enable_web_search" mapstructure:"enable_web_search"`
 	SessionMessageThreshold int      `toml:"session_message_threshold" mapstructure:"session_message_threshold"` // 0 = disabled
 	SessionRetentionDays    int      `toml:"session_retention_days" mapstructure:"session_retention_days"`       // Number of days to retain sessions (default: 30)
+	MaxSessions             int      `toml:"max_sessions" mapstructure:"max_sessions"`                           // Number of sessions kept by sessions gc (0 = no limit)
+	TimeFormat              string   `toml:"time_format" mapstructure:"time_format"`                             // Preset name (rfc3339, iso8601, ...) or Go layout
+	Timezone                string   `toml:"timezone" mapstructure:"timezone"`                                   // IANA timezone name (empty = local time)
+	UserLabel               string   `toml:"user_label" mapstructure:"user_label"`                               // Label for user messages in output
+	AssistantLabel          string   `toml:"assistant_label" mapstructure:"assistant_label"`                     // Label for assistant messages in output
+	Temperature             *float64 `toml:"temperature" mapstructure:"temperature"`                             // Sampling temperature (unset = provider default)
+	TopP                    *float64 `toml:"top_p" mapstructure:"top_p"`                                         // Nucleus sampling (unset = provider default)
+	MaxTokens               *int     `toml:"max_tokens" mapstructure:"max_tokens"`                               // Maximum output tokens (unset = provider default)
+	Redact                  bool     `toml:"redact" mapstructure:"redact"`                                       // Redact credentials in outgoing messages
+	RedactPatterns          []string `toml:"redact_patterns" mapstructure:"redact_patterns"`                     // Additional regexes to redact (enables redaction)
+	MaxIdleConns            int      `toml:"max_idle_conns" mapstructure:"max_idle_conns"`                       // Maximum idle (keep-alive) connections (0 = no limit)
+	IdleConnTimeout         string   `toml:"idle_conn_timeout" mapstructure:"idle_conn_timeout"`                 // How long idle connections are kept open, e.g. "90s" (0 = no limit)
+	MaxDisplayLines         int      `toml:"max_display_lines" mapstructure:"max_display_lines"`                 // Lines of a response shown at once in interactive mode (0 = no limit)
+	HistorySize             int      `toml:"history_size" mapstructure:"history_size"`                           // Entries kept in the interactive input history file (0 = history is not saved)
+	MaxRetries              int      `toml:"max_retries" mapstructure:"max_retries"`                             // Retries after rate limit (429), server (5xx) and connection errors
+	TimeoutRetries          int      `toml:"timeout_retries" mapstructure:"timeout_retries"`                     // Retries after request timeouts
+	OverloadedRetries       int      `toml:"overloaded_retries" mapstructure:"overloaded_retries"`               // Retries after overloaded (529) responses, e.g. Anthropic's overloaded_error
+	RequestTimeoutSeconds   int      `toml:"request_timeout_seconds" mapstructure:"request_timeout_seconds"`     // Timeout of each provider request (0 = no timeout)
+
+	RoleMap map[string]map[string]string `toml:"role_map" mapstructure:"role_map"` // Per provider, message roles sent as different role strings (e.g. assistant = "bot")
+
+	rawTokens map[string]string // Token values before environment variable expansion (for diagnostics)
 }
 
 // GetModel returns the model name
@@ -27,6 +64,12 @@ func (c *Config) GetModel() string {
 	return c.Model
 }
 
+// QualifyModel returns model in "provider:model" format, using default_provider as
+// the provider of a model name given without one
+func (c *Config) QualifyModel(model string) string {
+	return llmc.QualifyModelString(model, c.DefaultProvider)
+}
+
 // GetProvider extracts provider name from the model string
 func (c *Config) GetProvider() (string, error) {
 	provider, _, err := llmc.ParseModelString(c.Model)
@@ -39,20 +82,161 @@ func (c *Config) GetModelName() (string, error) {
 	return model, err
 }
 
+// TransportOptions returns the HTTP connection settings of the configuration
+func (c *Config) TransportOptions() llmc.TransportOptions {
+	timeout, _ := c.idleConnTimeout()
+	return llmc.TransportOptions{
+		MaxIdleConns:    c.MaxIdleConns,
+		IdleConnTimeout: timeout,
+	}
+}
+
+// RequestTimeout returns the timeout of each provider request (0 = no timeout)
+func (c *Config) RequestTimeout() time.Duration {
+	return time.Duration(c.RequestTimeoutSeconds) * time.Second
+}
+
+// RetryPolicy returns the request retry settings of the configuration
+func (c *Config) RetryPolicy() httpretry.Policy {
+	return httpretry.Policy{
+		MaxRetries:        c.MaxRetries,
+		TimeoutRetries:    c.TimeoutRetries,
+		OverloadedRetries: c.OverloadedRetries,
+	}
+}
+
+// idleConnTimeout parses IdleConnTimeout (empty = no limit)
+func (c *Config) idleConnTimeout() (time.Duration, error) {
+	if c.IdleConnTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.IdleConnTimeout)
+	if err != nil {
+		return 0, err
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return timeout, nil
+}
+
+// SamplingParams returns the sampling parameters set in the configuration
+func (c *Config) SamplingParams() llmc.SamplingParams {
+	return llmc.SamplingParams{
+		Temperature: c.Temperature,
+		TopP:        c.TopP,
+		MaxTokens:   c.MaxTokens,
+	}
+}
+
+// RedactionEnabled reports whether outgoing messages are redacted
+// (enabled by redact = true or by setting redact_patterns)
+func (c *Config) RedactionEnabled() bool {
+	return c.Redact || len(c.RedactPatterns) > 0
+}
+
+// isAPIStyle reports whether style is one of llmc.APIStyles
+func isAPIStyle(style string) bool {
+	for _, s := range llmc.APIStyles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// roleMapProviders lists the providers whose requests role_map applies to
+var roleMapProviders = []string{"openai", "azure", llmc.CustomProviderName}
+
+// validateRoleMap checks that role_map only maps message roles of providers it applies to.
+// Roles of the custom provider are only mapped with the OpenAI API (apiStyle "openai").
+func validateRoleMap(roleMap map[string]map[string]string, apiStyle string) error {
+	for provider, roles := range roleMap {
+		supported := false
+		for _, p := range roleMapProviders {
+			if p == provider {
+				supported = true
+			}
+		}
+		if !supported {
+			return llmc.NewConfigError("invalid role_map provider '%s' (must be one of: %s)", provider, strings.Join(roleMapProviders, ", "))
+		}
+		if provider == llmc.CustomProviderName && apiStyle != "openai" {
+			return llmc.NewConfigError("role_map.%s requires api_style = \"openai\" (got '%s'; roles are only mapped for the OpenAI API)", provider, apiStyle)
+		}
+		for role, wireRole := range roles {
+			if role != "user" && role != "assistant" {
+				return llmc.NewConfigError("invalid role_map.%s role '%s' (must be user or assistant)", provider, role)
+			}
+			if wireRole == "" {
+				return llmc.NewConfigError("role_map.%s.%s must not be empty", provider, role)
+			}
+		}
+	}
+	return nil
+}
+
+// Default labels for messages in output
+const (
+	DefaultUserLabel      = "You"
+	DefaultAssistantLabel = "Assistant"
+)
+
+// RoleLabel returns the configured output label for a message role ("user" or "assistant")
+func (c *Config) RoleLabel(role string) string {
+	if role == "assistant" {
+		if c.AssistantLabel != "" {
+			return c.AssistantLabel
+		}
+		return DefaultAssistantLabel
+	}
+	if c.UserLabel != "" {
+		return c.UserLabel
+	}
+	return DefaultUserLabel
+}
+
 // NewDefaultConfig returns a new Config with default values
 func NewDefaultConfig(promptDir string) *Config {
 	return &Config{
 		Model:                   "openai:gpt-4.1", // Changed to "provider:model" format
+		FallbackModels:          []string{},
+		DefaultProvider:         "", // Model names must include the provider
 		OpenAIBaseURL:           "https://api.openai.com/v1",
 		OpenAIToken:             "", // No default, use LLMC_OPENAI_TOKEN env var or set in config file
 		GeminiBaseURL:           "https://generativelanguage.googleapis.com/v1beta",
 		GeminiToken:             "", // No default, use LLMC_GEMINI_TOKEN env var or set in config file
 		AnthropicBaseURL:        "https://api.anthropic.com/v1",
 		AnthropicToken:          "", // No default, use LLMC_ANTHROPIC_TOKEN env var or set in config file
+		CohereBaseURL:           "https://api.cohere.com/v1",
+		CohereToken:             "", // No default, use LLMC_COHERE_TOKEN env var or set in config file
+		OpenRouterBaseURL:       "https://openrouter.ai/api/v1",
+		OpenRouterToken:         "", // No default, use LLMC_OPENROUTER_TOKEN env var or set in config file
+		AzureEndpoint:           "", // No default, e.g. https://<resource>.openai.azure.com
+		AzureAPIVersion:         "2024-10-21",
+		AzureToken:              "", // No default, use LLMC_AZURE_TOKEN env var or set in config file
+		CustomBaseURL:           "", // Only used with the custom provider
+		CustomToken:             "",
+		APIStyle:                "",
 		PromptDirs:              []string{promptDir},
 		EnableWebSearch:         false,
 		SessionMessageThreshold: 50, // Default threshold (0 = disabled)
 		SessionRetentionDays:    30, // Default: delete sessions older than 30 days
+		MaxSessions:             0,  // No limit
+		TimeFormat:              DefaultTimeFormat,
+		Timezone:                "", // Default: local time
+		UserLabel:               DefaultUserLabel,
+		AssistantLabel:          DefaultAssistantLabel,
+		Redact:                  false, // Opt-in
+		RedactPatterns:          []string{},
+		MaxIdleConns:            llmc.DefaultTransportOptions.MaxIdleConns,
+		IdleConnTimeout:         llmc.DefaultTransportOptions.IdleConnTimeout.String(),
+		MaxDisplayLines:         0, // No limit
+		HistorySize:             1000,
+		MaxRetries:              3,
+		TimeoutRetries:          0, // Opt-in
+		OverloadedRetries:       3,
+		RequestTimeoutSeconds:   120,
 	}
 }
 
@@ -60,16 +244,44 @@ func NewDefaultConfig(promptDir string) *Config {
 func LoadConfig() (*Config, error) {
 	config := &Config{}
 	if err := viper.Unmarshal(config); err != nil {
-		return nil, fmt.Errorf("error unmarshaling config: %v", err)
+		return nil, llmc.NewConfigError("error unmarshaling config: %v", err)
+	}
+
+	// Keep the original token values to diagnose references to unset environment variables
+	config.rawTokens = map[string]string{
+		"openai":     config.OpenAIToken,
+		"gemini":     config.GeminiToken,
+		"anthropic":  config.AnthropicToken,
+		"cohere":     config.CohereToken,
+		"openrouter": config.OpenRouterToken,
+		"azure":      config.AzureToken,
+		"custom":     config.CustomToken,
 	}
 
 	// Expand environment variables in tokens and base URLs
 	config.OpenAIToken, _ = expandEnvVar(config.OpenAIToken)
 	config.GeminiToken, _ = expandEnvVar(config.GeminiToken)
 	config.AnthropicToken, _ = expandEnvVar(config.AnthropicToken)
+	config.CohereToken, _ = expandEnvVar(config.CohereToken)
+	config.OpenRouterToken, _ = expandEnvVar(config.OpenRouterToken)
+	config.AzureToken, _ = expandEnvVar(config.AzureToken)
+	config.CustomToken, _ = expandEnvVar(config.CustomToken)
 	config.OpenAIBaseURL, _ = expandEnvVar(config.OpenAIBaseURL)
 	config.GeminiBaseURL, _ = expandEnvVar(config.GeminiBaseURL)
 	config.AnthropicBaseURL, _ = expandEnvVar(config.AnthropicBaseURL)
+	config.CohereBaseURL, _ = expandEnvVar(config.CohereBaseURL)
+	config.OpenRouterBaseURL, _ = expandEnvVar(config.OpenRouterBaseURL)
+	config.AzureEndpoint, _ = expandEnvVar(config.AzureEndpoint)
+	config.CustomBaseURL, _ = expandEnvVar(config.CustomBaseURL)
+
+	// Qualify model names given without a provider with default_provider
+	if config.DefaultProvider != "" && !llmc.IsSupportedProvider(config.DefaultProvider) {
+		return nil, llmc.NewConfigError("invalid default_provider '%s' (must be one of: %s)", config.DefaultProvider, strings.Join(llmc.SupportedProviders, ", "))
+	}
+	config.Model = config.QualifyModel(config.Model)
+	for i, fallbackModel := range config.FallbackModels {
+		config.FallbackModels[i] = config.QualifyModel(fallbackModel)
+	}
 
 	// Convert prompt directories to absolute paths
 	for i, promptDir := range config.PromptDirs {
@@ -80,5 +292,68 @@ func LoadConfig() (*Config, error) {
 		config.PromptDirs[i] = absPath
 	}
 
+	// Validate time format and timezone
+	if err := validateTimeFormat(config.TimeFormat); err != nil {
+		return nil, err
+	}
+	if _, err := loadTimezone(config.Timezone); err != nil {
+		return nil, err
+	}
+
+	// Validate sampling parameters
+	if err := config.SamplingParams().Validate(); err != nil {
+		return nil, llmc.NewConfigError("invalid sampling parameter: %v", err)
+	}
+
+	// Validate the API style of the custom provider
+	if config.APIStyle != "" && !isAPIStyle(config.APIStyle) {
+		return nil, llmc.NewConfigError("invalid api_style '%s' (must be one of: %s)", config.APIStyle, strings.Join(llmc.APIStyles, ", "))
+	}
+
+	// Validate connection settings
+	if config.MaxIdleConns < 0 {
+		return nil, llmc.NewConfigError("invalid max_idle_conns %d (must be 0 or greater)", config.MaxIdleConns)
+	}
+	if _, err := config.idleConnTimeout(); err != nil {
+		return nil, llmc.NewConfigError("invalid idle_conn_timeout '%s': %v", config.IdleConnTimeout, err)
+	}
+
+	// Validate session settings
+	if config.MaxSessions < 0 {
+		return nil, llmc.NewConfigError("invalid max_sessions %d (must be 0 or greater)", config.MaxSessions)
+	}
+
+	// Validate retry settings
+	if config.MaxRetries < 0 {
+		return nil, llmc.NewConfigError("invalid max_retries %d (must be 0 or greater)", config.MaxRetries)
+	}
+	if config.TimeoutRetries < 0 {
+		return nil, llmc.NewConfigError("invalid timeout_retries %d (must be 0 or greater)", config.TimeoutRetries)
+	}
+	if config.OverloadedRetries < 0 {
+		return nil, llmc.NewConfigError("invalid overloaded_retries %d (must be 0 or greater)", config.OverloadedRetries)
+	}
+	if config.RequestTimeoutSeconds < 0 {
+		return nil, llmc.NewConfigError("invalid request_timeout_seconds %d (must be 0 or greater)", config.RequestTimeoutSeconds)
+	}
+
+	// Validate display settings
+	if config.MaxDisplayLines < 0 {
+		return nil, llmc.NewConfigError("invalid max_display_lines %d (must be 0 or greater)", config.MaxDisplayLines)
+	}
+	if config.HistorySize < 0 {
+		return nil, llmc.NewConfigError("invalid history_size %d (must be 0 or greater)", config.HistorySize)
+	}
+
+	// Validate role mapping
+	if err := validateRoleMap(config.RoleMap, config.APIStyle); err != nil {
+		return nil, err
+	}
+
+	// Validate redact patterns
+	if _, err := llmc.NewRedactor(config.RedactPatterns); err != nil {
+		return nil, llmc.NewConfigError("%v", err)
+	}
+
 	return config, nil
 }
@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestValidateRoleMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		roleMap  map[string]map[string]string
+		apiStyle string
+		wantErr  bool
+	}{
+		{name: "empty", roleMap: nil},
+		{name: "openai", roleMap: map[string]map[string]string{"openai": {"assistant": "bot"}}},
+		{name: "custom", roleMap: map[string]map[string]string{"custom": {"user": "human", "assistant": "ai"}}, apiStyle: "openai"},
+		{name: "custom with anthropic api_style", roleMap: map[string]map[string]string{"custom": {"assistant": "ai"}}, apiStyle: "anthropic", wantErr: true},
+		{name: "custom with gemini api_style", roleMap: map[string]map[string]string{"custom": {"assistant": "ai"}}, apiStyle: "gemini", wantErr: true},
+		{name: "custom without api_style", roleMap: map[string]map[string]string{"custom": {"assistant": "ai"}}, wantErr: true},
+		{name: "unsupported provider", roleMap: map[string]map[string]string{"gemini": {"assistant": "bot"}}, wantErr: true},
+		{name: "unknown role", roleMap: map[string]map[string]string{"openai": {"system": "developer"}}, wantErr: true},
+		{name: "empty wire role", roleMap: map[string]map[string]string{"openai": {"user": ""}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRoleMap(tt.roleMap, tt.apiStyle)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRoleMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQualifyModel(t *testing.T) {
+	cfg := &Config{DefaultProvider: "openai"}
+	if got := cfg.QualifyModel("gpt-4o"); got != "openai:gpt-4o" {
+		t.Errorf("QualifyModel(gpt-4o) = %q, want openai:gpt-4o", got)
+	}
+	if got := cfg.QualifyModel("gemini:gemini-2.0-flash"); got != "gemini:gemini-2.0-flash" {
+		t.Errorf("QualifyModel(gemini:gemini-2.0-flash) = %q, want the explicit provider kept", got)
+	}
+	if got := (&Config{}).QualifyModel("gpt-4o"); got != "gpt-4o" {
+		t.Errorf("QualifyModel(gpt-4o) without default_provider = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigDirName is the name of the directory with configuration fragments,
+// located next to the user configuration file
+const ConfigDirName = "config.d"
+
+// LoadConfigDir reads the *.toml fragments in dir in lexical order of their file names
+// and merges them, with later fragments overriding earlier ones.
+// It returns the merged settings and the paths of the fragments that were read.
+// A missing directory is not an error.
+func LoadConfigDir(dir string) (map[string]interface{}, []string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing config fragments in %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	settings := make(map[string]interface{})
+	var fragments []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		fragment := make(map[string]interface{})
+		if _, err := toml.DecodeFile(path, &fragment); err != nil {
+			return nil, nil, fmt.Errorf("reading config fragment %s: %w", path, err)
+		}
+		mergeSettings(settings, fragment)
+		fragments = append(fragments, path)
+	}
+
+	return settings, fragments, nil
+}
+
+// mergeSettings merges src into dst, overriding existing values.
+// Tables present in both are merged recursively.
+func mergeSettings(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcTable, srcIsTable := value.(map[string]interface{})
+		dstTable, dstIsTable := dst[key].(map[string]interface{})
+		if srcIsTable && dstIsTable {
+			mergeSettings(dstTable, srcTable)
+			continue
+		}
+		dst[key] = value
+	}
+}
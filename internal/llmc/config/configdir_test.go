@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-machine.toml": "model = \"openai:gpt-4.1\"\ntimezone = \"UTC\"\n",
+		"20-project.toml": "model = \"anthropic:claude-sonnet-4\"\nprompt_dirs = [\"./prompts\"]\n",
+		"05-base.toml":    "model = \"gemini:gemini-2.5-flash\"\nuser_label = \"Me\"\ntimezone = \"Asia/Tokyo\"\n",
+		"notes.txt":       "model = \"ignored\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	settings, fragments, err := LoadConfigDir(dir)
+	if err != nil {
+		t.Fatalf("LoadConfigDir() error = %v", err)
+	}
+
+	wantFragments := []string{
+		filepath.Join(dir, "05-base.toml"),
+		filepath.Join(dir, "10-machine.toml"),
+		filepath.Join(dir, "20-project.toml"),
+	}
+	if !reflect.DeepEqual(fragments, wantFragments) {
+		t.Errorf("LoadConfigDir() fragments = %v, want %v", fragments, wantFragments)
+	}
+
+	want := map[string]interface{}{
+		"model":       "anthropic:claude-sonnet-4", // last fragment wins
+		"timezone":    "UTC",                       // overridden by 10-machine.toml
+		"user_label":  "Me",                        // only set in 05-base.toml
+		"prompt_dirs": []interface{}{"./prompts"},
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("LoadConfigDir() settings = %v, want %v", settings, want)
+	}
+}
+
+func TestLoadConfigDirMissing(t *testing.T) {
+	settings, fragments, err := LoadConfigDir(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("LoadConfigDir() error = %v", err)
+	}
+	if len(settings) != 0 || len(fragments) != 0 {
+		t.Errorf("LoadConfigDir() = %v, %v, want nothing", settings, fragments)
+	}
+}
+
+func TestLoadConfigDirInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.toml"), []byte("model = "), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadConfigDir(dir); err == nil {
+		t.Error("LoadConfigDir() error = nil, want an error for an invalid fragment")
+	}
+}
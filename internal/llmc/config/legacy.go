@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/spf13/viper"
+)
+
+// legacyKeys are the settings of the old single-provider schema, which used one
+// provider, base_url and token instead of per-provider settings and a
+// "provider:model" model string
+var legacyKeys = []string{"provider", "base_url", "token"}
+
+// legacyDefaultProvider is the provider the old schema used when none was set
+const legacyDefaultProvider = "openai"
+
+// MigrateLegacySettings converts the legacy keys in settings to the current schema
+// in place: token and base_url become <provider>_token and <provider>_base_url,
+// a plain model becomes "provider:model", and provider is removed.
+// A per-provider setting that is already present is kept and the legacy value is dropped.
+// It returns a description of each change, or nil when settings has no legacy keys.
+func MigrateLegacySettings(settings map[string]interface{}) ([]string, error) {
+	if !hasLegacyKeys(settings) {
+		return nil, nil
+	}
+
+	model, _ := settings["model"].(string)
+	provider, _ := settings["provider"].(string)
+	if provider == "" {
+		if modelProvider, _, err := llmc.ParseModelString(model); err == nil {
+			provider = modelProvider
+		} else {
+			provider = legacyDefaultProvider
+		}
+	}
+	if !llmc.IsSupportedProvider(provider) {
+		return nil, llmc.NewConfigError("unsupported legacy provider '%s' (supported: %s)", provider, strings.Join(llmc.SupportedProviders, ", "))
+	}
+
+	var changes []string
+	for _, key := range []string{"token", "base_url"} {
+		value, ok := settings[key]
+		if !ok {
+			continue
+		}
+		newKey := provider + "_" + key
+		if _, exists := settings[newKey]; exists {
+			changes = append(changes, fmt.Sprintf("removed %s (%s is already set)", key, newKey))
+		} else {
+			settings[newKey] = value
+			changes = append(changes, fmt.Sprintf("moved %s to %s", key, newKey))
+		}
+		delete(settings, key)
+	}
+
+	if model != "" && !strings.Contains(model, ":") {
+		settings["model"] = llmc.FormatModelString(provider, model)
+		changes = append(changes, fmt.Sprintf("changed model %q to %q", model, settings["model"]))
+	}
+
+	if _, ok := settings["provider"]; ok {
+		delete(settings, "provider")
+		changes = append(changes, fmt.Sprintf("removed provider (now part of model, e.g. \"%s:<model>\")", provider))
+	}
+
+	return changes, nil
+}
+
+// ApplyLegacySettings migrates legacy keys in the loaded configuration (see
+// MigrateLegacySettings) so that LoadConfig sees the current schema.
+// Settings already given in the current schema, in a config file or an
+// environment variable, take precedence over the legacy values.
+// It returns a description of each change, or nil when there are no legacy keys.
+func ApplyLegacySettings() ([]string, error) {
+	settings := make(map[string]interface{})
+	for _, key := range legacyKeys {
+		if viper.IsSet(key) {
+			settings[key] = viper.Get(key)
+		}
+	}
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	if viper.InConfig("model") || os.Getenv("LLMC_MODEL") != "" {
+		settings["model"] = viper.GetString("model")
+	}
+	for _, provider := range llmc.SupportedProviders {
+		for _, key := range []string{provider + "_token", provider + "_base_url"} {
+			if viper.InConfig(key) || os.Getenv("LLMC_"+strings.ToUpper(key)) != "" {
+				settings[key] = viper.Get(key)
+			}
+		}
+	}
+
+	changes, err := MigrateLegacySettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	return changes, nil
+}
+
+// hasLegacyKeys reports whether settings has any key of the legacy schema
+func hasLegacyKeys(settings map[string]interface{}) bool {
+	for _, key := range legacyKeys {
+		if _, ok := settings[key]; ok {
+			return true
+		}
+	}
+	return false
+}
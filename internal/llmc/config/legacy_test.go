@@ -0,0 +1,78 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateLegacySettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    map[string]interface{}
+		want        map[string]interface{}
+		wantChanges int
+		wantErr     bool
+	}{
+		{
+			name:     "current schema is unchanged",
+			settings: map[string]interface{}{"model": "gpt-4", "openai_token": "sk-new"},
+			want:     map[string]interface{}{"model": "gpt-4", "openai_token": "sk-new"},
+		},
+		{
+			name: "provider, token, base_url and plain model",
+			settings: map[string]interface{}{
+				"provider": "gemini",
+				"model":    "gemini-pro",
+				"token":    "g-token",
+				"base_url": "https://gemini.example.com",
+			},
+			want: map[string]interface{}{
+				"model":           "gemini:gemini-pro",
+				"gemini_token":    "g-token",
+				"gemini_base_url": "https://gemini.example.com",
+			},
+			wantChanges: 4,
+		},
+		{
+			name:        "provider taken from model",
+			settings:    map[string]interface{}{"model": "anthropic:claude-3-5-sonnet", "token": "a-token"},
+			want:        map[string]interface{}{"model": "anthropic:claude-3-5-sonnet", "anthropic_token": "a-token"},
+			wantChanges: 1,
+		},
+		{
+			name:        "openai without provider or model",
+			settings:    map[string]interface{}{"token": "sk-old"},
+			want:        map[string]interface{}{"openai_token": "sk-old"},
+			wantChanges: 1,
+		},
+		{
+			name:        "current setting takes precedence",
+			settings:    map[string]interface{}{"provider": "openai", "token": "sk-old", "openai_token": "sk-new"},
+			want:        map[string]interface{}{"openai_token": "sk-new"},
+			wantChanges: 2,
+		},
+		{
+			name:     "unsupported provider",
+			settings: map[string]interface{}{"provider": "unknown", "token": "x"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := MigrateLegacySettings(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateLegacySettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.settings, tt.want) {
+				t.Errorf("MigrateLegacySettings() settings = %v, want %v", tt.settings, tt.want)
+			}
+			if len(changes) != tt.wantChanges {
+				t.Errorf("MigrateLegacySettings() changes = %q, want %d change(s)", changes, tt.wantChanges)
+			}
+		})
+	}
+}
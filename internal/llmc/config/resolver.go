@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/longkey1/llmc/internal/llmc"
 	"github.com/spf13/viper"
 )
 
@@ -14,25 +15,32 @@ import (
 // Returns the expanded value. If the environment variable is not set, returns empty string.
 func expandEnvVar(value string) (string, error) {
 	// Check if it's an environment variable reference
-	if !strings.HasPrefix(value, "$") {
+	name := envVarName(value)
+	if name == "" {
 		// Not an environment variable reference, return as-is
 		return value, nil
 	}
 
-	var envVarName string
+	// Get environment variable value
+	// If not set, return empty string (no error)
+	envValue := os.Getenv(name)
+	return envValue, nil
+}
+
+// envVarName returns the variable name if value is an environment variable reference
+// ($VAR or ${VAR}), or an empty string otherwise
+func envVarName(value string) string {
+	if !strings.HasPrefix(value, "$") {
+		return ""
+	}
+
 	// Support both $VAR and ${VAR} syntax
 	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
 		// Extract variable name from ${VAR} format
-		envVarName = value[2 : len(value)-1]
-	} else {
-		// Extract variable name from $VAR format
-		envVarName = strings.TrimPrefix(value, "$")
+		return value[2 : len(value)-1]
 	}
-
-	// Get environment variable value
-	// If not set, return empty string (no error)
-	envValue := os.Getenv(envVarName)
-	return envValue, nil
+	// Extract variable name from $VAR format
+	return strings.TrimPrefix(value, "$")
 }
 
 // GetBaseURL returns the base URL for the specified provider
@@ -46,13 +54,24 @@ func (c *Config) GetBaseURL(provider string) (string, error) {
 		baseURLValue = c.GeminiBaseURL
 	case "anthropic":
 		baseURLValue = c.AnthropicBaseURL
+	case "cohere":
+		baseURLValue = c.CohereBaseURL
+	case "openrouter":
+		baseURLValue = c.OpenRouterBaseURL
+	case "azure":
+		baseURLValue = c.AzureEndpoint
+	case "custom":
+		baseURLValue = c.CustomBaseURL
 	default:
 		return "", fmt.Errorf("unsupported provider: %s", provider)
 	}
 
 	// Validate that base URL is not empty
+	if baseURLValue == "" && provider == "azure" {
+		return "", llmc.NewConfigError("azure endpoint is not configured. Set it in config file (azure_endpoint) or environment variable (LLMC_AZURE_ENDPOINT)")
+	}
 	if baseURLValue == "" {
-		return "", fmt.Errorf("%s base URL is not configured. Set it in config file (%s_base_url) or environment variable (LLMC_%s_BASE_URL)", provider, provider, strings.ToUpper(provider))
+		return "", llmc.NewConfigError("%s base URL is not configured. Set it in config file (%s_base_url) or environment variable (LLMC_%s_BASE_URL)", provider, provider, strings.ToUpper(provider))
 	}
 
 	return baseURLValue, nil
@@ -69,18 +88,69 @@ func (c *Config) GetToken(provider string) (string, error) {
 		tokenValue = c.GeminiToken
 	case "anthropic":
 		tokenValue = c.AnthropicToken
+	case "cohere":
+		tokenValue = c.CohereToken
+	case "openrouter":
+		tokenValue = c.OpenRouterToken
+	case "azure":
+		tokenValue = c.AzureToken
+	case "custom":
+		tokenValue = c.CustomToken
 	default:
 		return "", fmt.Errorf("unsupported provider: %s", provider)
 	}
 
 	// Validate that token is not empty
 	if tokenValue == "" {
-		return "", fmt.Errorf("%s token is not configured. Set it in config file (%s_token) or environment variable (LLMC_%s_TOKEN)", provider, provider, strings.ToUpper(provider))
+		if name := envVarName(c.rawTokens[provider]); name != "" {
+			return "", llmc.NewConfigError("%s token references environment variable %s, which is not set. Export %s or change %s_token in config file", provider, name, name, provider)
+		}
+		if !TokenRequired(provider) {
+			return "", nil
+		}
+		return "", llmc.NewConfigError("%s token is not configured. Set it in config file (%s_token) or environment variable (LLMC_%s_TOKEN)", provider, provider, strings.ToUpper(provider))
+	}
+
+	// Detect obviously invalid tokens
+	if strings.Contains(tokenValue, "$") {
+		return "", llmc.NewConfigError("%s token looks like an unexpanded environment variable reference (%s). Use \"$VAR\" or \"${VAR}\" as the whole value of %s_token", provider, tokenValue, provider)
+	}
+	if strings.ContainsAny(tokenValue, " \t\r\n") {
+		return "", llmc.NewConfigError("%s token contains whitespace. Check %s_token for extra spaces, a trailing newline, or a \"Bearer \" prefix", provider, provider)
 	}
 
 	return tokenValue, nil
 }
 
+// tokenOptionalProviders lists the providers that can be used without a token,
+// such as a local server (e.g. Ollama) that needs no authentication behind the custom provider
+var tokenOptionalProviders = []string{"custom"}
+
+// TokenRequired reports whether GetToken returns an error when the token of provider is not set
+func TokenRequired(provider string) bool {
+	for _, name := range tokenOptionalProviders {
+		if provider == name {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRoleMap returns the role_map of the specified provider: the role strings to send
+// instead of the message roles. It is empty if the roles are sent as they are.
+func (c *Config) GetRoleMap(provider string) map[string]string {
+	return c.RoleMap[provider]
+}
+
+// GetAPIVersion returns the API version sent with the requests of the specified provider.
+// It is empty for providers whose API is not versioned by a setting.
+func (c *Config) GetAPIVersion(provider string) string {
+	if provider == "azure" {
+		return c.AzureAPIVersion
+	}
+	return ""
+}
+
 // ResolvePath converts a relative path to absolute path if needed
 func ResolvePath(path string) (string, error) {
 	if filepath.IsAbs(path) {
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetTokenDiagnostics(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		rawToken    string
+		want        string
+		wantErrText string
+	}{
+		{
+			name:     "valid token",
+			token:    "sk-test123",
+			rawToken: "sk-test123",
+			want:     "sk-test123",
+		},
+		{
+			name:        "not configured",
+			token:       "",
+			rawToken:    "",
+			wantErrText: "openai token is not configured",
+		},
+		{
+			name:        "reference to unset environment variable",
+			token:       "",
+			rawToken:    "$LLMC_TEST_UNSET_TOKEN",
+			wantErrText: "references environment variable LLMC_TEST_UNSET_TOKEN, which is not set",
+		},
+		{
+			name:        "braced reference to unset environment variable",
+			token:       "",
+			rawToken:    "${LLMC_TEST_UNSET_TOKEN}",
+			wantErrText: "references environment variable LLMC_TEST_UNSET_TOKEN, which is not set",
+		},
+		{
+			name:        "unexpanded reference",
+			token:       "Bearer $OPENAI_API_KEY",
+			rawToken:    "Bearer $OPENAI_API_KEY",
+			wantErrText: "unexpanded environment variable reference",
+		},
+		{
+			name:        "contains whitespace",
+			token:       "sk-test 123",
+			rawToken:    "sk-test 123",
+			wantErrText: "contains whitespace",
+		},
+		{
+			name:        "trailing newline",
+			token:       "sk-test123\n",
+			rawToken:    "sk-test123\n",
+			wantErrText: "contains whitespace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				OpenAIToken: tt.token,
+				rawTokens:   map[string]string{"openai": tt.rawToken},
+			}
+
+			got, err := cfg.GetToken("openai")
+			if tt.wantErrText != "" {
+				if err == nil {
+					t.Fatalf("GetToken() error = nil, want error containing %q", tt.wantErrText)
+				}
+				if !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Errorf("GetToken() error = %q, want it to contain %q", err.Error(), tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetToken() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandEnvVar(t *testing.T) {
+	t.Setenv("LLMC_TEST_TOKEN", "sk-from-env")
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "literal", value: "sk-literal", want: "sk-literal"},
+		{name: "dollar reference", value: "$LLMC_TEST_TOKEN", want: "sk-from-env"},
+		{name: "braced reference", value: "${LLMC_TEST_TOKEN}", want: "sk-from-env"},
+		{name: "unset reference", value: "$LLMC_TEST_UNSET_TOKEN", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := expandEnvVar(tt.value)
+			if got != tt.want {
+				t.Errorf("expandEnvVar(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTokenOptional(t *testing.T) {
+	cfg := &Config{rawTokens: map[string]string{"custom": ""}}
+	got, err := cfg.GetToken("custom")
+	if err != nil {
+		t.Fatalf("GetToken(custom) error = %v, want nil", err)
+	}
+	if got != "" {
+		t.Errorf("GetToken(custom) = %q, want empty", got)
+	}
+
+	cfg = &Config{rawTokens: map[string]string{"custom": "$LLMC_TEST_UNSET_TOKEN"}}
+	if _, err := cfg.GetToken("custom"); err == nil {
+		t.Error("GetToken(custom) error = nil for an unset environment variable reference")
+	}
+
+	if TokenRequired("custom") {
+		t.Error("TokenRequired(custom) = true, want false")
+	}
+	if !TokenRequired("openai") {
+		t.Error("TokenRequired(openai) = false, want true")
+	}
+}
+
+func TestResolveProvider(t *testing.T) {
+	cfg := &Config{
+		OpenAIBaseURL:    "https://openai.example.com",
+		OpenAIToken:      "sk-openai",
+		GeminiBaseURL:    "https://gemini.example.com",
+		GeminiToken:      "gemini-key",
+		AnthropicBaseURL: "https://anthropic.example.com",
+		AnthropicToken:   "sk-ant",
+		AzureEndpoint:    "https://my-resource.openai.azure.com",
+		AzureToken:       "azure-key",
+	}
+
+	tests := []struct {
+		provider    string
+		wantBaseURL string
+		wantToken   string
+		wantErr     bool
+	}{
+		{provider: "openai", wantBaseURL: "https://openai.example.com", wantToken: "sk-openai"},
+		{provider: "gemini", wantBaseURL: "https://gemini.example.com", wantToken: "gemini-key"},
+		{provider: "anthropic", wantBaseURL: "https://anthropic.example.com", wantToken: "sk-ant"},
+		{provider: "azure", wantBaseURL: "https://my-resource.openai.azure.com", wantToken: "azure-key"},
+		{provider: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			baseURL, err := cfg.GetBaseURL(tt.provider)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			token, err := cfg.GetToken(tt.provider)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if baseURL != tt.wantBaseURL || token != tt.wantToken {
+				t.Errorf("resolved (%q, %q), want (%q, %q)", baseURL, token, tt.wantBaseURL, tt.wantToken)
+			}
+		})
+	}
+
+	if _, err := (&Config{}).GetToken("anthropic"); err == nil || !strings.Contains(err.Error(), "LLMC_ANTHROPIC_TOKEN") {
+		t.Errorf("GetToken(anthropic) error = %v, want guidance mentioning LLMC_ANTHROPIC_TOKEN", err)
+	}
+	if _, err := (&Config{}).GetBaseURL("azure"); err == nil || !strings.Contains(err.Error(), "azure_endpoint") {
+		t.Errorf("GetBaseURL(azure) error = %v, want guidance mentioning azure_endpoint", err)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+// DefaultTimeFormat is the layout used when time_format is not set
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
+// timeFormatPresets maps preset names accepted in time_format to Go layouts
+var timeFormatPresets = map[string]string{
+	"default":  DefaultTimeFormat,
+	"iso8601":  "2006-01-02T15:04:05-07:00",
+	"rfc3339":  time.RFC3339,
+	"rfc1123":  time.RFC1123,
+	"kitchen":  time.Kitchen,
+	"datetime": time.DateTime,
+}
+
+// resolveTimeFormat returns the Go layout for a preset name or custom layout
+func resolveTimeFormat(format string) string {
+	if format == "" {
+		return DefaultTimeFormat
+	}
+	if layout, ok := timeFormatPresets[strings.ToLower(format)]; ok {
+		return layout
+	}
+	return format
+}
+
+// validateTimeFormat checks that format is a preset name or a Go layout
+// containing at least one time element
+func validateTimeFormat(format string) error {
+	layout := resolveTimeFormat(format)
+	sample := time.Date(2001, 11, 22, 10, 33, 44, 0, time.UTC)
+	if sample.Format(layout) == layout {
+		return llmc.NewConfigError("invalid time_format '%s' (use a preset such as rfc3339 or iso8601, or a Go layout like \"2006-01-02 15:04\")", format)
+	}
+	return nil
+}
+
+// loadTimezone returns the location for a timezone name ("" or "Local" = local time)
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, llmc.NewConfigError("invalid timezone '%s': %v", name, err)
+	}
+	return loc, nil
+}
+
+// Location returns the configured timezone (local time if it is not set or invalid)
+func (c *Config) Location() *time.Location {
+	loc, err := loadTimezone(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// FormatTime formats t using the configured time_format in the configured timezone
+func (c *Config) FormatTime(t time.Time) string {
+	return t.In(c.Location()).Format(resolveTimeFormat(c.TimeFormat))
+}
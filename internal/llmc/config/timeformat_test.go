@@ -0,0 +1,95 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		timeFormat string
+		timezone   string
+		want       string
+	}{
+		{
+			name:       "default format in UTC",
+			timeFormat: "",
+			timezone:   "UTC",
+			want:       "2025-01-02 03:04:05",
+		},
+		{
+			name:       "rfc3339 preset",
+			timeFormat: "rfc3339",
+			timezone:   "UTC",
+			want:       "2025-01-02T03:04:05Z",
+		},
+		{
+			name:       "iso8601 preset in non-local timezone",
+			timeFormat: "iso8601",
+			timezone:   "Asia/Tokyo",
+			want:       "2025-01-02T12:04:05+09:00",
+		},
+		{
+			name:       "custom layout in non-local timezone",
+			timeFormat: "02/01/2006 15:04 MST",
+			timezone:   "America/New_York",
+			want:       "01/01/2025 22:04 EST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{TimeFormat: tt.timeFormat, Timezone: tt.timezone}
+			if got := cfg.FormatTime(ts); got != tt.want {
+				t.Errorf("FormatTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTimeFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{name: "empty uses default", format: "", wantErr: false},
+		{name: "preset", format: "RFC3339", wantErr: false},
+		{name: "go layout", format: "2006/01/02", wantErr: false},
+		{name: "no layout elements", format: "yyyy-mm-dd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTimeFormat(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTimeFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		wantErr bool
+	}{
+		{name: "empty is local", tz: "", wantErr: false},
+		{name: "Local", tz: "Local", wantErr: false},
+		{name: "IANA name", tz: "Europe/London", wantErr: false},
+		{name: "unknown", tz: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTimezone(tt.tz)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadTimezone(%q) error = %v, wantErr %v", tt.tz, err, tt.wantErr)
+			}
+		})
+	}
+}
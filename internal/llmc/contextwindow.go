@@ -0,0 +1,53 @@
+package llmc
+
+import "strings"
+
+// knownContextWindows lists the context windows (in tokens) of models whose APIs do not report them.
+// Dated and suffixed variants (e.g. "gpt-4o-2024-08-06") match the longest listed model ID they extend.
+var knownContextWindows = map[string]int{
+	// OpenAI
+	"gpt-3.5-turbo": 16385,
+	"gpt-4":         8192,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4o-mini":   128000,
+	"gpt-4.1":       1047576,
+	"gpt-4.1-mini":  1047576,
+	"gpt-4.1-nano":  1047576,
+	"gpt-5":         400000,
+	"gpt-5-mini":    400000,
+	"gpt-5-nano":    400000,
+	"o1":            200000,
+	"o1-mini":       128000,
+	"o3":            200000,
+	"o3-mini":       200000,
+	"o4-mini":       200000,
+
+	// Anthropic
+	"claude-3-haiku":    200000,
+	"claude-3-opus":     200000,
+	"claude-3-5-haiku":  200000,
+	"claude-3-5-sonnet": 200000,
+	"claude-3-7-sonnet": 200000,
+	"claude-sonnet-4":   200000,
+	"claude-opus-4":     200000,
+
+	// Gemini (used when the API does not report inputTokenLimit)
+	"gemini-1.5-flash": 1048576,
+	"gemini-1.5-pro":   2097152,
+	"gemini-2.0-flash": 1048576,
+	"gemini-2.5-flash": 1048576,
+	"gemini-2.5-pro":   1048576,
+}
+
+// KnownContextWindow returns the context window in tokens of the model with the given ID
+// (without provider prefix) from the built-in table, or 0 if it is not known
+func KnownContextWindow(modelID string) int {
+	best, window := "", 0
+	for id, w := range knownContextWindows {
+		if (modelID == id || strings.HasPrefix(modelID, id+"-")) && len(id) > len(best) {
+			best, window = id, w
+		}
+	}
+	return window
+}
@@ -0,0 +1,23 @@
+package llmc
+
+import "testing"
+
+func TestKnownContextWindow(t *testing.T) {
+	tests := map[string]int{
+		"gpt-4o":                     128000,
+		"gpt-4o-2024-08-06":          128000,
+		"gpt-4":                      8192,
+		"gpt-4-0613":                 8192,
+		"gpt-4-turbo-preview":        128000,
+		"gpt-4.1-mini-2025-04-14":    1047576,
+		"gpt-4.5-preview":            0,
+		"claude-3-5-sonnet-20241022": 200000,
+		"o1-mini":                    128000,
+		"unknown-model":              0,
+	}
+	for id, want := range tests {
+		if got := KnownContextWindow(id); got != want {
+			t.Errorf("KnownContextWindow(%q) = %d, want %d", id, got, want)
+		}
+	}
+}
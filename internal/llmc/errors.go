@@ -0,0 +1,156 @@
+package llmc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrorKind classifies errors returned by providers
+type ErrorKind int
+
+const (
+	ErrorKindUnknown        ErrorKind = iota
+	ErrorKindAuth                     // Invalid or missing credentials (HTTP 401/403)
+	ErrorKindRateLimit                // Too many requests (HTTP 429)
+	ErrorKindUnavailable              // Server error or overloaded service (HTTP 5xx)
+	ErrorKindNetwork                  // Connection failure or timeout
+	ErrorKindContentFilter            // Request or response blocked by a content filter
+	ErrorKindInvalidRequest           // Other client errors (HTTP 4xx)
+	ErrorKindConfig                   // Missing or invalid configuration
+	ErrorKindContextLength            // Input exceeds the model's context window
+)
+
+// contextLengthMarkers are lowercase fragments of provider error codes and
+// messages reporting that the input exceeds the model's context window
+var contextLengthMarkers = []string{
+	"context_length_exceeded",              // OpenAI error code
+	"maximum context length",               // OpenAI error message
+	"context window",                       // OpenAI error message
+	"prompt is too long",                   // Anthropic error message
+	"exceeds the maximum number of tokens", // Gemini error message
+}
+
+// APIError represents an error response returned by a provider API.
+// Message is the text shown to the user; StatusCode and Type are kept so that
+// callers can decide how to handle the error (fallback, exit code, etc.).
+type APIError struct {
+	Provider   string    // Provider name (e.g., "openai")
+	StatusCode int       // HTTP status code (0 if the error was reported in a successful response)
+	Type       string    // Provider-specific error type or code (can be empty)
+	Kind       ErrorKind // Error classification
+	Message    string    // Human-readable error message
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// ConfigError represents a missing or invalid configuration value
+type ConfigError struct {
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Message
+}
+
+// NewConfigError creates a ConfigError with a formatted message
+func NewConfigError(format string, a ...interface{}) *ConfigError {
+	return &ConfigError{Message: fmt.Sprintf(format, a...)}
+}
+
+// NewAPIError creates an APIError and classifies it by status code and error type
+func NewAPIError(provider string, statusCode int, errType string, message string) *APIError {
+	return &APIError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Type:       errType,
+		Kind:       classifyAPIError(statusCode, errType, message),
+		Message:    message,
+	}
+}
+
+// classifyAPIError determines the ErrorKind from an HTTP status code, a provider error type
+// and the error message
+func classifyAPIError(statusCode int, errType string, message string) ErrorKind {
+	t := strings.ToLower(errType)
+	m := strings.ToLower(message)
+	for _, marker := range contextLengthMarkers {
+		if strings.Contains(t, marker) || strings.Contains(m, marker) {
+			return ErrorKindContextLength
+		}
+	}
+
+	switch {
+	case strings.Contains(t, "content_filter"), strings.Contains(t, "content_policy"), strings.Contains(t, "safety"):
+		return ErrorKindContentFilter
+	case strings.Contains(t, "authentication"), strings.Contains(t, "permission"), strings.Contains(t, "invalid_api_key"):
+		return ErrorKindAuth
+	case strings.Contains(t, "rate_limit"):
+		return ErrorKindRateLimit
+	case strings.Contains(t, "overloaded"):
+		return ErrorKindUnavailable
+	}
+
+	switch {
+	case statusCode == 401 || statusCode == 403:
+		return ErrorKindAuth
+	case statusCode == 429:
+		return ErrorKindRateLimit
+	case statusCode >= 500:
+		return ErrorKindUnavailable
+	case statusCode >= 400:
+		return ErrorKindInvalidRequest
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// ErrorKindOf returns the classification of err.
+// Network failures wrapped by providers are reported as ErrorKindNetwork.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return ErrorKindUnknown
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind
+	}
+
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return ErrorKindConfig
+	}
+
+	// A cancelled request is neither a network failure nor temporary
+	if errors.Is(err, context.Canceled) {
+		return ErrorKindUnknown
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return ErrorKindNetwork
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorKindNetwork
+	}
+
+	return ErrorKindUnknown
+}
+
+// IsTemporaryError reports whether err is likely to succeed with another
+// attempt or another provider (rate limits, server errors and network failures)
+func IsTemporaryError(err error) bool {
+	switch ErrorKindOf(err) {
+	case ErrorKindRateLimit, ErrorKindUnavailable, ErrorKindNetwork:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,28 @@
+package llmc
+
+import "testing"
+
+func TestNewAPIErrorContextLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		errType    string
+		message    string
+		want       ErrorKind
+	}{
+		{name: "openai code", statusCode: 400, errType: "context_length_exceeded", message: "API request failed (HTTP 400)", want: ErrorKindContextLength},
+		{name: "anthropic message", statusCode: 400, errType: "invalid_request_error", message: "API error: prompt is too long: 210000 tokens > 200000 maximum", want: ErrorKindContextLength},
+		{name: "gemini message", statusCode: 400, message: "API error: The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).", want: ErrorKindContextLength},
+		{name: "other invalid request", statusCode: 400, errType: "invalid_request_error", message: "API error: bad request", want: ErrorKindInvalidRequest},
+		{name: "rate limit", statusCode: 429, message: "API request failed (HTTP 429)", want: ErrorKindRateLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAPIError("test", tt.statusCode, tt.errType, tt.message)
+			if err.Kind != tt.want {
+				t.Errorf("NewAPIError() Kind = %v, want %v", err.Kind, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+// ManifestFileName is the name of the manifest written by WriteAll
+const ManifestFileName = "manifest.json"
+
+// Manifest describes the sessions in an export bundle
+type Manifest struct {
+	ExportedAt time.Time       `json:"exported_at"`
+	Format     Format          `json:"format"`
+	Sessions   []ManifestEntry `json:"sessions"`
+}
+
+// ManifestEntry describes one exported session
+type ManifestEntry struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name,omitempty"`
+	Model        string    `json:"model"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MessageCount int       `json:"message_count"`
+	File         string    `json:"file"` // File name relative to the bundle directory
+}
+
+// WriteAll exports every session into dir, one file per session named as by
+// DefaultFileName (the full session ID is used when two short IDs collide),
+// and writes a manifest of the exported sessions to dir/manifest.json.
+func WriteAll(dir string, sessions []session.Session, format Format, cfg Config) (*Manifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating export directory: %w", err)
+	}
+
+	manifest := &Manifest{ExportedAt: time.Now(), Format: format, Sessions: []ManifestEntry{}}
+	used := make(map[string]bool)
+	for i := range sessions {
+		sess := &sessions[i]
+		name := DefaultFileName(sess, format)
+		if used[name] {
+			name = sess.ID + format.Extension()
+		}
+		used[name] = true
+
+		var buf bytes.Buffer
+		if err := Write(&buf, sess, format, cfg); err != nil {
+			return nil, fmt.Errorf("exporting session %s: %w", sess.GetShortID(), err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("writing export file: %w", err)
+		}
+
+		manifest.Sessions = append(manifest.Sessions, ManifestEntry{
+			ID:           sess.ID,
+			Name:         sess.Name,
+			Model:        sess.Model,
+			CreatedAt:    sess.CreatedAt,
+			UpdatedAt:    sess.UpdatedAt,
+			MessageCount: sess.MessageCount(),
+			File:         name,
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("writing manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// WriteCombinedMarkdown renders all sessions as a single Markdown document,
+// starting with a table of contents that links to each session
+func WriteCombinedMarkdown(w io.Writer, sessions []session.Session, cfg Config) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Sessions\n\n")
+	fmt.Fprintf(&b, "Exported %s (%d sessions)\n\n", cfg.FormatTime(time.Now()), len(sessions))
+	for i := range sessions {
+		sess := &sessions[i]
+		fmt.Fprintf(&b, "- [%s](#session-%s) (%s, %d messages, updated %s)\n",
+			sess.GetDisplayName(), sess.ID, sess.Model, sess.MessageCount(), cfg.FormatTime(sess.UpdatedAt))
+	}
+
+	for i := range sessions {
+		sess := &sessions[i]
+		fmt.Fprintf(&b, "\n---\n\n<a id=\"session-%s\"></a>\n\n", sess.ID)
+		if err := writeMarkdown(&b, sess, cfg); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
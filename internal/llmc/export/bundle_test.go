@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+func TestWriteAll(t *testing.T) {
+	first := newTestSession()
+	second := newTestSession()
+	second.Name = ""
+	sessions := []session.Session{*first, *second}
+
+	dir := filepath.Join(t.TempDir(), "backup")
+	manifest, err := WriteAll(dir, sessions, FormatMarkdown, testConfig{})
+	if err != nil {
+		t.Fatalf("WriteAll() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	want := []string{first.GetShortID() + ".md", second.GetShortID() + ".md", ManifestFileName}
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteAll() files = %v, want %v", got, want)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, first.GetShortID()+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "# greeting\n") {
+		t.Errorf("exported file does not contain the session:\n%s", content)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written Manifest
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if len(written.Sessions) != 2 || written.Format != FormatMarkdown {
+		t.Fatalf("manifest = %+v, want 2 markdown sessions", written)
+	}
+	for i, sess := range sessions {
+		entry := written.Sessions[i]
+		if entry.ID != sess.ID || entry.File != sess.GetShortID()+".md" || entry.MessageCount != 2 {
+			t.Errorf("manifest entry %d = %+v, want session %s in %s.md", i, entry, sess.ID, sess.GetShortID())
+		}
+	}
+	if len(manifest.Sessions) != 2 {
+		t.Errorf("WriteAll() manifest has %d sessions, want 2", len(manifest.Sessions))
+	}
+}
+
+func TestWriteCombinedMarkdown(t *testing.T) {
+	first := newTestSession()
+	second := newTestSession()
+	second.Name = "other"
+
+	var buf bytes.Buffer
+	if err := WriteCombinedMarkdown(&buf, []session.Session{*first, *second}, testConfig{}); err != nil {
+		t.Fatalf("WriteCombinedMarkdown() error = %v", err)
+	}
+	got := buf.String()
+	for _, sess := range []*session.Session{first, second} {
+		for _, want := range []string{
+			"- [" + sess.Name + "](#session-" + sess.ID + ")",
+			`<a id="session-` + sess.ID + `"></a>`,
+			"# " + sess.Name + "\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("WriteCombinedMarkdown() missing %q:\n%s", want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package export renders sessions as Markdown, JSON or HTML documents.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+// Format is an export format
+type Format string
+
+// Supported export formats
+const (
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+	FormatHTML     Format = "html"
+)
+
+// Config defines the display settings used when rendering a session
+type Config interface {
+	RoleLabel(role string) string
+	FormatTime(t time.Time) string
+}
+
+// ParseFormat returns the Format for a name or file extension (e.g., "md", "markdown", "json", "html")
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "json":
+		return FormatJSON, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s (supported: markdown, json, html)", name)
+	}
+}
+
+// Extension returns the file extension for the format (including the dot)
+func (f Format) Extension() string {
+	switch f {
+	case FormatJSON:
+		return ".json"
+	case FormatHTML:
+		return ".html"
+	default:
+		return ".md"
+	}
+}
+
+// DefaultFileName returns the default export file name for a session (e.g., "550e8400.md")
+func DefaultFileName(sess *session.Session, format Format) string {
+	return sess.GetShortID() + format.Extension()
+}
+
+// Write renders sess in the given format to w
+func Write(w io.Writer, sess *session.Session, format Format, cfg Config) error {
+	switch format {
+	case FormatMarkdown:
+		return writeMarkdown(w, sess, cfg)
+	case FormatJSON:
+		return writeJSON(w, sess)
+	case FormatHTML:
+		return writeHTML(w, sess, cfg)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// writeMarkdown renders the session as a Markdown document
+func writeMarkdown(w io.Writer, sess *session.Session, cfg Config) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", sess.GetDisplayName())
+	fmt.Fprintf(&b, "- **Session:** %s\n", sess.ID)
+	fmt.Fprintf(&b, "- **Model:** %s\n", sess.Model)
+	fmt.Fprintf(&b, "- **Created:** %s\n", cfg.FormatTime(sess.CreatedAt))
+	fmt.Fprintf(&b, "- **Updated:** %s\n", cfg.FormatTime(sess.UpdatedAt))
+	if sess.TemplateName != "" {
+		fmt.Fprintf(&b, "- **Template:** %s\n", sess.TemplateName)
+	}
+	if sess.SystemPrompt != "" {
+		fmt.Fprintf(&b, "\n## System Prompt\n\n%s\n", sess.SystemPrompt)
+	}
+
+	for _, msg := range sess.Messages {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n%s\n", cfg.RoleLabel(msg.Role), cfg.FormatTime(msg.Timestamp), msg.Content)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeJSON writes the session as it is stored
+func writeJSON(w io.Writer, sess *session.Session) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sess)
+}
+
+// htmlTemplate is the template for HTML exports (content is escaped by html/template)
+var htmlTemplate = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 800px; margin: 2em auto; line-height: 1.5; }
+.message { margin: 1em 0; padding: 0.5em 1em; border-radius: 6px; }
+.user { background: #eef3fb; }
+.assistant { background: #f4f4f4; }
+.meta { color: #666; font-size: 0.9em; }
+pre { white-space: pre-wrap; font-family: inherit; margin: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul class="meta">
+<li>Session: {{.ID}}</li>
+<li>Model: {{.Model}}</li>
+<li>Created: {{.Created}}</li>
+<li>Updated: {{.Updated}}</li>
+{{- if .Template}}
+<li>Template: {{.Template}}</li>
+{{- end}}
+</ul>
+{{- if .SystemPrompt}}
+<h2>System Prompt</h2>
+<pre>{{.SystemPrompt}}</pre>
+{{- end}}
+{{- range .Messages}}
+<div class="message {{.Role}}">
+<p class="meta"><strong>{{.Label}}</strong> ({{.Time}})</p>
+<pre>{{.Content}}</pre>
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlMessage is a message as rendered in HTML exports
+type htmlMessage struct {
+	Role    string
+	Label   string
+	Time    string
+	Content string
+}
+
+// writeHTML renders the session as a standalone HTML document
+func writeHTML(w io.Writer, sess *session.Session, cfg Config) error {
+	messages := make([]htmlMessage, 0, len(sess.Messages))
+	for _, msg := range sess.Messages {
+		messages = append(messages, htmlMessage{
+			Role:    msg.Role,
+			Label:   cfg.RoleLabel(msg.Role),
+			Time:    cfg.FormatTime(msg.Timestamp),
+			Content: msg.Content,
+		})
+	}
+
+	return htmlTemplate.Execute(w, map[string]interface{}{
+		"Title":        sess.GetDisplayName(),
+		"ID":           sess.ID,
+		"Model":        sess.Model,
+		"Created":      cfg.FormatTime(sess.CreatedAt),
+		"Updated":      cfg.FormatTime(sess.UpdatedAt),
+		"Template":     sess.TemplateName,
+		"SystemPrompt": sess.SystemPrompt,
+		"Messages":     messages,
+	})
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc/session"
+)
+
+// testConfig renders labels and times in a fixed way
+type testConfig struct{}
+
+func (testConfig) RoleLabel(role string) string {
+	if role == "assistant" {
+		return "Bot"
+	}
+	return "Me"
+}
+
+func (testConfig) FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func newTestSession() *session.Session {
+	sess := session.NewSession("openai:gpt-4")
+	sess.Name = "greeting"
+	sess.AddMessage("user", "Hello <b>world</b>")
+	sess.AddMessage("assistant", "Hi!")
+	return sess
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "markdown", want: FormatMarkdown},
+		{name: "md", want: FormatMarkdown},
+		{name: ".json", want: FormatJSON},
+		{name: "HTML", want: FormatHTML},
+		{name: "pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	sess := newTestSession()
+
+	tests := []struct {
+		format Format
+		want   []string
+	}{
+		{format: FormatMarkdown, want: []string{"# greeting", "## Me (", "Hello <b>world</b>", "## Bot (", "Hi!"}},
+		{format: FormatHTML, want: []string{"<title>greeting</title>", "<strong>Me</strong>", "Hello &lt;b&gt;world&lt;/b&gt;", "<strong>Bot</strong>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, sess, tt.format, testConfig{}); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	sess := newTestSession()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, sess, FormatJSON, testConfig{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got session.Session
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.ID != sess.ID || len(got.Messages) != 2 {
+		t.Errorf("decoded session = %s with %d messages, want %s with 2", got.ID, len(got.Messages), sess.ID)
+	}
+}
@@ -0,0 +1,216 @@
+package llmc
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FallbackNotifyFunc is called when a request is retried with the next model in the chain
+type FallbackNotifyFunc func(failedModel, nextModel string, err error)
+
+// FallbackProvider wraps a chain of providers and retries a failed request
+// with the next provider when the error is temporary (rate limit, server error
+// or network failure). Authentication errors, content filters and other
+// client errors are returned immediately.
+type FallbackProvider struct {
+	models    []string
+	providers []Provider
+	notify    FallbackNotifyFunc
+	lastModel string // Model that answered the last successful request
+	lastIndex int    // Index of the provider that answered the last successful request
+}
+
+// NewFallbackProvider creates a FallbackProvider.
+// models and providers must have the same length; models[0]/providers[0] is the primary.
+// notify can be nil.
+func NewFallbackProvider(models []string, providers []Provider, notify FallbackNotifyFunc) *FallbackProvider {
+	return &FallbackProvider{
+		models:    models,
+		providers: providers,
+		notify:    notify,
+	}
+}
+
+// Chat sends a message, falling back to the next provider on temporary errors
+func (f *FallbackProvider) Chat(message string) (string, error) {
+	return f.ChatContext(context.Background(), message)
+}
+
+// ChatWithHistory sends a message with history, falling back to the next provider on temporary errors
+func (f *FallbackProvider) ChatWithHistory(systemPrompt string, messages []Message, newMessage string) (string, error) {
+	return f.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (f *FallbackProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	return f.do(func(p Provider) (string, error) {
+		return p.ChatContext(ctx, message)
+	})
+}
+
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (f *FallbackProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []Message, newMessage string) (string, error) {
+	return f.do(func(p Provider) (string, error) {
+		return p.ChatWithHistoryContext(ctx, systemPrompt, messages, newMessage)
+	})
+}
+
+// ChatStream streams a message with history, falling back to the next provider
+// on temporary errors as long as no event has been emitted yet.
+// All providers in the chain must implement StreamingProvider.
+func (f *FallbackProvider) ChatStream(ctx context.Context, systemPrompt string, messages []Message, newMessage string, onEvent func(StreamEvent) error) (string, error) {
+	for i, p := range f.providers {
+		if _, ok := p.(StreamingProvider); !ok {
+			return "", fmt.Errorf("%s: %w", f.models[i], ErrStreamingNotSupported)
+		}
+	}
+
+	var lastErr error
+	for i, p := range f.providers {
+		emitted := false
+		response, err := p.(StreamingProvider).ChatStream(ctx, systemPrompt, messages, newMessage, func(e StreamEvent) error {
+			emitted = true
+			return onEvent(e)
+		})
+		if err == nil {
+			f.lastModel = f.models[i]
+			f.lastIndex = i
+			return response, nil
+		}
+		lastErr = err
+
+		// Output already streamed cannot be taken back
+		if emitted || !IsTemporaryError(err) || i == len(f.providers)-1 {
+			break
+		}
+		if f.notify != nil {
+			f.notify(f.models[i], f.models[i+1], err)
+		}
+	}
+	return "", lastErr
+}
+
+// do runs fn against each provider in order until one succeeds or a non-temporary error occurs
+func (f *FallbackProvider) do(fn func(Provider) (string, error)) (string, error) {
+	var lastErr error
+	for i, p := range f.providers {
+		response, err := fn(p)
+		if err == nil {
+			f.lastModel = f.models[i]
+			f.lastIndex = i
+			return response, nil
+		}
+		lastErr = err
+
+		if !IsTemporaryError(err) || i == len(f.providers)-1 {
+			break
+		}
+		if f.notify != nil {
+			f.notify(f.models[i], f.models[i+1], err)
+		}
+	}
+	return "", lastErr
+}
+
+// LastModel returns the model that answered the last successful request
+// (empty if no request has succeeded yet)
+func (f *FallbackProvider) LastModel() string {
+	return f.lastModel
+}
+
+// LastUsage returns the token usage reported by the provider that answered the
+// last successful request (nil if none has succeeded or the usage is unknown)
+func (f *FallbackProvider) LastUsage() *Usage {
+	if f.lastModel == "" {
+		return nil
+	}
+	return LastUsage(f.providers[f.lastIndex])
+}
+
+// ResponseModel returns the model that answered the last request of provider.
+// Wrapping providers are unwrapped until a FallbackProvider is found;
+// defaultModel is returned if there is none or it has not answered yet.
+func ResponseModel(provider Provider, defaultModel string) string {
+	for provider != nil {
+		if f, ok := provider.(*FallbackProvider); ok {
+			if f.lastModel != "" {
+				return f.lastModel
+			}
+			break
+		}
+		wrapper, ok := provider.(interface{ Unwrap() Provider })
+		if !ok {
+			break
+		}
+		provider = wrapper.Unwrap()
+	}
+	return defaultModel
+}
+
+// eachProvider calls visit for the providers that send the requests of provider:
+// every chained provider of a fallback chain, and for a wrapper that visit does not
+// accept, the provider it wraps (Unwrap). It stops and returns false at the first
+// provider that is neither accepted by visit nor a wrapper.
+func eachProvider(provider Provider, visit func(p Provider) bool) bool {
+	if f, ok := provider.(*FallbackProvider); ok {
+		for _, chained := range f.providers {
+			if !eachProvider(chained, visit) {
+				return false
+			}
+		}
+		return true
+	}
+	if visit(provider) {
+		return true
+	}
+	if wrapper, ok := provider.(interface{ Unwrap() Provider }); ok {
+		return eachProvider(wrapper.Unwrap(), visit)
+	}
+	return false
+}
+
+// SetWebSearch enables or disables web search for all providers in the chain
+func (f *FallbackProvider) SetWebSearch(enabled bool) {
+	for _, p := range f.providers {
+		p.SetWebSearch(enabled)
+	}
+}
+
+// SetIgnoreWebSearchErrors configures all providers in the chain
+func (f *FallbackProvider) SetIgnoreWebSearchErrors(enabled bool) {
+	for _, p := range f.providers {
+		p.SetIgnoreWebSearchErrors(enabled)
+	}
+}
+
+// SetDebug enables or disables debug output for all providers in the chain
+func (f *FallbackProvider) SetDebug(enabled bool) {
+	for _, p := range f.providers {
+		p.SetDebug(enabled)
+	}
+}
+
+// SetTimeout sets the HTTP request timeout for all providers in the chain
+func (f *FallbackProvider) SetTimeout(timeout time.Duration) {
+	for _, p := range f.providers {
+		p.SetTimeout(timeout)
+	}
+}
+
+// SetSampling sets the sampling parameters for all providers in the chain
+func (f *FallbackProvider) SetSampling(params SamplingParams) {
+	for _, p := range f.providers {
+		p.SetSampling(params)
+	}
+}
+
+// ListModels returns the models of the primary provider
+func (f *FallbackProvider) ListModels() ([]ModelInfo, error) {
+	return f.providers[0].ListModels()
+}
+
+// HealthCheck checks the primary provider
+func (f *FallbackProvider) HealthCheck(ctx context.Context) error {
+	return f.providers[0].HealthCheck(ctx)
+}
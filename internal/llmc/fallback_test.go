@@ -0,0 +1,182 @@
+package llmc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeProvider is a Provider that returns a fixed response or error
+type fakeProvider struct {
+	response string
+	err      error
+	calls    int
+}
+
+func (p *fakeProvider) Chat(message string) (string, error) {
+	p.calls++
+	return p.response, p.err
+}
+
+func (p *fakeProvider) ChatWithHistory(systemPrompt string, messages []Message, newMessage string) (string, error) {
+	return p.Chat(newMessage)
+}
+
+func (p *fakeProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	return p.Chat(message)
+}
+
+func (p *fakeProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []Message, newMessage string) (string, error) {
+	return p.Chat(newMessage)
+}
+
+func (p *fakeProvider) SetWebSearch(enabled bool)             {}
+func (p *fakeProvider) SetIgnoreWebSearchErrors(enabled bool) {}
+func (p *fakeProvider) SetDebug(enabled bool)                 {}
+func (p *fakeProvider) SetTimeout(timeout time.Duration)      {}
+func (p *fakeProvider) SetSampling(params SamplingParams)     {}
+func (p *fakeProvider) ListModels() ([]ModelInfo, error)      { return nil, nil }
+func (p *fakeProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func TestFallbackProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		primaryErr   error
+		wantResponse string
+		wantErr      bool
+		wantFallback bool
+		wantNotified bool
+	}{
+		{
+			name:         "primary succeeds",
+			primaryErr:   nil,
+			wantResponse: "primary",
+			wantFallback: false,
+		},
+		{
+			name:         "service unavailable triggers fallback",
+			primaryErr:   NewAPIError("openai", 503, "", "API request failed (HTTP 503)"),
+			wantResponse: "fallback",
+			wantFallback: true,
+			wantNotified: true,
+		},
+		{
+			name:         "rate limit triggers fallback",
+			primaryErr:   NewAPIError("anthropic", 429, "rate_limit_error", "API error: rate limited"),
+			wantResponse: "fallback",
+			wantFallback: true,
+			wantNotified: true,
+		},
+		{
+			name:         "auth error does not trigger fallback",
+			primaryErr:   NewAPIError("openai", 401, "", "API request failed (HTTP 401)"),
+			wantErr:      true,
+			wantFallback: false,
+		},
+		{
+			name:         "content filter does not trigger fallback",
+			primaryErr:   NewAPIError("openai", 0, "content_filter", "API error: blocked"),
+			wantErr:      true,
+			wantFallback: false,
+		},
+		{
+			name:         "unclassified error does not trigger fallback",
+			primaryErr:   fmt.Errorf("invalid model format"),
+			wantErr:      true,
+			wantFallback: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeProvider{response: "primary", err: tt.primaryErr}
+			fallback := &fakeProvider{response: "fallback"}
+			notified := false
+
+			p := NewFallbackProvider(
+				[]string{"openai:gpt-4", "gemini:gemini-2.0-flash"},
+				[]Provider{primary, fallback},
+				func(failedModel, nextModel string, err error) {
+					notified = true
+					if failedModel != "openai:gpt-4" || nextModel != "gemini:gemini-2.0-flash" {
+						t.Errorf("notify(%q, %q), want (openai:gpt-4, gemini:gemini-2.0-flash)", failedModel, nextModel)
+					}
+				},
+			)
+
+			response, err := p.Chat("hello")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Chat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && err != tt.primaryErr {
+				t.Errorf("Chat() error = %v, want %v", err, tt.primaryErr)
+			}
+			if response != tt.wantResponse {
+				t.Errorf("Chat() response = %q, want %q", response, tt.wantResponse)
+			}
+			if (fallback.calls > 0) != tt.wantFallback {
+				t.Errorf("fallback called %d times, wantFallback %v", fallback.calls, tt.wantFallback)
+			}
+			if notified != tt.wantNotified {
+				t.Errorf("notified = %v, want %v", notified, tt.wantNotified)
+			}
+		})
+	}
+}
+
+func TestFallbackProviderLastError(t *testing.T) {
+	lastErr := NewAPIError("gemini", 500, "", "API error (HTTP 500)")
+	p := NewFallbackProvider(
+		[]string{"openai:gpt-4", "gemini:gemini-2.0-flash"},
+		[]Provider{
+			&fakeProvider{err: NewAPIError("openai", 503, "", "API request failed (HTTP 503)")},
+			&fakeProvider{err: lastErr},
+		},
+		nil,
+	)
+
+	_, err := p.ChatWithHistory("", nil, "hello")
+	if err != lastErr {
+		t.Errorf("ChatWithHistory() error = %v, want %v", err, lastErr)
+	}
+}
+
+func TestResponseModel(t *testing.T) {
+	unavailable := NewAPIError("openai", 503, "", "API request failed (HTTP 503)")
+	newFallback := func(primaryErr error) *FallbackProvider {
+		return NewFallbackProvider(
+			[]string{"openai:gpt-4o", "anthropic:claude-3-5-sonnet"},
+			[]Provider{&fakeProvider{response: "primary", err: primaryErr}, &fakeProvider{response: "fallback"}},
+			nil,
+		)
+	}
+
+	primary := newFallback(nil)
+	fallback := newFallback(unavailable)
+	for _, f := range []*FallbackProvider{primary, fallback} {
+		if _, err := f.Chat("hello"); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		provider Provider
+		want     string
+	}{
+		{name: "plain provider", provider: &fakeProvider{}, want: "default:model"},
+		{name: "no request yet", provider: newFallback(nil), want: "default:model"},
+		{name: "primary answered", provider: primary, want: "openai:gpt-4o"},
+		{name: "fallback answered", provider: fallback, want: "anthropic:claude-3-5-sonnet"},
+		{name: "wrapped fallback", provider: NewRedactingProvider(fallback, &Redactor{}, nil), want: "anthropic:claude-3-5-sonnet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResponseModel(tt.provider, "default:model"); got != tt.want {
+				t.Errorf("ResponseModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
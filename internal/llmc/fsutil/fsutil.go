@@ -0,0 +1,79 @@
+// Package fsutil provides file helpers that are safe to use from concurrent llmc processes.
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	lockTimeout       = 5 * time.Second
+	staleLockAge      = 30 * time.Second // A lock older than this is left over from a crashed process
+)
+
+// WriteFileAtomic writes data to path by writing a temporary file in the same
+// directory and renaming it, so that readers never see a partially written file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
+
+// WithLock runs fn while holding an exclusive lock on path.
+// The lock is a "<path>.lock" file created exclusively; other processes wait
+// until it is removed. Locks left over by crashed processes are removed after a while.
+func WithLock(path string, fn func() error) error {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		// Remove a stale lock
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for lock %s (remove it if no other llmc process is running)", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	defer os.Remove(lockPath)
+
+	return fn()
+}
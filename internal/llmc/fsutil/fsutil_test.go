@@ -0,0 +1,47 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	for _, content := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFileAtomic() error = %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("file content = %q, want %q", got, content)
+		}
+	}
+}
+
+func TestWithLockRemovesStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	lockPath := path + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	if err := WithLock(path, func() error { called = true; return nil }); err != nil {
+		t.Fatalf("WithLock() error = %v", err)
+	}
+	if !called {
+		t.Error("WithLock() did not call fn")
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("lock file was not removed")
+	}
+}
@@ -0,0 +1,233 @@
+// Package httpretry retries provider HTTP requests that timed out, could not connect or
+// failed with a rate limit or server error. Timeouts, overloaded responses and other error responses
+// have separate retry budgets, since a flaky network and an overloaded API call for different settings.
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reason tells why a request is retried
+type Reason string
+
+const (
+	ReasonTimeout    Reason = "timeout"          // The request timed out (TimeoutRetries budget)
+	ReasonStatus     Reason = "error response"   // HTTP 429 or 5xx (MaxRetries budget)
+	ReasonConnection Reason = "connection error" // Connection refused or closed (MaxRetries budget)
+	ReasonOverloaded Reason = "overloaded"       // HTTP 529 (OverloadedRetries budget)
+)
+
+// StatusOverloaded is the non-standard status of an overloaded API (Anthropic's overloaded_error)
+const StatusOverloaded = 529
+
+// Policy configures how requests are retried
+type Policy struct {
+	MaxRetries        int           // Retries after HTTP 429 and 5xx responses and connection errors
+	TimeoutRetries    int           // Retries after timeouts
+	OverloadedRetries int           // Retries after HTTP 529 (overloaded) responses
+	Backoff           time.Duration // Delay before the first retry, doubled for each further retry (plus jitter)
+
+	// OnRetry is called before a retry (optional). attempt is the number of the retry
+	// within the budget of reason, starting at 1.
+	OnRetry func(reason Reason, attempt int, delay time.Duration)
+}
+
+// TimeoutError reports a request that did not complete within the client timeout
+type TimeoutError struct {
+	Timeout time.Duration // Client timeout that expired
+	Err     error         // Underlying error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %gs (increase with --timeout)", e.Timeout.Seconds())
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultBackoff is the delay before the first retry if Policy.Backoff is not set
+const DefaultBackoff = 500 * time.Millisecond
+
+// MaxRetryAfter limits the delay requested by the Retry-After header of a 429 response
+const MaxRetryAfter = time.Minute
+
+var (
+	policyMu sync.Mutex
+	policy   Policy
+)
+
+// Configure sets the policy used by Do
+func Configure(p Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+}
+
+// CurrentPolicy returns the policy used by Do
+func CurrentPolicy() Policy {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	return policy
+}
+
+// Do sends req with client, retrying according to the policy set by Configure
+func Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	return DoWithPolicy(client, req, CurrentPolicy())
+}
+
+// DoWithPolicy sends req with client and retries it according to p.
+// The delay before a retry is the exponential backoff with up to 50% random jitter,
+// or the Retry-After header of a 429 response if it has one.
+// The client timeout applies to each attempt. A request with a body is only
+// retried if the body can be recreated (req.GetBody, set by http.NewRequest).
+// If all retries fail, the last response or error is returned; an error caused by
+// the client timeout is returned as a *TimeoutError.
+func DoWithPolicy(client *http.Client, req *http.Request, p Policy) (*http.Response, error) {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	used := map[Reason]int{}
+	retries := 0
+	for {
+		resp, err := client.Do(req)
+
+		// Never retry a request whose context was cancelled or has expired
+		reason, retryable := retryReason(resp, err)
+		if req.Context().Err() != nil || !retryable || used[reason] >= p.budget(reason) || !rewind(req) {
+			if err != nil && client.Timeout > 0 && req.Context().Err() == nil && IsTimeout(err) {
+				err = &TimeoutError{Timeout: client.Timeout, Err: err}
+			}
+			return resp, err
+		}
+		delay := retryAfter(resp)
+		if delay <= 0 {
+			delay = withJitter(backoff << retries)
+		}
+		if resp != nil {
+			// Drain the body so that the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		used[reason]++
+		retries++
+		if p.OnRetry != nil {
+			p.OnRetry(reason, used[reason], delay)
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// budget returns the number of retries allowed for reason
+func (p Policy) budget(reason Reason) int {
+	switch reason {
+	case ReasonTimeout:
+		return p.TimeoutRetries
+	case ReasonOverloaded:
+		return p.OverloadedRetries
+	}
+	return p.MaxRetries
+}
+
+// retryReason reports whether the result of a request can be retried and why
+func retryReason(resp *http.Response, err error) (Reason, bool) {
+	if err != nil {
+		if IsTimeout(err) {
+			return ReasonTimeout, true
+		}
+		return ReasonConnection, IsConnectionError(err)
+	}
+	if resp.StatusCode == StatusOverloaded {
+		return ReasonOverloaded, true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return ReasonStatus, true
+	}
+	return "", false
+}
+
+// IsTimeout reports whether err is a request or connection timeout
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionError reports whether err is a failure to connect to the server
+// or a connection closed by the server before it responded
+func IsConnectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// An unknown host will not resolve on the next attempt either
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a 429 response,
+// given in seconds or as an HTTP date, up to MaxRetryAfter (0 if there is none)
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	}
+	return min(delay, MaxRetryAfter)
+}
+
+// withJitter adds a random delay of up to half of delay, so that clients that
+// failed at the same time do not all retry at the same time
+func withJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return delay
+	}
+	return delay + rand.N(delay/2+1)
+}
+
+// rewind prepares req to be sent again and reports whether that is possible
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
@@ -0,0 +1,262 @@
+package httpretry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoWithPolicyBudgets(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      func(w http.ResponseWriter, r *http.Request)
+		policy       Policy
+		wantAttempts int32
+		wantErr      bool
+		wantStatus   int
+		wantReason   Reason
+	}{
+		{
+			name: "timeout uses timeout budget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			policy:       Policy{MaxRetries: 5, TimeoutRetries: 1},
+			wantAttempts: 2,
+			wantErr:      true,
+			wantReason:   ReasonTimeout,
+		},
+		{
+			name: "429 uses error budget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			policy:       Policy{MaxRetries: 2, TimeoutRetries: 5},
+			wantAttempts: 3,
+			wantStatus:   http.StatusTooManyRequests,
+			wantReason:   ReasonStatus,
+		},
+		{
+			name: "5xx uses error budget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			policy:       Policy{MaxRetries: 1},
+			wantAttempts: 2,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantReason:   ReasonStatus,
+		},
+		{
+			name: "529 uses overloaded budget",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(StatusOverloaded)
+			},
+			policy:       Policy{MaxRetries: 1, OverloadedRetries: 3},
+			wantAttempts: 4,
+			wantStatus:   StatusOverloaded,
+			wantReason:   ReasonOverloaded,
+		},
+		{
+			name: "no timeout retries by default",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			policy:       Policy{MaxRetries: 3},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name: "client errors are not retried",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			policy:       Policy{MaxRetries: 3, TimeoutRetries: 3},
+			wantAttempts: 1,
+			wantStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			var reasons []Reason
+			policy := tt.policy
+			policy.Backoff = time.Millisecond
+			policy.OnRetry = func(reason Reason, attempt int, delay time.Duration) {
+				reasons = append(reasons, reason)
+			}
+
+			client := &http.Client{Timeout: 50 * time.Millisecond}
+			req, _ := http.NewRequest("POST", server.URL, bytes.NewBufferString(`{}`))
+			resp, err := DoWithPolicy(client, req, policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DoWithPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if resp != nil {
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("DoWithPolicy() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("DoWithPolicy() attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			for _, reason := range reasons {
+				if reason != tt.wantReason {
+					t.Errorf("OnRetry() reason = %q, want %q", reason, tt.wantReason)
+				}
+			}
+		})
+	}
+}
+
+func TestDoWithPolicyResendsBody(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"input":"hello"}` {
+			t.Errorf("attempt %d body = %q", atomic.LoadInt32(&attempts)+1, body)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("POST", server.URL, bytes.NewBufferString(`{"input":"hello"}`))
+	resp, err := DoWithPolicy(server.Client(), req, Policy{MaxRetries: 1, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("DoWithPolicy() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DoWithPolicy() status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestDoWithPolicyStopsWhenCancelled(t *testing.T) {
+	var attempts int32
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	start := time.Now()
+	resp, err := DoWithPolicy(server.Client(), req, Policy{MaxRetries: 3, Backoff: time.Second})
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, context.Canceled) && (resp == nil || resp.StatusCode != http.StatusServiceUnavailable) {
+		t.Errorf("DoWithPolicy() = %v, %v, want the cancellation or the first response", resp, err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("DoWithPolicy() attempts = %d, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("DoWithPolicy() took %v, want it to stop without waiting for the backoff", elapsed)
+	}
+}
+
+func TestDoWithPolicyRetriesConnectionErrors(t *testing.T) {
+	// Take the address of a server that is no longer listening
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	var reasons []Reason
+	policy := Policy{MaxRetries: 2, Backoff: time.Millisecond, OnRetry: func(reason Reason, attempt int, delay time.Duration) {
+		reasons = append(reasons, reason)
+	}}
+	req, _ := http.NewRequest("GET", url, nil)
+	if _, err := DoWithPolicy(&http.Client{}, req, policy); err == nil {
+		t.Fatal("DoWithPolicy() error = nil, want connection error")
+	}
+	if len(reasons) != 2 || reasons[0] != ReasonConnection {
+		t.Errorf("DoWithPolicy() retries = %q, want 2 %q retries", reasons, ReasonConnection)
+	}
+}
+
+func TestDoWithPolicyRetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	policy := Policy{MaxRetries: 1, Backoff: time.Millisecond, OnRetry: func(reason Reason, attempt int, delay time.Duration) {
+		delays = append(delays, delay)
+	}}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := DoWithPolicy(server.Client(), req, policy)
+	if err != nil {
+		t.Fatalf("DoWithPolicy() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DoWithPolicy() status = %d, want 200", resp.StatusCode)
+	}
+	if len(delays) != 1 || delays[0] != time.Second {
+		t.Errorf("DoWithPolicy() delays = %v, want [1s] from Retry-After", delays)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+	}{
+		{name: "seconds", status: http.StatusTooManyRequests, header: "3", want: 3 * time.Second},
+		{name: "capped", status: http.StatusTooManyRequests, header: "3600", want: MaxRetryAfter},
+		{name: "date in the past", status: http.StatusTooManyRequests, header: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0},
+		{name: "invalid", status: http.StatusTooManyRequests, header: "soon", want: 0},
+		{name: "missing", status: http.StatusTooManyRequests, want: 0},
+		{name: "ignored for 5xx", status: http.StatusServiceUnavailable, header: "3", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			if got := retryAfter(resp); got > tt.want || (tt.want > 0 && got < tt.want) {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	delay := 100 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		if got := withJitter(delay); got < delay || got > delay+delay/2 {
+			t.Fatalf("withJitter(%v) = %v, want between %v and %v", delay, got, delay, delay+delay/2)
+		}
+	}
+}
@@ -4,15 +4,18 @@
 package llmc
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // ModelInfo represents information about an available model from a provider.
 type ModelInfo struct {
-	ID          string // Model identifier (e.g., "gpt-4", "gemini-pro")
-	Description string // Human-readable description of the model
-	IsDefault   bool   // Whether this is the default model for the provider
+	ID            string // Model identifier (e.g., "gpt-4", "gemini-pro")
+	Description   string // Human-readable description of the model
+	IsDefault     bool   // Whether this is the default model for the provider
+	ContextWindow int    // Context window in tokens (0 = unknown)
 }
 
 // Provider defines the interface for LLM providers.
@@ -33,6 +36,12 @@ type Provider interface {
 	// newMessage is the new user message to send.
 	ChatWithHistory(systemPrompt string, messages []Message, newMessage string) (string, error)
 
+	// ChatContext is like Chat but aborts the request when ctx is cancelled.
+	ChatContext(ctx context.Context, message string) (string, error)
+
+	// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled.
+	ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []Message, newMessage string) (string, error)
+
 	// SetWebSearch enables or disables web search for the provider.
 	SetWebSearch(enabled bool)
 
@@ -42,8 +51,53 @@ type Provider interface {
 	// SetDebug enables or disables debug output.
 	SetDebug(enabled bool)
 
+	// SetTimeout sets the HTTP request timeout (0 = no timeout).
+	SetTimeout(timeout time.Duration)
+
+	// SetSampling sets the sampling parameters (temperature, top_p, max_tokens).
+	SetSampling(params SamplingParams)
+
 	// ListModels returns a list of available models for the provider.
 	ListModels() ([]ModelInfo, error)
+
+	// HealthCheck makes the cheapest authenticated request the provider
+	// supports and returns nil when the API is reachable and the token is
+	// accepted. An invalid token surfaces as an APIError of ErrorKindAuth.
+	HealthCheck(ctx context.Context) error
+}
+
+// CustomProviderName is the provider name for a compatible gateway at a custom base URL.
+// Requests to it use the API of another provider, selected by api_style.
+const CustomProviderName = "custom"
+
+// SupportedProviders lists the provider names accepted in model strings.
+// Each entry must match the ProviderName of a provider package, except CustomProviderName.
+var SupportedProviders = []string{"openai", "gemini", "anthropic", "cohere", "openrouter", "azure", CustomProviderName}
+
+// APIStyles lists the provider APIs a custom provider can use
+var APIStyles = []string{"openai", "anthropic", "gemini"}
+
+// IsSupportedProvider reports whether name is one of SupportedProviders
+func IsSupportedProvider(name string) bool {
+	for _, p := range SupportedProviders {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateModelString parses a model string in "provider:model" format and
+// checks that the provider is one of SupportedProviders.
+func ValidateModelString(modelStr string) error {
+	provider, _, err := ParseModelString(modelStr)
+	if err != nil {
+		return err
+	}
+	if !IsSupportedProvider(provider) {
+		return fmt.Errorf("unsupported provider: %s (supported: %s)", provider, strings.Join(SupportedProviders, ", "))
+	}
+	return nil
 }
 
 // ParseModelString parses a model string in "provider:model" format.
@@ -69,6 +123,17 @@ func ParseModelString(modelStr string) (string, string, error) {
 	return provider, model, nil
 }
 
+// QualifyModelString returns modelStr with defaultProvider prepended if it has no
+// provider, so that a bare model name such as "gpt-4o" becomes "openai:gpt-4o".
+// A model string with a provider, and any model string when defaultProvider is
+// empty, is returned as it is.
+func QualifyModelString(modelStr, defaultProvider string) string {
+	if defaultProvider == "" || modelStr == "" || strings.Contains(modelStr, ":") {
+		return modelStr
+	}
+	return FormatModelString(defaultProvider, modelStr)
+}
+
 // FormatModelString formats provider and model into "provider:model" format.
 //
 // Example:
@@ -84,3 +84,49 @@ func TestParseModelString(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateModelString(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "openai", input: "openai:gpt-4", wantErr: false},
+		{name: "gemini", input: "gemini:gemini-2.0-flash", wantErr: false},
+		{name: "anthropic", input: "anthropic:claude-3-5-sonnet-20241022", wantErr: false},
+		{name: "unknown provider", input: "opanai:gpt-4", wantErr: true},
+		{name: "invalid format", input: "gpt-4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateModelString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateModelString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQualifyModelString(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		defaultProvider string
+		want            string
+	}{
+		{name: "bare model", input: "gpt-4o", defaultProvider: "openai", want: "openai:gpt-4o"},
+		{name: "bare model with slash", input: "anthropic/claude-3.5-sonnet", defaultProvider: "openrouter", want: "openrouter:anthropic/claude-3.5-sonnet"},
+		{name: "explicit provider", input: "anthropic:claude-3-5-sonnet-20241022", defaultProvider: "openai", want: "anthropic:claude-3-5-sonnet-20241022"},
+		{name: "no default provider", input: "gpt-4o", defaultProvider: "", want: "gpt-4o"},
+		{name: "empty model", input: "", defaultProvider: "openai", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QualifyModelString(tt.input, tt.defaultProvider); got != tt.want {
+				t.Errorf("QualifyModelString(%q, %q) = %q, want %q", tt.input, tt.defaultProvider, got, tt.want)
+			}
+		})
+	}
+}
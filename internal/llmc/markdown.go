@@ -0,0 +1,88 @@
+package llmc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ANSI escape sequences used by RenderMarkdown
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiHeading   = "\x1b[1;4m" // Bold, underlined
+	ansiCode      = "\x1b[36m"  // Cyan
+	ansiBoldOff   = "\x1b[22m"
+	ansiColorOff  = "\x1b[39m"
+	bulletSymbol  = "•"
+	quoteBarStyle = ansiDim + "│" + ansiReset
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	quotePattern   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+)
+
+// RenderMarkdown styles a Markdown text for a terminal with ANSI escape sequences:
+// headings, **bold** text, `inline code`, list bullets, block quotes and fenced code
+// blocks. Everything else, including the line structure, is kept as it is, so the
+// rendered text has as many lines as the source.
+func RenderMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	var fence string // Opening fence of the current code block ("" = outside a block)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				lines[i] = ansiDim + line + ansiReset
+				continue
+			}
+			lines[i] = ansiCode + line + ansiReset
+			continue
+		}
+		if f := codeFence(trimmed); f != "" {
+			fence = f
+			lines[i] = ansiDim + line + ansiReset
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			lines[i] = ansiHeading + m[2] + ansiReset
+		} else if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + bulletSymbol + " " + renderInline(m[2])
+		} else if m := quotePattern.FindStringSubmatch(line); m != nil {
+			lines[i] = quoteBarStyle + " " + renderInline(m[1])
+		} else {
+			lines[i] = renderInline(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline styles the `code` spans and **bold** (or __bold__) text of a line.
+// Markers without a closing marker on the same line are kept as they are.
+func renderInline(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == '`':
+			if end := strings.IndexByte(line[i+1:], '`'); end > 0 {
+				b.WriteString(ansiCode + line[i+1:i+1+end] + ansiColorOff)
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(line[i:], "**"), strings.HasPrefix(line[i:], "__"):
+			marker := line[i : i+2]
+			if end := strings.Index(line[i+2:], marker); end > 0 {
+				b.WriteString(ansiBold + line[i+2:i+2+end] + ansiBoldOff)
+				i += end + 4
+				continue
+			}
+		}
+		b.WriteByte(line[i])
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package llmc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain text", text: "Just text.", want: "Just text."},
+		{name: "heading", text: "## Setup ##", want: "\x1b[1;4mSetup\x1b[0m"},
+		{name: "bold", text: "This is **important** and __this__ too", want: "This is \x1b[1mimportant\x1b[22m and \x1b[1mthis\x1b[22m too"},
+		{name: "inline code", text: "Run `go test` now", want: "Run \x1b[36mgo test\x1b[39m now"},
+		{name: "unclosed markers", text: "2 ** 3 and a ` tick", want: "2 ** 3 and a ` tick"},
+		{name: "bullets", text: "- one\n  * two", want: "• one\n  • two"},
+		{name: "block quote", text: "> quoted", want: "\x1b[2m│\x1b[0m quoted"},
+		{
+			name: "code block",
+			text: "```go\nx := **y**\n# not a heading\n```\nafter",
+			want: "\x1b[2m```go\x1b[0m\n\x1b[36mx := **y**\x1b[0m\n\x1b[36m# not a heading\x1b[0m\n\x1b[2m```\x1b[0m\nafter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.text); got != tt.want {
+				t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownKeepsLines(t *testing.T) {
+	text := "# Title\n\nSome **bold** text.\n\n```\ncode\n```\n- item"
+	if got, want := strings.Count(RenderMarkdown(text), "\n"), strings.Count(text, "\n"); got != want {
+		t.Errorf("RenderMarkdown() has %d newlines, want %d", got, want)
+	}
+}
@@ -1,8 +1,33 @@
 package llmc
 
+import (
+	"fmt"
+	"time"
+)
+
 // Message represents a single message in a conversation (for session support)
 type Message struct {
-	Role      string      `json:"role"`      // "user" or "assistant"
-	Content   string      `json:"content"`   // Message content
-	Timestamp interface{} `json:"timestamp"` // time.Time, but use interface{} to avoid import cycle
+	Role      string    `json:"role"`            // "user" or "assistant"
+	Content   string    `json:"content"`         // Message content
+	Timestamp time.Time `json:"timestamp"`       // Time the message was added
+	Model     string    `json:"model,omitempty"` // Model that generated the message (assistant messages; empty in older sessions)
+}
+
+// userFirstAPIs lists the provider APIs that reject a conversation starting with an assistant message
+var userFirstAPIs = []string{"anthropic", "gemini"}
+
+// CheckFirstRole returns an error if role is not a message role ("user" or "assistant")
+// or if the provider API does not accept a conversation whose first message has that role
+func CheckFirstRole(api, role string) error {
+	if role != "user" && role != "assistant" {
+		return fmt.Errorf("invalid role '%s' (must be user or assistant)", role)
+	}
+	if role == "assistant" {
+		for _, name := range userFirstAPIs {
+			if api == name {
+				return fmt.Errorf("%s requires the conversation to start with a user message", api)
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,96 @@
+// Package modelcache caches the model lists returned by providers.
+package modelcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/fsutil"
+)
+
+// DefaultTTL is how long cached model lists are used before they are fetched again
+const DefaultTTL = 24 * time.Hour
+
+// Cache holds the cached model lists by provider name
+type Cache struct {
+	Providers map[string]Entry `json:"providers"`
+}
+
+// Entry is the cached model list of a provider
+type Entry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Models    []Model   `json:"models"`
+}
+
+// Model is a cached model
+type Model struct {
+	ID            string `json:"id"`
+	Description   string `json:"description,omitempty"`
+	ContextWindow int    `json:"context_window,omitempty"`
+}
+
+// DefaultPath returns the default cache file path ($XDG_CACHE_HOME/llmc/models.json or the OS equivalent)
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "llmc", "models.json"), nil
+}
+
+// Load reads the cache file at path.
+// A missing, partially written or otherwise unreadable cache is treated as empty.
+func Load(path string) *Cache {
+	cache := &Cache{Providers: map[string]Entry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var loaded Cache
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded.Providers == nil {
+		return cache
+	}
+	return &loaded
+}
+
+// Get returns the cached models of provider if they were fetched within ttl
+func (c *Cache) Get(provider string, ttl time.Duration) ([]llmc.ModelInfo, bool) {
+	entry, ok := c.Providers[provider]
+	if !ok || time.Since(entry.FetchedAt) > ttl {
+		return nil, false
+	}
+	models := make([]llmc.ModelInfo, 0, len(entry.Models))
+	for _, m := range entry.Models {
+		models = append(models, llmc.ModelInfo{ID: m.ID, Description: m.Description, ContextWindow: m.ContextWindow})
+	}
+	return models, true
+}
+
+// Update stores the models of provider in the cache file at path.
+// The file is re-read and replaced atomically under a lock, so that concurrent
+// llmc processes updating different providers do not overwrite each other.
+func Update(path string, provider string, models []llmc.ModelInfo) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	entry := Entry{FetchedAt: time.Now(), Models: make([]Model, 0, len(models))}
+	for _, m := range models {
+		entry.Models = append(entry.Models, Model{ID: m.ID, Description: m.Description, ContextWindow: m.ContextWindow})
+	}
+
+	return fsutil.WithLock(path, func() error {
+		cache := Load(path)
+		cache.Providers[provider] = entry
+
+		data, err := json.MarshalIndent(cache, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize model cache: %w", err)
+		}
+		return fsutil.WriteFileAtomic(path, data, 0644)
+	})
+}
@@ -0,0 +1,88 @@
+package modelcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+func TestUpdateConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			models := []llmc.ModelInfo{{ID: fmt.Sprintf("model-%d", i), Description: "test"}}
+			errs <- Update(path, fmt.Sprintf("provider-%d", i), models)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cache Cache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		t.Fatalf("cache file is not valid JSON: %v\n%s", err, data)
+	}
+	if len(cache.Providers) != writers {
+		t.Errorf("cache has %d providers, want %d (updates were lost)", len(cache.Providers), writers)
+	}
+
+	// No temporary or lock files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("cache directory contains %v, want only models.json", names)
+	}
+}
+
+func TestLoadPartialFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	if err := os.WriteFile(path, []byte(`{"providers": {"openai": {"fetched_at": "20`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := Load(path)
+	if _, ok := cache.Get("openai", DefaultTTL); ok {
+		t.Error("Get() on a partially written cache returned models, want none")
+	}
+
+	// The cache can still be updated
+	if err := Update(path, "openai", []llmc.ModelInfo{{ID: "gpt-4"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	models, ok := Load(path).Get("openai", DefaultTTL)
+	if !ok || len(models) != 1 || models[0].ID != "gpt-4" {
+		t.Errorf("Get() = %v, %v, want [gpt-4], true", models, ok)
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	cache := &Cache{Providers: map[string]Entry{
+		"openai": {FetchedAt: time.Now().Add(-2 * DefaultTTL), Models: []Model{{ID: "gpt-4"}}},
+	}}
+	if _, ok := cache.Get("openai", DefaultTTL); ok {
+		t.Error("Get() returned expired models")
+	}
+}
@@ -0,0 +1,38 @@
+package llmc
+
+import "errors"
+
+// ErrPrefillNotSupported is returned by SetPrefill for a provider that cannot prefill responses
+var ErrPrefillNotSupported = errors.New("prefill is not supported by this provider")
+
+// PrefillProvider is implemented by providers that can continue the assistant's
+// response from given text, by ending the request with an assistant turn.
+type PrefillProvider interface {
+	Provider
+
+	// SetPrefill sets the text the response starts with ("" = none).
+	// The returned response is the continuation, without the prefill.
+	SetPrefill(text string)
+}
+
+// SetPrefill makes the responses of provider start with text. With fallback models,
+// every model must support prefill, since any of them may answer; otherwise
+// ErrPrefillNotSupported is returned and no prefill is set.
+func SetPrefill(provider Provider, text string) error {
+	var targets []PrefillProvider
+	supported := eachProvider(provider, func(p Provider) bool {
+		target, ok := p.(PrefillProvider)
+		if ok {
+			targets = append(targets, target)
+		}
+		return ok
+	})
+	if !supported {
+		return ErrPrefillNotSupported
+	}
+
+	for _, p := range targets {
+		p.SetPrefill(text)
+	}
+	return nil
+}
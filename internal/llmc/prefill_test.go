@@ -0,0 +1,38 @@
+package llmc
+
+import (
+	"errors"
+	"testing"
+)
+
+// prefillProvider is a fakeProvider that supports prefill
+type prefillProvider struct {
+	fakeProvider
+	prefill string
+}
+
+func (p *prefillProvider) SetPrefill(text string) { p.prefill = text }
+
+func TestSetPrefill(t *testing.T) {
+	t.Run("wrapped fallback chain", func(t *testing.T) {
+		primary, fallback := &prefillProvider{}, &prefillProvider{}
+		chain := NewFallbackProvider([]string{"a:1", "b:2"}, []Provider{primary, fallback}, nil)
+		if err := SetPrefill(NewRedactingProvider(chain, &Redactor{}, nil), "{"); err != nil {
+			t.Fatalf("SetPrefill() error = %v", err)
+		}
+		if primary.prefill != "{" || fallback.prefill != "{" {
+			t.Errorf("SetPrefill() prefills = %q, %q, want both %q", primary.prefill, fallback.prefill, "{")
+		}
+	})
+
+	t.Run("unsupported provider in chain", func(t *testing.T) {
+		primary := &prefillProvider{}
+		chain := NewFallbackProvider([]string{"a:1", "b:2"}, []Provider{primary, &fakeProvider{}}, nil)
+		if err := SetPrefill(chain, "{"); !errors.Is(err, ErrPrefillNotSupported) {
+			t.Errorf("SetPrefill() error = %v, want ErrPrefillNotSupported", err)
+		}
+		if primary.prefill != "" {
+			t.Errorf("SetPrefill() set prefill %q on failure, want none", primary.prefill)
+		}
+	})
+}
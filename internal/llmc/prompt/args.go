@@ -0,0 +1,102 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholderPattern matches {{key}} placeholders in prompt templates
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}\s]+)\}\}`)
+
+// ParseArgs parses the command line arguments (format: key:value) and returns a map of key-value pairs
+func ParseArgs(args []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, arg := range args {
+		// Handle quoted values
+		arg = strings.TrimSpace(arg)
+		if strings.HasPrefix(arg, `"`) && strings.HasSuffix(arg, `"`) {
+			arg = strings.Trim(arg, `"`)
+		}
+
+		// Split on first unescaped colon
+		var key, value string
+		parts := strings.SplitN(arg, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid argument format: %s. Expected format: key:value", arg)
+		}
+
+		key = strings.TrimSpace(parts[0])
+		value = strings.TrimSpace(parts[1])
+
+		// Remove escape characters from value
+		value = strings.ReplaceAll(value, `\:`, ":")
+		value = strings.ReplaceAll(value, `\"`, `"`)
+
+		if key == "input" {
+			return nil, fmt.Errorf("'input' is a reserved keyword and cannot be used as a key")
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// MergeArgs returns a new map with defaults overridden by overrides.
+// Neither input map is modified.
+func MergeArgs(defaults, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
+
+// ApplyArgs replaces {{key}} placeholders in text with the corresponding values
+func ApplyArgs(text string, args map[string]string) string {
+	for key, value := range args {
+		text = strings.ReplaceAll(text, fmt.Sprintf("{{%s}}", key), value)
+	}
+	return text
+}
+
+// Placeholders returns the sorted, unique placeholder names used in text
+func Placeholders(text string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TemplateArgs returns the sorted, unique placeholder names used in the system and
+// user templates of the prompt, except {{input}} which is filled from the message
+func TemplateArgs(p *Prompt) []string {
+	var names []string
+	for _, name := range Placeholders(p.System + "\n" + p.User) {
+		if name != "input" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MissingArgs returns the placeholders of the prompt that are not given in args.
+// {{input}} is filled from the message and is never reported as missing.
+func MissingArgs(p *Prompt, args map[string]string) []string {
+	var missing []string
+	for _, name := range TemplateArgs(p) {
+		if _, ok := args[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,113 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		defaults  map[string]string
+		overrides map[string]string
+		want      map[string]string
+	}{
+		{
+			name:      "defaults only",
+			defaults:  map[string]string{"lang": "Japanese"},
+			overrides: nil,
+			want:      map[string]string{"lang": "Japanese"},
+		},
+		{
+			name:      "overrides only",
+			defaults:  nil,
+			overrides: map[string]string{"lang": "English"},
+			want:      map[string]string{"lang": "English"},
+		},
+		{
+			name:      "per-turn args take precedence over session defaults",
+			defaults:  map[string]string{"lang": "Japanese", "tone": "formal"},
+			overrides: map[string]string{"lang": "English"},
+			want:      map[string]string{"lang": "English", "tone": "formal"},
+		},
+		{
+			name:      "both empty",
+			defaults:  nil,
+			overrides: nil,
+			want:      map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeArgs(tt.defaults, tt.overrides)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeArgsDoesNotModifyDefaults(t *testing.T) {
+	defaults := map[string]string{"lang": "Japanese"}
+	MergeArgs(defaults, map[string]string{"lang": "English"})
+	if defaults["lang"] != "Japanese" {
+		t.Errorf("defaults[lang] = %q, want Japanese", defaults["lang"])
+	}
+}
+
+func TestApplyArgs(t *testing.T) {
+	args := map[string]string{"lang": "Japanese"}
+	got := ApplyArgs("Answer in {{lang}}: {{unknown}}", args)
+	want := "Answer in Japanese: {{unknown}}"
+	if got != want {
+		t.Errorf("ApplyArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "none", text: "plain text", want: nil},
+		{name: "sorted and unique", text: "{{tone}} {{input}} {{lang}} {{tone}}", want: []string{"input", "lang", "tone"}},
+		{name: "spaces are not placeholders", text: "{{ lang }} {{}}", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Placeholders(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Placeholders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateArgs(t *testing.T) {
+	p := &Prompt{
+		System: "Review the {{lang}} code in {{repo}} for {{focus}}.",
+		User:   "{{input}}\n\nFocus on {{focus}}; reply in {{reply_lang}}.",
+	}
+
+	got := TemplateArgs(p)
+	want := []string{"focus", "lang", "reply_lang", "repo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateArgs() = %v, want %v", got, want)
+	}
+
+	if got := TemplateArgs(&Prompt{User: "{{input}}"}); len(got) != 0 {
+		t.Errorf("TemplateArgs() = %v, want none", got)
+	}
+}
+
+func TestMissingArgs(t *testing.T) {
+	p := &Prompt{System: "Translate into {{lang}}.", User: "{{input}} ({{tone}})"}
+
+	got := MissingArgs(p, map[string]string{"lang": "Japanese"})
+	want := []string{"tone"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingArgs() = %v, want %v", got, want)
+	}
+}
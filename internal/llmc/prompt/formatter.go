@@ -10,12 +10,25 @@ import (
 )
 
 // FormatMessage formats the message with prompt if specified
-// Returns the formatted message, the model specified in the prompt file (if any), and web search setting (if any)
-func FormatMessage(message string, promptName string, promptDirs []string, args []string) (string, *string, *bool, error) {
+// Returns the formatted message, the model specified in the prompt file (if any), web search setting (if any)
+// and the sampling parameters set in the prompt file
+func FormatMessage(message string, promptName string, promptDirs []string, args []string) (string, *string, *bool, llmc.SamplingParams, error) {
 	if promptName == "" {
-		return message, nil, nil, nil
+		return message, nil, nil, llmc.SamplingParams{}, nil
 	}
 
+	// Process command line arguments
+	argMap, err := ParseArgs(args)
+	if err != nil {
+		return "", nil, nil, llmc.SamplingParams{}, fmt.Errorf("error processing arguments: %v", err)
+	}
+
+	return FormatMessageWithArgs(message, promptName, promptDirs, argMap)
+}
+
+// FindPrompt returns the path of the prompt file for promptName.
+// When the prompt exists in several directories, the later directory takes precedence.
+func FindPrompt(promptName string, promptDirs []string) (string, error) {
 	// Add .toml extension if not present
 	promptFile := promptName
 	if !strings.HasSuffix(promptFile, ".toml") {
@@ -36,19 +49,38 @@ func FormatMessage(message string, promptName string, promptDirs []string, args
 	}
 
 	if !found {
-		return "", nil, nil, fmt.Errorf("prompt file '%s' not found in any of the prompt directories: %v", promptFile, promptDirs)
+		return "", fmt.Errorf("prompt file '%s' not found in any of the prompt directories: %v", promptFile, promptDirs)
+	}
+	return promptPath, nil
+}
+
+// FormatMessageWithArgs is like FormatMessage but takes already parsed template arguments
+func FormatMessageWithArgs(message string, promptName string, promptDirs []string, argMap map[string]string) (string, *string, *bool, llmc.SamplingParams, error) {
+	if promptName == "" {
+		return message, nil, nil, llmc.SamplingParams{}, nil
+	}
+
+	// Find prompt file
+	promptPath, err := FindPrompt(promptName, promptDirs)
+	if err != nil {
+		return "", nil, nil, llmc.SamplingParams{}, err
 	}
 
 	// Load prompt template
 	promptTemplate, err := LoadPrompt(promptPath)
 	if err != nil {
-		return "", nil, nil, fmt.Errorf("error loading prompt file: %v", err)
+		return "", nil, nil, llmc.SamplingParams{}, fmt.Errorf("error loading prompt file: %v", err)
 	}
 
-	// Process command line arguments
-	argMap, err := processArgs(args)
-	if err != nil {
-		return "", nil, nil, fmt.Errorf("error processing arguments: %v", err)
+	// Validate model format and provider if specified in prompt
+	if err := promptTemplate.ValidateModel(); err != nil {
+		return "", nil, nil, llmc.SamplingParams{}, fmt.Errorf("prompt template '%s' (%s): %w", promptName, promptPath, err)
+	}
+
+	// Validate sampling parameters if specified in prompt
+	sampling := promptTemplate.Sampling()
+	if err := sampling.Validate(); err != nil {
+		return "", nil, nil, llmc.SamplingParams{}, fmt.Errorf("prompt template '%s' (%s): %w", promptName, promptPath, err)
 	}
 
 	// Create a map of all replacements
@@ -59,52 +91,8 @@ func FormatMessage(message string, promptName string, promptDirs []string, args
 	}
 
 	// Format both prompts with all replacements
-	systemPrompt := promptTemplate.System
-	userPrompt := promptTemplate.User
-	for key, value := range replacements {
-		placeholder := fmt.Sprintf("{{%s}}", key)
-		systemPrompt = strings.ReplaceAll(systemPrompt, placeholder, value)
-		userPrompt = strings.ReplaceAll(userPrompt, placeholder, value)
-	}
-
-	// Validate model format if specified in prompt
-	if promptTemplate.Model != nil {
-		if _, _, err := llmc.ParseModelString(*promptTemplate.Model); err != nil {
-			return "", nil, nil, fmt.Errorf("invalid model format in prompt template: %w", err)
-		}
-	}
-
-	return fmt.Sprintf("System: %s\n\nUser: %s", systemPrompt, userPrompt), promptTemplate.Model, promptTemplate.WebSearch, nil
-}
+	systemPrompt := ApplyArgs(promptTemplate.System, replacements)
+	userPrompt := ApplyArgs(promptTemplate.User, replacements)
 
-// processArgs processes the command line arguments and returns a map of key-value pairs
-func processArgs(args []string) (map[string]string, error) {
-	result := make(map[string]string)
-	for _, arg := range args {
-		// Handle quoted values
-		arg = strings.TrimSpace(arg)
-		if strings.HasPrefix(arg, `"`) && strings.HasSuffix(arg, `"`) {
-			arg = strings.Trim(arg, `"`)
-		}
-
-		// Split on first unescaped colon
-		var key, value string
-		parts := strings.SplitN(arg, ":", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid argument format: %s. Expected format: key:value", arg)
-		}
-
-		key = strings.TrimSpace(parts[0])
-		value = strings.TrimSpace(parts[1])
-
-		// Remove escape characters from value
-		value = strings.ReplaceAll(value, `\:`, ":")
-		value = strings.ReplaceAll(value, `\"`, `"`)
-
-		if key == "input" {
-			return nil, fmt.Errorf("'input' is a reserved keyword and cannot be used as a key")
-		}
-		result[key] = value
-	}
-	return result, nil
+	return fmt.Sprintf("System: %s\n\nUser: %s", systemPrompt, userPrompt), promptTemplate.Model, promptTemplate.WebSearch, sampling, nil
 }
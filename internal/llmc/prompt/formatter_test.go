@@ -0,0 +1,86 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatMessageWithArgsModelValidation(t *testing.T) {
+	dir := t.TempDir()
+	prompts := map[string]string{
+		"valid.toml":       "user = \"{{input}}\"\nmodel = \"anthropic:claude-3-5-sonnet-20241022\"\n",
+		"typo.toml":        "user = \"{{input}}\"\nmodel = \"opanai:gpt-4\"\n",
+		"no-provider.toml": "user = \"{{input}}\"\nmodel = \"gpt-4\"\n",
+	}
+	for name, content := range prompts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		promptName string
+		wantErr    []string
+	}{
+		{name: "supported provider", promptName: "valid"},
+		{name: "unknown provider", promptName: "typo", wantErr: []string{"prompt template 'typo'", "unsupported provider: opanai"}},
+		{name: "missing provider", promptName: "no-provider", wantErr: []string{"prompt template 'no-provider'", "invalid model format"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, _, err := FormatMessageWithArgs("hello", tt.promptName, []string{dir}, nil)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("FormatMessageWithArgs() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("FormatMessageWithArgs() error = nil, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("FormatMessageWithArgs() error = %q, want containing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatMessageWithArgsSampling(t *testing.T) {
+	dir := t.TempDir()
+	prompts := map[string]string{
+		"creative.toml": "user = \"{{input}}\"\ntemperature = 1.2\nmax_tokens = 256\n",
+		"plain.toml":    "user = \"{{input}}\"\n",
+		"too-hot.toml":  "user = \"{{input}}\"\ntemperature = 3.0\n",
+	}
+	for name, content := range prompts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, _, sampling, err := FormatMessageWithArgs("hello", "creative", []string{dir}, nil)
+	if err != nil {
+		t.Fatalf("FormatMessageWithArgs() error = %v", err)
+	}
+	if sampling.Temperature == nil || *sampling.Temperature != 1.2 || sampling.MaxTokens == nil || *sampling.MaxTokens != 256 || sampling.TopP != nil {
+		t.Errorf("sampling = %v, want temperature=1.2, max_tokens=256", sampling)
+	}
+
+	_, _, _, sampling, err = FormatMessageWithArgs("hello", "plain", []string{dir}, nil)
+	if err != nil {
+		t.Fatalf("FormatMessageWithArgs() error = %v", err)
+	}
+	if sampling.Temperature != nil || sampling.TopP != nil || sampling.MaxTokens != nil {
+		t.Errorf("sampling = %v, want none set", sampling)
+	}
+
+	if _, _, _, _, err := FormatMessageWithArgs("hello", "too-hot", []string{dir}, nil); err == nil || !strings.Contains(err.Error(), "prompt template 'too-hot'") {
+		t.Errorf("FormatMessageWithArgs() error = %v, want an invalid temperature error", err)
+	}
+}
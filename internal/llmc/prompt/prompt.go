@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/BurntSushi/toml"
+	"github.com/longkey1/llmc/internal/llmc"
 )
 
 // Prompt represents the structure of a TOML prompt file
@@ -12,6 +13,11 @@ type Prompt struct {
 	User      string  `toml:"user"`
 	Model     *string `toml:"model,omitempty"`
 	WebSearch *bool   `toml:"web_search,omitempty"`
+
+	// Sampling parameters, overriding those of the config for this prompt
+	Temperature *float64 `toml:"temperature,omitempty"`
+	TopP        *float64 `toml:"top_p,omitempty"`
+	MaxTokens   *int     `toml:"max_tokens,omitempty"`
 }
 
 // LoadPrompt loads a prompt file and returns its contents
@@ -22,3 +28,20 @@ func LoadPrompt(filePath string) (*Prompt, error) {
 	}
 	return &prompt, nil
 }
+
+// ValidateModel checks that the model set in the prompt (if any) is in
+// "provider:model" format with a supported provider
+func (p *Prompt) ValidateModel() error {
+	if p.Model == nil {
+		return nil
+	}
+	if err := llmc.ValidateModelString(*p.Model); err != nil {
+		return fmt.Errorf("invalid model '%s': %w", *p.Model, err)
+	}
+	return nil
+}
+
+// Sampling returns the sampling parameters set in the prompt (unset fields are nil)
+func (p *Prompt) Sampling() llmc.SamplingParams {
+	return llmc.SamplingParams{Temperature: p.Temperature, TopP: p.TopP, MaxTokens: p.MaxTokens}
+}
@@ -0,0 +1,82 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TestFileSuffix is the file name suffix of prompt test fixtures.
+// The fixture for the template foo/bar.toml is foo/bar.test.toml.
+const TestFileSuffix = ".test.toml"
+
+// TestFile holds the test cases of a prompt template
+type TestFile struct {
+	Cases []TestCase `toml:"cases"`
+}
+
+// TestCase is a single prompt test: the template is run with Input and Args
+// and the response must satisfy all expectations
+type TestCase struct {
+	Name             string            `toml:"name"`
+	Input            string            `toml:"input"`
+	Args             map[string]string `toml:"args"`
+	ExpectContains   []string          `toml:"expect_contains"`   // Substrings the response must contain
+	ExpectRegex      []string          `toml:"expect_regex"`      // Regular expressions the response must match
+	RecordedResponse string            `toml:"recorded_response"` // Response checked instead of calling the model in offline mode
+}
+
+// IsTestFile reports whether name is the file name of a prompt test fixture
+func IsTestFile(name string) bool {
+	return strings.HasSuffix(name, TestFileSuffix)
+}
+
+// TestFilePath returns the path of the test fixture for the template at promptPath
+func TestFilePath(promptPath string) string {
+	return strings.TrimSuffix(promptPath, ".toml") + TestFileSuffix
+}
+
+// LoadTestFile loads a prompt test fixture and validates its cases
+func LoadTestFile(path string) (*TestFile, error) {
+	var file TestFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, fmt.Errorf("error decoding test file: %v", err)
+	}
+	for i, tc := range file.Cases {
+		if tc.Name == "" {
+			file.Cases[i].Name = fmt.Sprintf("case %d", i+1)
+		}
+		if len(tc.ExpectContains) == 0 && len(tc.ExpectRegex) == 0 {
+			return nil, fmt.Errorf("%s: no expect_contains or expect_regex", file.Cases[i].Name)
+		}
+		for _, pattern := range tc.ExpectRegex {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("%s: invalid expect_regex %q: %v", file.Cases[i].Name, pattern, err)
+			}
+		}
+	}
+	return &file, nil
+}
+
+// Check returns a description of each expectation that response does not satisfy
+func (tc *TestCase) Check(response string) []string {
+	var failures []string
+	for _, want := range tc.ExpectContains {
+		if !strings.Contains(response, want) {
+			failures = append(failures, fmt.Sprintf("response does not contain %q", want))
+		}
+	}
+	for _, pattern := range tc.ExpectRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid expect_regex %q: %v", pattern, err))
+			continue
+		}
+		if !re.MatchString(response) {
+			failures = append(failures, fmt.Sprintf("response does not match %q", pattern))
+		}
+	}
+	return failures
+}
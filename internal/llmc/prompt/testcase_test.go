@@ -0,0 +1,117 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTestCaseCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		tc       TestCase
+		response string
+		want     []string
+	}{
+		{
+			name:     "all expectations met",
+			tc:       TestCase{ExpectContains: []string{"error", "os.Open"}, ExpectRegex: []string{`(?i)^the error`}},
+			response: "The error returned by os.Open is ignored.",
+		},
+		{
+			name:     "missing substring",
+			tc:       TestCase{ExpectContains: []string{"error", "defer"}},
+			response: "The error returned by os.Open is ignored.",
+			want:     []string{`response does not contain "defer"`},
+		},
+		{
+			name:     "regex not matched",
+			tc:       TestCase{ExpectRegex: []string{`\bClose\(\)`}},
+			response: "The error returned by os.Open is ignored.",
+			want:     []string{`response does not match "\\bClose\\(\\)"`},
+		},
+		{
+			name:     "contains is case sensitive",
+			tc:       TestCase{ExpectContains: []string{"ERROR"}, ExpectRegex: []string{`(?i)ERROR`}},
+			response: "error",
+			want:     []string{`response does not contain "ERROR"`},
+		},
+		{
+			name:     "all failures reported",
+			tc:       TestCase{ExpectContains: []string{"a", "b"}, ExpectRegex: []string{"c"}},
+			response: "",
+			want:     []string{`response does not contain "a"`, `response does not contain "b"`, `response does not match "c"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.tc.Check(tt.response)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTestFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name: "cases",
+			content: `[[cases]]
+name = "greeting"
+input = "Good morning"
+args = { lang = "Japanese" }
+expect_contains = ["おはよう"]
+
+[[cases]]
+input = "Good night"
+expect_regex = ["おやすみ"]
+`,
+			wantNames: []string{"greeting", "case 2"},
+		},
+		{
+			name:    "no expectations",
+			content: "[[cases]]\ninput = \"hello\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			content: "[[cases]]\nexpect_regex = [\"(\"]\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "translate"+TestFileSuffix)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := LoadTestFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTestFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, tc := range file.Cases {
+				names = append(names, tc.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("LoadTestFile() cases = %v, want %v", names, tt.wantNames)
+			}
+			if file.Cases[0].Args["lang"] != "Japanese" {
+				t.Errorf("LoadTestFile() args = %v, want lang:Japanese", file.Cases[0].Args)
+			}
+		})
+	}
+}
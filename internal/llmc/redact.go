@@ -0,0 +1,139 @@
+package llmc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// RedactedText replaces redacted matches in outgoing messages
+const RedactedText = "[REDACTED]"
+
+// BuiltinRedactPatterns match common credential formats
+var BuiltinRedactPatterns = []string{
+	`sk-[A-Za-z0-9_-]{20,}`,                   // OpenAI and Anthropic API keys
+	`AIza[0-9A-Za-z_-]{35}`,                   // Google API keys
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,           // AWS access key IDs
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,          // GitHub tokens
+	`\bgithub_pat_[A-Za-z0-9_]{22,}`,          // GitHub fine-grained tokens
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}`,          // Slack tokens
+	`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`, // Bearer tokens in headers
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`, // Private keys
+}
+
+// Redactor replaces matches of credential patterns with RedactedText
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a Redactor for the built-in patterns and the given custom patterns
+func NewRedactor(customPatterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range append(append([]string{}, BuiltinRedactPatterns...), customPatterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns text with all matches replaced by RedactedText and the number of replacements
+func (r *Redactor) Redact(text string) (string, int) {
+	count := 0
+	for _, re := range r.patterns {
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return RedactedText
+		})
+	}
+	return text, count
+}
+
+// RedactNotifyFunc is called with the number of replacements when an outgoing request was redacted
+type RedactNotifyFunc func(count int)
+
+// RedactingProvider wraps a provider and redacts the system prompt and messages
+// before they are sent. Messages stored by the caller are not modified.
+type RedactingProvider struct {
+	Provider
+	redactor *Redactor
+	notify   RedactNotifyFunc
+}
+
+// NewRedactingProvider creates a RedactingProvider. notify can be nil.
+func NewRedactingProvider(provider Provider, redactor *Redactor, notify RedactNotifyFunc) *RedactingProvider {
+	return &RedactingProvider{
+		Provider: provider,
+		redactor: redactor,
+		notify:   notify,
+	}
+}
+
+// Unwrap returns the wrapped provider
+func (r *RedactingProvider) Unwrap() Provider {
+	return r.Provider
+}
+
+// Chat redacts the message and sends it
+func (r *RedactingProvider) Chat(message string) (string, error) {
+	return r.ChatContext(context.Background(), message)
+}
+
+// ChatWithHistory redacts the system prompt, history and new message and sends them
+func (r *RedactingProvider) ChatWithHistory(systemPrompt string, messages []Message, newMessage string) (string, error) {
+	return r.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatContext redacts the message and sends it, aborting when ctx is cancelled
+func (r *RedactingProvider) ChatContext(ctx context.Context, message string) (string, error) {
+	message, count := r.redactor.Redact(message)
+	r.report(count)
+	return r.Provider.ChatContext(ctx, message)
+}
+
+// ChatWithHistoryContext redacts the system prompt, history and new message and sends them,
+// aborting when ctx is cancelled
+func (r *RedactingProvider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []Message, newMessage string) (string, error) {
+	systemPrompt, messages, newMessage = r.redactAll(systemPrompt, messages, newMessage)
+	return r.Provider.ChatWithHistoryContext(ctx, systemPrompt, messages, newMessage)
+}
+
+// ChatStream redacts the system prompt, history and new message and streams the response.
+// The wrapped provider must implement StreamingProvider.
+func (r *RedactingProvider) ChatStream(ctx context.Context, systemPrompt string, messages []Message, newMessage string, onEvent func(StreamEvent) error) (string, error) {
+	streamer, ok := r.Provider.(StreamingProvider)
+	if !ok {
+		return "", ErrStreamingNotSupported
+	}
+	systemPrompt, messages, newMessage = r.redactAll(systemPrompt, messages, newMessage)
+	return streamer.ChatStream(ctx, systemPrompt, messages, newMessage, onEvent)
+}
+
+// redactAll redacts the system prompt, a copy of the history and the new message
+func (r *RedactingProvider) redactAll(systemPrompt string, messages []Message, newMessage string) (string, []Message, string) {
+	total := 0
+	systemPrompt, count := r.redactor.Redact(systemPrompt)
+	total += count
+
+	redacted := make([]Message, len(messages))
+	for i, msg := range messages {
+		redacted[i] = msg
+		redacted[i].Content, count = r.redactor.Redact(msg.Content)
+		total += count
+	}
+
+	newMessage, count = r.redactor.Redact(newMessage)
+	total += count
+
+	r.report(total)
+	return systemPrompt, redacted, newMessage
+}
+
+// report calls notify if anything was redacted
+func (r *RedactingProvider) report(count int) {
+	if count > 0 && r.notify != nil {
+		r.notify(count)
+	}
+}
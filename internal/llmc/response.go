@@ -0,0 +1,71 @@
+package llmc
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxFirstLineLength limits the part of a response body shown in NonJSONResponseError
+const maxFirstLineLength = 200
+
+// NonJSONResponseError returns an error if a successful response is not JSON but, for example,
+// an HTML page served by a proxy or a login page, or a plain text message of a portal, which
+// happens with a wrong base URL or an authentication redirect. A response is not JSON when its
+// Content-Type is set to another media type than JSON (or an event stream), or when its body
+// is markup. It returns nil otherwise. body can be nil (e.g. for a stream that
+// has not been read yet), in which case only the content type is checked. With debug, the
+// error includes the first line of the body.
+func NonJSONResponseError(resp *http.Response, body []byte, debug bool) error {
+	contentType := resp.Header.Get("Content-Type")
+	if !isNonJSONContentType(contentType) && !bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		return nil
+	}
+
+	if contentType == "" {
+		contentType = "not set"
+	}
+	message := fmt.Sprintf("received a non-JSON response (Content-Type: %s), likely a wrong base URL or an authentication redirect", contentType)
+	if resp.Request != nil && resp.Request.Response != nil {
+		// The request was redirected; the query is left out since it can hold an API key
+		u := *resp.Request.URL
+		u.RawQuery = ""
+		message += fmt.Sprintf(" (redirected to %s)", u.Redacted())
+	}
+	switch {
+	case body == nil:
+	case debug:
+		message += fmt.Sprintf("\nFirst line: %s", firstLine(body))
+	default:
+		message += ". Use --verbose for details"
+	}
+	return NewConfigError("%s", message)
+}
+
+// isNonJSONContentType reports whether contentType is set to a media type other than
+// application/json, *+json or text/event-stream (for streamed responses).
+// An empty or unparsable Content-Type is not reported.
+func isNonJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") && mediaType != "text/event-stream"
+}
+
+// firstLine returns the first non-empty line of body, shortened to maxFirstLineLength
+func firstLine(body []byte) string {
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > maxFirstLineLength {
+			line = line[:maxFirstLineLength] + "..."
+		}
+		return line
+	}
+	return "(empty)"
+}
@@ -0,0 +1,100 @@
+package llmc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNonJSONResponseError(t *testing.T) {
+	const loginPage = "\n<!DOCTYPE html>\n<html><head><title>Sign in</title></head></html>\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, loginPage)
+	})
+	mux.HandleFunc("/v1/responses", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login?next=secret", http.StatusFound)
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, loginPage)
+	})
+	mux.HandleFunc("/untyped", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		io.WriteString(w, loginPage)
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok":true}`)
+	})
+	mux.HandleFunc("/problem", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		io.WriteString(w, `{"ok":true}`)
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {}\n\n")
+	})
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, "Service is under maintenance\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		debug    bool
+		wantErr  bool
+		contains []string
+		excludes []string
+	}{
+		{path: "/json"},
+		{path: "/problem"},
+		{path: "/stream"},
+		{path: "/text", wantErr: true, contains: []string{"non-JSON response", "Content-Type: text/plain; charset=utf-8", "--verbose"}},
+		{path: "/text", debug: true, wantErr: true, contains: []string{"First line: Service is under maintenance"}},
+		{path: "/html", wantErr: true, contains: []string{"non-JSON response", "Content-Type: text/html", "--verbose"}, excludes: []string{"<html>"}},
+		{path: "/html", debug: true, wantErr: true, contains: []string{"First line: <!DOCTYPE html>"}},
+		{path: "/untyped", wantErr: true, contains: []string{"non-JSON response"}},
+		{path: "/v1/responses", wantErr: true, contains: []string{"redirected to " + server.URL + "/login"}, excludes: []string{"secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			err = NonJSONResponseError(resp, body, tt.debug)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NonJSONResponseError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Errorf("NonJSONResponseError() error type = %T, want *ConfigError", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("NonJSONResponseError() = %q, want it to contain %q", err, s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(err.Error(), s) {
+					t.Errorf("NonJSONResponseError() = %q, want it not to contain %q", err, s)
+				}
+			}
+		})
+	}
+}
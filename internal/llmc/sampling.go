@@ -0,0 +1,65 @@
+package llmc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SamplingParams holds optional sampling parameters.
+// A nil field means the provider default is used.
+type SamplingParams struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// Merge returns a copy of p with the fields set in override replacing those of p
+func (p SamplingParams) Merge(override SamplingParams) SamplingParams {
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if override.MaxTokens != nil {
+		p.MaxTokens = override.MaxTokens
+	}
+	return p
+}
+
+// IsZero reports whether no parameter is set
+func (p SamplingParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxTokens == nil
+}
+
+// Validate checks that the parameters are within the ranges accepted by all providers
+func (p SamplingParams) Validate() error {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *p.Temperature)
+	}
+	if p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %g", *p.TopP)
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be greater than 0, got %d", *p.MaxTokens)
+	}
+	return nil
+}
+
+// String returns the parameters that are set (e.g., "temperature=0.7, max_tokens=1024")
+func (p SamplingParams) String() string {
+	var parts []string
+	if p.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature=%g", *p.Temperature))
+	}
+	if p.TopP != nil {
+		parts = append(parts, fmt.Sprintf("top_p=%g", *p.TopP))
+	}
+	if p.MaxTokens != nil {
+		parts = append(parts, fmt.Sprintf("max_tokens=%d", *p.MaxTokens))
+	}
+	if len(parts) == 0 {
+		return "(provider defaults)"
+	}
+	return strings.Join(parts, ", ")
+}
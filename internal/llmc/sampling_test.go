@@ -0,0 +1,49 @@
+package llmc
+
+import "testing"
+
+func TestSamplingParamsMerge(t *testing.T) {
+	temp, topP, override := 0.2, 0.9, 1.0
+	maxTokens := 512
+
+	base := SamplingParams{Temperature: &temp, TopP: &topP}
+	got := base.Merge(SamplingParams{Temperature: &override, MaxTokens: &maxTokens})
+
+	if got.Temperature == nil || *got.Temperature != 1.0 {
+		t.Errorf("Merge() temperature = %v, want 1.0", got.Temperature)
+	}
+	if got.TopP == nil || *got.TopP != 0.9 {
+		t.Errorf("Merge() top_p = %v, want 0.9", got.TopP)
+	}
+	if got.MaxTokens == nil || *got.MaxTokens != 512 {
+		t.Errorf("Merge() max_tokens = %v, want 512", got.MaxTokens)
+	}
+	if *base.Temperature != 0.2 {
+		t.Errorf("Merge() modified the receiver")
+	}
+}
+
+func TestSamplingParamsValidate(t *testing.T) {
+	tooHot, negative, half := 2.5, -0.1, 0.5
+	zero := 0
+
+	tests := []struct {
+		name    string
+		params  SamplingParams
+		wantErr bool
+	}{
+		{name: "empty", params: SamplingParams{}},
+		{name: "valid", params: SamplingParams{Temperature: &half, TopP: &half}},
+		{name: "temperature too high", params: SamplingParams{Temperature: &tooHot}, wantErr: true},
+		{name: "negative top_p", params: SamplingParams{TopP: &negative}, wantErr: true},
+		{name: "zero max_tokens", params: SamplingParams{MaxTokens: &zero}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
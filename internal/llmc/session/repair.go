@@ -0,0 +1,190 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/fsutil"
+)
+
+// RecoveredSuffix is appended to the session ID in the file name of a recovered session
+const RecoveredSuffix = ".recovered"
+
+// requiredFields are the session fields reported as lost when they cannot be recovered
+var requiredFields = []string{"id", "model", "created_at", "updated_at", "messages"}
+
+// RecoveryReport describes what could be recovered from a corrupted session file
+type RecoveryReport struct {
+	RecoveredFields   []string // Top-level fields that were recovered
+	LostFields        []string // Required fields that were missing or unreadable
+	RecoveredMessages int      // Number of complete messages recovered
+	MessagesTruncated bool     // The messages list was cut off (messages after the recovered ones are lost)
+}
+
+// RecoverSession salvages the readable parts of a corrupted session file.
+// Fields are read in order until the data becomes unreadable (e.g. a truncated write);
+// complete messages before that point are kept. id is used when the ID itself is lost.
+func RecoverSession(id string, data []byte) (*Session, *RecoveryReport, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("session file does not contain a JSON object")
+	}
+
+	session := &Session{}
+	report := &RecoveryReport{}
+	recovered := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, ok := tok.(string)
+		if !ok {
+			break
+		}
+
+		// Messages are recovered one by one so that a truncated list keeps its complete messages
+		if key == "messages" {
+			messages, complete := recoverMessages(dec)
+			session.Messages = messages
+			report.RecoveredMessages = len(messages)
+			report.MessagesTruncated = !complete
+			recovered[key] = true
+			if !complete {
+				break
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			break
+		}
+		field, _ := json.Marshal(map[string]json.RawMessage{key: raw})
+		if err := json.Unmarshal(field, session); err == nil {
+			recovered[key] = true
+		}
+	}
+
+	for key := range recovered {
+		report.RecoveredFields = append(report.RecoveredFields, key)
+	}
+	sort.Strings(report.RecoveredFields)
+	for _, key := range requiredFields {
+		if !recovered[key] {
+			report.LostFields = append(report.LostFields, key)
+		}
+	}
+
+	// Fill in lost required fields so that the session can be loaded again
+	if session.ID == "" {
+		session.ID = id
+	}
+	if session.Messages == nil {
+		session.Messages = []llmc.Message{}
+	}
+	if session.UpdatedAt.IsZero() {
+		session.UpdatedAt = session.CreatedAt
+		for _, msg := range session.Messages {
+			if msg.Timestamp.After(session.UpdatedAt) {
+				session.UpdatedAt = msg.Timestamp
+			}
+		}
+	}
+
+	return session, report, nil
+}
+
+// recoverMessages decodes the messages array at the current position of dec.
+// It returns the complete messages and whether the whole array could be read.
+func recoverMessages(dec *json.Decoder) ([]llmc.Message, bool) {
+	messages := []llmc.Message{}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return messages, false
+	}
+	for dec.More() {
+		var msg llmc.Message
+		if err := dec.Decode(&msg); err != nil {
+			return messages, false
+		}
+		messages = append(messages, msg)
+	}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim(']') {
+		return messages, false
+	}
+	return messages, true
+}
+
+// ListCorruptedSessions returns the IDs of session files that cannot be parsed
+func ListCorruptedSessions() ([]string, error) {
+	sessionDir, err := GetSessionDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(sessionDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isSessionFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sessionDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var session Session
+		if json.Unmarshal(data, &session) != nil {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	return ids, nil
+}
+
+// RepairSession recovers the corrupted session file with the given full ID and writes
+// the result to <id>.recovered.json next to it, leaving the original file untouched.
+// It returns the recovered session, the recovery report and the path of the written file.
+func RepairSession(id string) (*Session, *RecoveryReport, string, error) {
+	sessionDir, err := GetSessionDir()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(sessionDir, id+".json"))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	session, report, err := RecoverSession(id, data)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	out, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to serialize session: %w", err)
+	}
+	recoveredFile := filepath.Join(sessionDir, id+RecoveredSuffix+".json")
+	if err := fsutil.WriteFileAtomic(recoveredFile, out, 0644); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to write recovered session file: %w", err)
+	}
+
+	return session, report, recoveredFile, nil
+}
+
+// isSessionFile reports whether name is the file name of a session (not a recovered copy)
+func isSessionFile(name string) bool {
+	return strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, RecoveredSuffix+".json")
+}
@@ -0,0 +1,167 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// truncatedSession returns the JSON of a session with three messages cut off at the given marker
+func truncatedSession(t *testing.T, cutBefore string) (*Session, []byte) {
+	t.Helper()
+	sess := NewSession("openai:gpt-4")
+	sess.Name = "work"
+	sess.AddMessage("user", "first question")
+	sess.AddMessage("assistant", "first answer")
+	sess.AddMessage("user", "second question")
+
+	data, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.Index(string(data), cutBefore)
+	if i < 0 {
+		t.Fatalf("marker %q not found in session JSON", cutBefore)
+	}
+	return sess, data[:i]
+}
+
+func TestRecoverSession(t *testing.T) {
+	tests := []struct {
+		name          string
+		cutBefore     string
+		wantMessages  int
+		wantTruncated bool
+		wantLost      []string
+	}{
+		{
+			name:          "truncated inside a message",
+			cutBefore:     "second question",
+			wantMessages:  2,
+			wantTruncated: true,
+		},
+		{
+			name:          "truncated before messages",
+			cutBefore:     `"messages"`,
+			wantMessages:  0,
+			wantTruncated: false,
+			wantLost:      []string{"messages"},
+		},
+		{
+			name:          "truncated before updated_at",
+			cutBefore:     `"updated_at"`,
+			wantMessages:  0,
+			wantTruncated: false,
+			wantLost:      []string{"updated_at", "messages"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig, data := truncatedSession(t, tt.cutBefore)
+
+			sess, report, err := RecoverSession(orig.ID, data)
+			if err != nil {
+				t.Fatalf("RecoverSession() error = %v", err)
+			}
+			if sess.ID != orig.ID || sess.Name != "work" || sess.Model != "openai:gpt-4" {
+				t.Errorf("RecoverSession() = %+v, want id, name and model recovered", sess)
+			}
+			if len(sess.Messages) != tt.wantMessages || report.RecoveredMessages != tt.wantMessages {
+				t.Errorf("RecoverSession() recovered %d messages (report %d), want %d", len(sess.Messages), report.RecoveredMessages, tt.wantMessages)
+			}
+			if report.MessagesTruncated != tt.wantTruncated {
+				t.Errorf("RecoverSession() MessagesTruncated = %v, want %v", report.MessagesTruncated, tt.wantTruncated)
+			}
+			if !reflect.DeepEqual(report.LostFields, tt.wantLost) {
+				t.Errorf("RecoverSession() LostFields = %v, want %v", report.LostFields, tt.wantLost)
+			}
+			if sess.Messages == nil || sess.UpdatedAt.IsZero() {
+				t.Errorf("RecoverSession() left required fields empty: messages=%v updated_at=%v", sess.Messages, sess.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestRecoverSessionLostID(t *testing.T) {
+	data := []byte(`{"model": "openai:gpt-4", "created_at": "2025-01-02T03:04:05Z", "messages": [{"role": "user", "content": "hi", "timestamp": "2025-01-02T03:05:00Z"}, {"role": "assi`)
+
+	sess, report, err := RecoverSession("550e8400-e29b-41d4-a716-446655440000", data)
+	if err != nil {
+		t.Fatalf("RecoverSession() error = %v", err)
+	}
+	if sess.ID != "550e8400-e29b-41d4-a716-446655440000" {
+		t.Errorf("RecoverSession() ID = %q, want the fallback ID", sess.ID)
+	}
+	if want := time.Date(2025, 1, 2, 3, 5, 0, 0, time.UTC); !sess.UpdatedAt.Equal(want) {
+		t.Errorf("RecoverSession() UpdatedAt = %v, want last message time %v", sess.UpdatedAt, want)
+	}
+	if want := []string{"id", "updated_at"}; !reflect.DeepEqual(report.LostFields, want) {
+		t.Errorf("RecoverSession() LostFields = %v, want %v", report.LostFields, want)
+	}
+}
+
+func TestRecoverSessionNotJSON(t *testing.T) {
+	if _, _, err := RecoverSession("id", []byte("garbage")); err == nil {
+		t.Error("RecoverSession() error = nil, want an error")
+	}
+}
+
+func TestRepairSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "llmc", "sessions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	orig, data := truncatedSession(t, "second question")
+	if err := os.WriteFile(filepath.Join(dir, orig.ID+".json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := ListCorruptedSessions()
+	if err != nil {
+		t.Fatalf("ListCorruptedSessions() error = %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{orig.ID}) {
+		t.Fatalf("ListCorruptedSessions() = %v, want [%s]", ids, orig.ID)
+	}
+
+	_, report, path, err := RepairSession(orig.ID)
+	if err != nil {
+		t.Fatalf("RepairSession() error = %v", err)
+	}
+	if report.RecoveredMessages != 2 {
+		t.Errorf("RepairSession() recovered %d messages, want 2", report.RecoveredMessages)
+	}
+	if path != filepath.Join(dir, orig.ID+".recovered.json") {
+		t.Errorf("RepairSession() path = %q", path)
+	}
+
+	// The recovered file is valid and the original is left untouched
+	recovered, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sess Session
+	if err := json.Unmarshal(recovered, &sess); err != nil {
+		t.Errorf("recovered file is not a valid session: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, orig.ID+".json")); string(got) != string(data) {
+		t.Error("RepairSession() modified the original file")
+	}
+
+	// Recovered copies are not listed as sessions
+	sessions, err := ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("ListSessions() = %d sessions, want 0 (recovered copies are not sessions)", len(sessions))
+	}
+}
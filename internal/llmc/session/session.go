@@ -1,6 +1,8 @@
 package session
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +20,17 @@ type Session struct {
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	Messages     []llmc.Message `json:"messages"`
+	Tags         []string       `json:"tags,omitempty"` // Optional tags for organizing sessions
+
+	// BranchedAt is the number of messages copied from the parent when the session was
+	// branched from it (0 = not a branch; a child without it starts with a summary of the parent)
+	BranchedAt int `json:"branched_at,omitempty"`
+
+	// DefaultArgs holds the template arguments given at creation; they are reapplied to every turn
+	DefaultArgs map[string]string `json:"default_args,omitempty"`
+
+	// Sampling holds the sampling parameters the session was created with (nil for sessions created before they were stored)
+	Sampling *llmc.SamplingParams `json:"sampling,omitempty"`
 }
 
 // NewSession creates a new session with the given model in "provider:model" format
@@ -36,6 +49,71 @@ func NewSession(model string) *Session {
 	}
 }
 
+// Branch returns a new unsaved session that continues the conversation of s after its
+// first n messages, with s as parent and the same model, prompt and settings
+func (s *Session) Branch(n int) (*Session, error) {
+	if n < 1 || n > len(s.Messages) {
+		return nil, fmt.Errorf("message number %d is out of range (session %s has %d messages)", n, s.GetShortID(), len(s.Messages))
+	}
+
+	branch := NewSession(s.Model)
+	branch.ParentID = s.ID
+	branch.BranchedAt = n
+	branch.SystemPrompt = s.SystemPrompt
+	branch.TemplateName = s.TemplateName
+	branch.DefaultArgs = s.DefaultArgs
+	branch.Sampling = s.Sampling
+	branch.Messages = append([]llmc.Message{}, s.Messages[:n]...)
+	return branch, nil
+}
+
+// DeleteMessages removes messages first through last (1-based, inclusive) from the session
+func (s *Session) DeleteMessages(first, last int) error {
+	if first < 1 || last < first || last > len(s.Messages) {
+		if first == last {
+			return fmt.Errorf("message number %d is out of range (session %s has %d messages)", first, s.GetShortID(), len(s.Messages))
+		}
+		return fmt.Errorf("message range %d-%d is out of range (session %s has %d messages)", first, last, s.GetShortID(), len(s.Messages))
+	}
+	s.Messages = append(s.Messages[:first-1:first-1], s.Messages[last:]...)
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// TrimEmptyMessages removes the messages whose content is empty or only whitespace
+// (e.g. left behind by a failed turn), which providers may reject. Messages of the
+// same role that end up next to each other are merged, so that the roles alternate
+// as they did before. It returns the number of removed and merged messages.
+func (s *Session) TrimEmptyMessages() (removed, merged int) {
+	var kept []llmc.Message
+	droppedSincePrev := false
+	for _, msg := range s.Messages {
+		if strings.TrimSpace(msg.Content) == "" {
+			removed++
+			droppedSincePrev = true
+			continue
+		}
+		if n := len(kept); n > 0 && droppedSincePrev && kept[n-1].Role == msg.Role {
+			kept[n-1].Content += "\n\n" + msg.Content
+			merged++
+		} else {
+			kept = append(kept, msg)
+		}
+		droppedSincePrev = false
+	}
+	if removed > 0 {
+		s.Messages = kept
+		s.UpdatedAt = time.Now()
+	}
+	return removed, merged
+}
+
+// StartsWithSummary reports whether the first message of the session is a summary of
+// its parent, as in a session created by summarizing the parent
+func (s *Session) StartsWithSummary() bool {
+	return s.ParentID != "" && s.BranchedAt == 0 && len(s.Messages) > 0
+}
+
 // AddMessage adds a new message to the session
 func (s *Session) AddMessage(role, content string) {
 	s.Messages = append(s.Messages, llmc.Message{
@@ -46,6 +124,56 @@ func (s *Session) AddMessage(role, content string) {
 	s.UpdatedAt = time.Now()
 }
 
+// AddAssistantMessage adds an assistant message generated by model ("provider:model" format)
+func (s *Session) AddAssistantMessage(content, model string) {
+	s.AddMessage("assistant", content)
+	s.Messages[len(s.Messages)-1].Model = model
+}
+
+// AddTags adds the tags the session does not have yet and returns them.
+// Tags are case-sensitive; surrounding whitespace is trimmed and empty tags are ignored.
+func (s *Session) AddTags(tags ...string) []string {
+	var added []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || s.HasTag(tag) {
+			continue
+		}
+		s.Tags = append(s.Tags, tag)
+		added = append(added, tag)
+	}
+	return added
+}
+
+// RemoveTags removes the given tags from the session and returns the ones it had
+func (s *Session) RemoveTags(tags ...string) []string {
+	var removed []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		for i, t := range s.Tags {
+			if t == tag {
+				s.Tags = append(s.Tags[:i], s.Tags[i+1:]...)
+				removed = append(removed, tag)
+				break
+			}
+		}
+	}
+	if len(s.Tags) == 0 {
+		s.Tags = nil
+	}
+	return removed
+}
+
+// HasTag reports whether the session has tag
+func (s *Session) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // GetShortID returns the shortened session ID (first 8 characters)
 func (s *Session) GetShortID() string {
 	if len(s.ID) >= 8 {
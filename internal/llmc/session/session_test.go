@@ -0,0 +1,399 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+)
+
+func TestSessionSamplingPersistence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	temp, maxTokens := 0.3, 1024
+	sess := NewSession("openai:gpt-4")
+	sess.Sampling = &llmc.SamplingParams{Temperature: &temp, MaxTokens: &maxTokens}
+	if err := SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	loaded, err := LoadSession(sess.ID)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Sampling == nil {
+		t.Fatal("LoadSession() sampling = nil, want saved parameters")
+	}
+	if loaded.Sampling.Temperature == nil || *loaded.Sampling.Temperature != 0.3 {
+		t.Errorf("temperature = %v, want 0.3", loaded.Sampling.Temperature)
+	}
+	if loaded.Sampling.TopP != nil {
+		t.Errorf("top_p = %v, want nil", *loaded.Sampling.TopP)
+	}
+	if loaded.Sampling.MaxTokens == nil || *loaded.Sampling.MaxTokens != 1024 {
+		t.Errorf("max_tokens = %v, want 1024", loaded.Sampling.MaxTokens)
+	}
+}
+
+func TestLoadSessionWithoutSampling(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Session file written before sampling parameters were stored
+	dir := filepath.Join(home, ".config", "llmc", "sessions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	data := `{"id":"` + id + `","model":"openai:gpt-4","messages":[]}`
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSession(id)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Sampling != nil {
+		t.Errorf("sampling = %+v, want nil", loaded.Sampling)
+	}
+}
+
+func TestBackupSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sess := NewSession("openai:gpt-4")
+	sess.AddMessage("user", "original")
+	if err := SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	backupFile, err := BackupSession(sess.ID)
+	if err != nil {
+		t.Fatalf("BackupSession() error = %v", err)
+	}
+
+	// Change the session; the backup keeps the original content
+	sess.Messages[0].Content = "changed"
+	if err := SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	data, err := os.ReadFile(backupFile)
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if !strings.Contains(string(data), "original") || strings.Contains(string(data), "changed") {
+		t.Errorf("backup content = %s, want the original session", data)
+	}
+
+	// Backups are not listed as sessions
+	sessions, err := ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("ListSessions() = %d sessions, want 1", len(sessions))
+	}
+}
+
+func TestListSessionsWithErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	valid := NewSession("openai:gpt-4")
+	valid.AddMessage("user", "hello")
+	if err := SaveSession(valid); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	sessionDir, err := GetSessionDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	corruptFile := filepath.Join(sessionDir, "00000000-0000-0000-0000-000000000000.json")
+	if err := os.WriteFile(corruptFile, []byte(`{"id": "00000000-0000-0000-0000-000000000000", "messages": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, fileErrors, err := ListSessionsWithErrors()
+	if err != nil {
+		t.Fatalf("ListSessionsWithErrors() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != valid.ID {
+		t.Errorf("ListSessionsWithErrors() sessions = %v, want only %s", sessions, valid.ID)
+	}
+	if len(fileErrors) != 1 {
+		t.Fatalf("ListSessionsWithErrors() errors = %v, want 1", fileErrors)
+	}
+	if fileErrors[0].Path != corruptFile || fileErrors[0].Err == nil {
+		t.Errorf("ListSessionsWithErrors() error = %v, want parse error for %s", fileErrors[0], corruptFile)
+	}
+
+	// ListSessions keeps listing the valid sessions
+	sessions, err = ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("ListSessions() = %d sessions, want 1", len(sessions))
+	}
+}
+
+func TestSessionTags(t *testing.T) {
+	sess := NewSession("openai:gpt-4")
+
+	added := sess.AddTags("work", " go ", "", "work")
+	if !reflect.DeepEqual(added, []string{"work", "go"}) || !reflect.DeepEqual(sess.Tags, []string{"work", "go"}) {
+		t.Fatalf("AddTags() = %q, tags = %q, want work and go once", added, sess.Tags)
+	}
+	if added := sess.AddTags("go"); added != nil {
+		t.Errorf("AddTags() of a duplicate tag = %q, want none added", added)
+	}
+	if !sess.HasTag("go") || sess.HasTag("Go") {
+		t.Errorf("HasTag() is not an exact match")
+	}
+
+	removed := sess.RemoveTags("work", "missing")
+	if !reflect.DeepEqual(removed, []string{"work"}) || !reflect.DeepEqual(sess.Tags, []string{"go"}) {
+		t.Errorf("RemoveTags() = %q, tags = %q, want work removed", removed, sess.Tags)
+	}
+	sess.RemoveTags("go")
+	if sess.Tags != nil {
+		t.Errorf("tags = %q after removing all, want nil (omitted from the file)", sess.Tags)
+	}
+}
+
+func TestSessionTagsPersistence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	sess := NewSession("openai:gpt-4")
+	sess.AddTags("notes")
+	if err := SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	loaded, err := LoadSession(sess.ID)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Tags, []string{"notes"}) {
+		t.Errorf("loaded tags = %q, want [notes]", loaded.Tags)
+	}
+
+	// Session file written before tags were stored
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	data := `{"id":"` + id + `","model":"openai:gpt-4","messages":[]}`
+	if err := os.WriteFile(filepath.Join(home, ".config", "llmc", "sessions", id+".json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old, err := LoadSession(id)
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v for a session without tags", err)
+	}
+	if old.Tags != nil {
+		t.Errorf("tags = %q, want nil", old.Tags)
+	}
+}
+
+func TestSessionBranch(t *testing.T) {
+	sess := NewSession("anthropic:claude-3-5-sonnet")
+	sess.SystemPrompt = "Be brief"
+	sess.TemplateName = "review"
+	for _, content := range []string{"q1", "a1", "q2", "a2"} {
+		sess.AddMessage("user", content)
+	}
+
+	branch, err := sess.Branch(2)
+	if err != nil {
+		t.Fatalf("Branch() error = %v", err)
+	}
+	if branch.ID == sess.ID || branch.ParentID != sess.ID || branch.BranchedAt != 2 {
+		t.Errorf("Branch() id = %s, parent = %s, branched at %d; want a new session with parent %s at 2", branch.ID, branch.ParentID, branch.BranchedAt, sess.ID)
+	}
+	if branch.Model != sess.Model || branch.SystemPrompt != sess.SystemPrompt || branch.TemplateName != sess.TemplateName {
+		t.Errorf("Branch() = %+v, want the model, system prompt and template of the source", branch)
+	}
+	if len(branch.Messages) != 2 || branch.Messages[1].Content != "a1" {
+		t.Fatalf("Branch() messages = %v, want the first 2", branch.Messages)
+	}
+	if branch.StartsWithSummary() {
+		t.Error("StartsWithSummary() = true for a branch")
+	}
+
+	// The branch does not share messages with the source
+	branch.AddMessage("user", "other path")
+	if len(sess.Messages) != 4 || sess.Messages[2].Content != "q2" {
+		t.Errorf("source messages = %v after adding to the branch, want them unchanged", sess.Messages)
+	}
+
+	for _, n := range []int{0, 5} {
+		if _, err := sess.Branch(n); err == nil {
+			t.Errorf("Branch(%d) error = nil, want out of range", n)
+		}
+	}
+}
+
+func TestSessionDeleteMessages(t *testing.T) {
+	newSession := func() *Session {
+		sess := NewSession("openai:gpt-4")
+		for _, content := range []string{"q1", "a1", "q2", "a2", "q3", "a3"} {
+			sess.AddMessage("user", content)
+		}
+		return sess
+	}
+	contents := func(sess *Session) []string {
+		var got []string
+		for _, msg := range sess.Messages {
+			got = append(got, msg.Content)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name        string
+		first, last int
+		want        []string
+		wantErr     bool
+	}{
+		{name: "single message", first: 2, last: 2, want: []string{"q1", "q2", "a2", "q3", "a3"}},
+		{name: "range", first: 3, last: 4, want: []string{"q1", "a1", "q3", "a3"}},
+		{name: "last message", first: 6, last: 6, want: []string{"q1", "a1", "q2", "a2", "q3"}},
+		{name: "all messages", first: 1, last: 6, want: nil},
+		{name: "zero", first: 0, last: 0, wantErr: true},
+		{name: "past the end", first: 7, last: 7, wantErr: true},
+		{name: "range past the end", first: 5, last: 8, wantErr: true},
+		{name: "reversed range", first: 4, last: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := newSession()
+			updatedAt := sess.UpdatedAt
+			err := sess.DeleteMessages(tt.first, tt.last)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("DeleteMessages(%d, %d) error = nil, want out of range", tt.first, tt.last)
+				}
+				if len(sess.Messages) != 6 {
+					t.Errorf("messages = %v after a failed deletion, want them unchanged", contents(sess))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeleteMessages(%d, %d) error = %v", tt.first, tt.last, err)
+			}
+			if got := contents(sess); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+			if sess.UpdatedAt.Before(updatedAt) {
+				t.Errorf("UpdatedAt = %v, want it updated", sess.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestSessionTrimEmptyMessages(t *testing.T) {
+	type msg struct{ role, content string }
+	tests := []struct {
+		name        string
+		messages    []msg
+		want        []msg
+		wantRemoved int
+		wantMerged  int
+	}{
+		{
+			name:     "no empty messages",
+			messages: []msg{{"user", "q1"}, {"assistant", "a1"}},
+			want:     []msg{{"user", "q1"}, {"assistant", "a1"}},
+		},
+		{
+			name:        "empty response merges the user messages around it",
+			messages:    []msg{{"user", "q1"}, {"assistant", ""}, {"user", "q2"}, {"assistant", "a2"}},
+			want:        []msg{{"user", "q1\n\nq2"}, {"assistant", "a2"}},
+			wantRemoved: 1,
+			wantMerged:  1,
+		},
+		{
+			name:        "empty turn keeps alternating roles",
+			messages:    []msg{{"user", "q1"}, {"assistant", "a1"}, {"user", " \n\t"}, {"assistant", ""}, {"user", "q2"}},
+			want:        []msg{{"user", "q1"}, {"assistant", "a1"}, {"user", "q2"}},
+			wantRemoved: 2,
+		},
+		{
+			name:        "adjacent messages of the same role without a removal are kept",
+			messages:    []msg{{"user", "q1"}, {"user", "q2"}, {"assistant", ""}},
+			want:        []msg{{"user", "q1"}, {"user", "q2"}},
+			wantRemoved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := NewSession("openai:gpt-4")
+			for _, m := range tt.messages {
+				sess.AddMessage(m.role, m.content)
+			}
+			removed, merged := sess.TrimEmptyMessages()
+			if removed != tt.wantRemoved || merged != tt.wantMerged {
+				t.Errorf("TrimEmptyMessages() = (%d, %d), want (%d, %d)", removed, merged, tt.wantRemoved, tt.wantMerged)
+			}
+			var got []msg
+			for _, m := range sess.Messages {
+				got = append(got, msg{m.Role, m.Content})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSessionByPrefix(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	sess := NewSession("openai:gpt-4")
+	if err := SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	// A session whose ID is not a UUID (e.g. written by hand) but starts like one
+	dir := filepath.Join(home, ".config", "llmc", "sessions")
+	oddID := "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz-copy"
+	data := `{"id":"` + oddID + `","model":"openai:gpt-4","messages":[]}`
+	if err := os.WriteFile(filepath.Join(dir, oddID+".json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		want    string
+		wantErr string
+	}{
+		{name: "valid UUID", id: sess.ID, want: sess.ID},
+		{name: "prefix", id: sess.ID[:8], want: sess.ID},
+		{name: "36-char non-UUID falls back to prefix", id: oddID[:36], want: oddID},
+		{name: "unknown UUID", id: "550e8400-e29b-41d4-a716-446655440000", wantErr: "session not found"},
+		{name: "unknown 36-char non-UUID", id: "yyyyyyyy-yyyy-yyyy-yyyy-yyyyyyyyyyyy", wantErr: "session not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := FindSessionByPrefix(tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FindSessionByPrefix(%q) error = %v, want %q", tt.id, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindSessionByPrefix(%q) error = %v", tt.id, err)
+			}
+			if found.ID != tt.want {
+				t.Errorf("FindSessionByPrefix(%q) = %s, want %s", tt.id, found.ID, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/longkey1/llmc/internal/llmc/fsutil"
 	"github.com/spf13/viper"
 )
 
@@ -83,8 +85,12 @@ func SaveSession(session *Session) error {
 	}
 
 	// Write to file (full UUID as filename)
+	// The file is replaced atomically under a lock so that concurrent llmc processes never leave a partial file
 	sessionFile := filepath.Join(sessionDir, session.ID+".json")
-	if err := os.WriteFile(sessionFile, data, 0644); err != nil {
+	err = fsutil.WithLock(sessionFile, func() error {
+		return fsutil.WriteFileAtomic(sessionFile, data, 0644)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to write session file: %w", err)
 	}
 
@@ -109,12 +115,59 @@ func LoadSession(id string) (*Session, error) {
 
 	var session Session
 	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to parse session file: %w\n\nThe session file may be corrupted.", err)
+		return nil, fmt.Errorf("failed to parse session file: %w\n\nThe session file may be corrupted. Run 'llmc sessions repair %s' to recover it.", err, id)
 	}
 
 	return &session, nil
 }
 
+// LoadRawMessage returns the JSON of the message at index (1-based) of the session with
+// the given full ID exactly as stored in the session file, including any fields not
+// known to this version
+func LoadRawMessage(id string, index int) (json.RawMessage, error) {
+	sessionDir, err := GetSessionDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(sessionDir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var raw struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse session file: %w", err)
+	}
+	if index < 1 || index > len(raw.Messages) {
+		return nil, fmt.Errorf("message %d not found (session has %d messages)", index, len(raw.Messages))
+	}
+	return raw.Messages[index-1], nil
+}
+
+// BackupSession copies the session file with the given full ID to <id>.json.bak
+// (replacing an earlier backup) and returns the path of the backup
+func BackupSession(id string) (string, error) {
+	sessionDir, err := GetSessionDir()
+	if err != nil {
+		return "", err
+	}
+
+	sessionFile := filepath.Join(sessionDir, id+".json")
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	backupFile := sessionFile + ".bak"
+	if err := fsutil.WriteFileAtomic(backupFile, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+	return backupFile, nil
+}
+
 // DeleteSession deletes a session from disk by full ID
 func DeleteSession(id string) error {
 	sessionDir, err := GetSessionDir()
@@ -133,38 +186,62 @@ func DeleteSession(id string) error {
 	return nil
 }
 
-// ListSessions returns all sessions sorted by UpdatedAt (newest first)
+// FileError describes a session file that could not be read or parsed
+type FileError struct {
+	Path string // Path of the session file
+	Err  error  // Read or parse error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// ListSessions returns all sessions sorted by UpdatedAt (newest first).
+// Session files that cannot be read are skipped; use ListSessionsWithErrors to report them.
 func ListSessions() ([]Session, error) {
+	sessions, _, err := ListSessionsWithErrors()
+	return sessions, err
+}
+
+// ListSessionsWithErrors returns all readable sessions sorted by UpdatedAt (newest first)
+// and the session files that could not be read or parsed, sorted by path
+func ListSessionsWithErrors() ([]Session, []FileError, error) {
 	sessionDir, err := GetSessionDir()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(sessionDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create session directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to create session directory: %w", err)
 	}
 
 	// Read all files in session directory
 	entries, err := os.ReadDir(sessionDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read session directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to read session directory: %w", err)
 	}
 
 	var sessions []Session
+	var fileErrors []FileError
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+		if entry.IsDir() || !isSessionFile(entry.Name()) {
 			continue
 		}
 
-		// Extract ID from filename (remove .json extension)
-		id := strings.TrimSuffix(entry.Name(), ".json")
-		session, err := LoadSession(id)
+		sessionFile := filepath.Join(sessionDir, entry.Name())
+		data, err := os.ReadFile(sessionFile)
 		if err != nil {
-			// Skip corrupted session files
+			fileErrors = append(fileErrors, FileError{Path: sessionFile, Err: err})
 			continue
 		}
-		sessions = append(sessions, *session)
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			// Corrupted session files can be recovered with 'llmc sessions repair'
+			fileErrors = append(fileErrors, FileError{Path: sessionFile, Err: err})
+			continue
+		}
+		sessions = append(sessions, session)
 	}
 
 	// Sort by UpdatedAt (newest first)
@@ -172,7 +249,7 @@ func ListSessions() ([]Session, error) {
 		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
 	})
 
-	return sessions, nil
+	return sessions, fileErrors, nil
 }
 
 // FindSessionByPrefix finds a session by short ID prefix (minimum 4 characters)
@@ -189,9 +266,15 @@ func FindSessionByPrefix(prefix string) (*Session, error) {
 		return nil, fmt.Errorf("session ID prefix must be at least 4 characters (got %d)", len(prefix))
 	}
 
-	// Check if it's a full UUID (36 characters with 4 dashes)
-	if len(prefix) == 36 && strings.Count(prefix, "-") == 4 {
-		return LoadSession(prefix)
+	// Load a full UUID directly; without a session file of that name, fall back to prefix matching
+	if isFullUUID(prefix) {
+		sessionDir, err := GetSessionDir()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(filepath.Join(sessionDir, prefix+".json")); err == nil {
+			return LoadSession(prefix)
+		}
 	}
 
 	// Search for prefix matches
@@ -221,6 +304,16 @@ func FindSessionByPrefix(prefix string) (*Session, error) {
 	return &matches[0], nil
 }
 
+// isFullUUID reports whether id is a UUID in its canonical 36-character form
+// (e.g. 550e8400-e29b-41d4-a716-446655440000)
+func isFullUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
 // GetLatestSession returns the most recently updated session
 func GetLatestSession() (*Session, error) {
 	sessions, err := ListSessions()
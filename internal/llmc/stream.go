@@ -0,0 +1,86 @@
+package llmc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrStreamingNotSupported is returned when streaming is requested from a provider without streaming support
+var ErrStreamingNotSupported = errors.New("streaming is not supported by this provider")
+
+// Stream event types
+const (
+	StreamEventDelta = "delta" // A chunk of response text
+	StreamEventDone  = "done"  // The response is complete (with usage if reported)
+	StreamEventError = "error" // The request failed
+)
+
+// Usage represents token usage reported by a provider
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// StreamEvent represents an event emitted while a response is streamed
+type StreamEvent struct {
+	Type         string `json:"type"`                    // StreamEventDelta, StreamEventDone or StreamEventError
+	Text         string `json:"text,omitempty"`          // Text chunk (delta)
+	Usage        *Usage `json:"usage,omitempty"`         // Token usage (done, if reported by the provider)
+	FinishReason string `json:"finish_reason,omitempty"` // Provider-specific finish reason (done)
+	Error        string `json:"error,omitempty"`         // Error message (error)
+}
+
+// StreamingProvider is implemented by providers that can stream responses.
+type StreamingProvider interface {
+	Provider
+
+	// ChatStream sends a message with conversation history and streams the response.
+	// onEvent is called for each text delta and once with a done event at the end;
+	// returning an error from onEvent aborts the stream.
+	// The full response text is returned. Cancelling ctx aborts the request.
+	ChatStream(ctx context.Context, systemPrompt string, messages []Message, newMessage string, onEvent func(StreamEvent) error) (string, error)
+}
+
+// ReadSSE reads a Server-Sent Events stream from r and calls fn with the
+// event name (can be empty) and data of each event.
+// Reading stops at the end of the stream or when fn returns an error.
+func ReadSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment line (keep-alive)
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
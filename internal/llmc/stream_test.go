@@ -0,0 +1,37 @@
+package llmc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive\n" +
+		"event: message_start\n" +
+		"data: {\"a\":1}\n" +
+		"\n" +
+		"data: line1\n" +
+		"data: line2\n" +
+		"\n" +
+		"data: last"
+
+	type sseEvent struct{ event, data string }
+	var got []sseEvent
+	err := ReadSSE(strings.NewReader(input), func(event, data string) error {
+		got = append(got, sseEvent{event, data})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadSSE() error = %v", err)
+	}
+
+	want := []sseEvent{
+		{"message_start", `{"a":1}`},
+		{"", "line1\nline2"},
+		{"", "last"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadSSE() events = %q, want %q", got, want)
+	}
+}
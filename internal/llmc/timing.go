@@ -0,0 +1,178 @@
+package llmc
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestTiming holds where the time of an HTTP request was spent.
+// DNS, Connect and TLS are zero when an existing connection was reused.
+type RequestTiming struct {
+	DNS     time.Duration // DNS lookup
+	Connect time.Duration // TCP connection
+	TLS     time.Duration // TLS handshake
+	TTFB    time.Duration // From sending the request to the first response byte
+	Total   time.Duration // From sending the request to reading the whole response body
+	Reused  bool          // An idle connection was reused
+}
+
+// String returns a compact summary such as "dns=12ms connect=30ms ttfb=420ms total=1.8s"
+func (t RequestTiming) String() string {
+	var parts []string
+	if t.DNS > 0 {
+		parts = append(parts, "dns="+formatTiming(t.DNS))
+	}
+	if t.Connect > 0 {
+		parts = append(parts, "connect="+formatTiming(t.Connect))
+	}
+	if t.TLS > 0 {
+		parts = append(parts, "tls="+formatTiming(t.TLS))
+	}
+	parts = append(parts, "ttfb="+formatTiming(t.TTFB), "total="+formatTiming(t.Total))
+	if t.Reused {
+		parts = append(parts, "reused")
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatTiming formats d in milliseconds below one second and in seconds otherwise
+func formatTiming(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// TimingTransport is an http.RoundTripper that measures each request with httptrace
+// and calls OnDone with the timing once the response body has been read or closed
+type TimingTransport struct {
+	Base   http.RoundTripper // Transport that sends the request (http.DefaultTransport if nil)
+	OnDone func(RequestTiming)
+}
+
+// NewTimingTransport creates a TimingTransport. base can be nil.
+func NewTimingTransport(base http.RoundTripper, onDone func(RequestTiming)) *TimingTransport {
+	return &TimingTransport{Base: base, OnDone: onDone}
+}
+
+// RoundTrip sends the request and records its timing
+func (t *TimingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rec := &timingRecorder{start: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), rec.clientTrace()))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		t.report(rec.finish())
+		return nil, err
+	}
+	resp.Body = &timedBody{ReadCloser: resp.Body, done: func() { t.report(rec.finish()) }}
+	return resp, nil
+}
+
+// report calls OnDone if set
+func (t *TimingTransport) report(timing RequestTiming) {
+	if t.OnDone != nil {
+		t.OnDone(timing)
+	}
+}
+
+// timingRecorder collects the trace events of a single request.
+// Trace hooks can be called from other goroutines, so access is guarded by mu.
+type timingRecorder struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       RequestTiming
+}
+
+// clientTrace returns the hooks that record into r
+func (r *timingRecorder) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.timing.DNS = time.Since(r.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.connectStart.IsZero() {
+				r.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if err == nil && r.timing.Connect == 0 {
+				r.timing.Connect = time.Since(r.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.timing.TLS = time.Since(r.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.timing.Reused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.timing.TTFB = time.Since(r.start)
+		},
+	}
+}
+
+// finish records the total time and returns the timing
+func (r *timingRecorder) finish() RequestTiming {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.Total = time.Since(r.start)
+	return r.timing
+}
+
+// timedBody calls done once, when the body has been read to the end or closed
+type timedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *timedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
@@ -0,0 +1,96 @@
+package llmc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimingTransport(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var timings []RequestTiming
+	client := &http.Client{Transport: NewTimingTransport(nil, func(timing RequestTiming) {
+		timings = append(timings, timing)
+	})}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	if len(timings) != 2 {
+		t.Fatalf("OnDone called %d times, want 2", len(timings))
+	}
+	first, second := timings[0], timings[1]
+	if first.Connect <= 0 {
+		t.Errorf("first request Connect = %v, want > 0", first.Connect)
+	}
+	if first.Reused {
+		t.Error("first request Reused = true, want false")
+	}
+	if !second.Reused {
+		t.Error("second request Reused = false, want true")
+	}
+	for i, timing := range timings {
+		if timing.TTFB < delay {
+			t.Errorf("request %d TTFB = %v, want >= %v", i, timing.TTFB, delay)
+		}
+		if timing.Total < timing.TTFB {
+			t.Errorf("request %d Total = %v, want >= TTFB %v", i, timing.Total, timing.TTFB)
+		}
+	}
+}
+
+func TestTimingTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	called := 0
+	client := &http.Client{Transport: NewTimingTransport(nil, func(RequestTiming) { called++ })}
+	if _, err := client.Get(url); err == nil {
+		t.Fatal("Get() error = nil, want connection error")
+	}
+	if called != 1 {
+		t.Errorf("OnDone called %d times, want 1", called)
+	}
+}
+
+func TestRequestTimingString(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing RequestTiming
+		want   string
+	}{
+		{
+			name:   "new connection",
+			timing: RequestTiming{DNS: 12 * time.Millisecond, Connect: 30 * time.Millisecond, TLS: 45 * time.Millisecond, TTFB: 420 * time.Millisecond, Total: 1800 * time.Millisecond},
+			want:   "dns=12ms connect=30ms tls=45ms ttfb=420ms total=1.8s",
+		},
+		{
+			name:   "reused connection",
+			timing: RequestTiming{TTFB: 420 * time.Millisecond, Total: 500 * time.Millisecond, Reused: true},
+			want:   "ttfb=420ms total=500ms reused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.timing.String(); got != tt.want {
+				t.Errorf("RequestTiming.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
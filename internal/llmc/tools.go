@@ -0,0 +1,113 @@
+package llmc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrToolsNotSupported is returned by SetTools for a provider that cannot call tools
+var ErrToolsNotSupported = errors.New("tools are not supported by this provider")
+
+// Tool is a function the model may call, described by a JSON schema of its parameters
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall is a call of a tool requested by the model. Executing it is left to the caller.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// ToolProvider is implemented by providers that can offer tools to the model.
+// When the model calls tools instead of answering, the response is empty and
+// the calls are returned by LastToolCalls.
+type ToolProvider interface {
+	Provider
+
+	// SetTools sets the tools sent with each request (nil = none)
+	SetTools(tools []Tool)
+
+	// LastToolCalls returns the tool calls of the last successful request (nil if none)
+	LastToolCalls() []ToolCall
+}
+
+// ParseTools parses tool definitions from a JSON array. Each element is either a
+// tool ({"name", "description", "parameters"}, optionally with "type": "function")
+// or a Chat Completions style {"type": "function", "function": {...}}.
+func ParseTools(data []byte) ([]Tool, error) {
+	var entries []struct {
+		Type string `json:"type"`
+		Tool
+		Function *Tool `json:"function"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid tools JSON (expected an array of tool definitions): %w", err)
+	}
+
+	tools := make([]Tool, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Type != "" && entry.Type != "function" {
+			return nil, fmt.Errorf("tool %d: unsupported type %q (only function tools are supported)", i+1, entry.Type)
+		}
+		tool := entry.Tool
+		if entry.Function != nil {
+			tool = *entry.Function
+		}
+		if tool.Name == "" {
+			return nil, fmt.Errorf("tool %d: name is required", i+1)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// SetTools offers tools to the model with each request of provider. The tools are
+// only set when every model that may answer (including fallback models) can call
+// them; otherwise ErrToolsNotSupported is returned.
+func SetTools(provider Provider, tools []Tool) error {
+	var targets []ToolProvider
+	supported := eachProvider(provider, func(p Provider) bool {
+		target, ok := p.(ToolProvider)
+		if ok {
+			targets = append(targets, target)
+		}
+		return ok
+	})
+	if !supported {
+		return ErrToolsNotSupported
+	}
+
+	for _, p := range targets {
+		p.SetTools(tools)
+	}
+	return nil
+}
+
+// LastToolCalls returns the tool calls the model requested in the last response of
+// provider. With fallback models, the calls of the model that answered are returned.
+// It returns nil if the model answered with text instead.
+func LastToolCalls(provider Provider) []ToolCall {
+	for provider != nil {
+		if f, ok := provider.(*FallbackProvider); ok {
+			if f.lastModel == "" {
+				return nil
+			}
+			provider = f.providers[f.lastIndex]
+			continue
+		}
+		if p, ok := provider.(ToolProvider); ok {
+			return p.LastToolCalls()
+		}
+		wrapper, ok := provider.(interface{ Unwrap() Provider })
+		if !ok {
+			return nil
+		}
+		provider = wrapper.Unwrap()
+	}
+	return nil
+}
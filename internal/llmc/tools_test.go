@@ -0,0 +1,80 @@
+package llmc
+
+import (
+	"errors"
+	"testing"
+)
+
+// toolProvider is a fakeProvider that supports tools
+type toolProvider struct {
+	fakeProvider
+	tools []Tool
+	calls []ToolCall
+}
+
+func (p *toolProvider) SetTools(tools []Tool)     { p.tools = tools }
+func (p *toolProvider) LastToolCalls() []ToolCall { return p.calls }
+
+func TestParseTools(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "flat", data: `[{"type":"function","name":"get_weather","parameters":{"type":"object"}}]`, wantName: "get_weather"},
+		{name: "without type", data: `[{"name":"get_weather"}]`, wantName: "get_weather"},
+		{name: "chat completions style", data: `[{"type":"function","function":{"name":"get_weather"}}]`, wantName: "get_weather"},
+		{name: "missing name", data: `[{"type":"function"}]`, wantErr: true},
+		{name: "unsupported type", data: `[{"type":"web_search","name":"x"}]`, wantErr: true},
+		{name: "not an array", data: `{"name":"get_weather"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools, err := ParseTools([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTools() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (len(tools) != 1 || tools[0].Name != tt.wantName) {
+				t.Errorf("ParseTools() = %+v, want one tool named %q", tools, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestSetTools(t *testing.T) {
+	tools := []Tool{{Name: "get_weather"}}
+
+	t.Run("wrapped fallback chain", func(t *testing.T) {
+		primary, fallback := &toolProvider{}, &toolProvider{}
+		chain := NewFallbackProvider([]string{"a:1", "b:2"}, []Provider{primary, fallback}, nil)
+		if err := SetTools(NewRedactingProvider(chain, &Redactor{}, nil), tools); err != nil {
+			t.Fatalf("SetTools() error = %v", err)
+		}
+		if len(primary.tools) != 1 || len(fallback.tools) != 1 {
+			t.Errorf("SetTools() tools = %v, %v, want both set", primary.tools, fallback.tools)
+		}
+	})
+
+	t.Run("unsupported provider in chain", func(t *testing.T) {
+		primary := &toolProvider{}
+		chain := NewFallbackProvider([]string{"a:1", "b:2"}, []Provider{primary, &fakeProvider{}}, nil)
+		if err := SetTools(chain, tools); !errors.Is(err, ErrToolsNotSupported) {
+			t.Errorf("SetTools() error = %v, want ErrToolsNotSupported", err)
+		}
+		if primary.tools != nil {
+			t.Errorf("SetTools() set tools %v on failure, want none", primary.tools)
+		}
+	})
+}
+
+func TestLastToolCalls(t *testing.T) {
+	p := &toolProvider{calls: []ToolCall{{ID: "call_1", Name: "get_weather"}}}
+	if got := LastToolCalls(NewRedactingProvider(p, &Redactor{}, nil)); len(got) != 1 || got[0].ID != "call_1" {
+		t.Errorf("LastToolCalls() = %v, want the calls of the wrapped provider", got)
+	}
+	if got := LastToolCalls(&fakeProvider{}); got != nil {
+		t.Errorf("LastToolCalls() = %v for a provider without tools, want nil", got)
+	}
+}
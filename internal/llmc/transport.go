@@ -0,0 +1,86 @@
+package llmc
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportOptions configures the HTTP transport shared by all providers
+type TransportOptions struct {
+	MaxIdleConns      int           // Maximum number of idle (keep-alive) connections (0 = no limit)
+	IdleConnTimeout   time.Duration // How long an idle connection is kept open (0 = no limit)
+	DisableKeepAlives bool          // Open a new connection for every request
+}
+
+// DefaultTransportOptions are used until ConfigureTransport is called
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConns:    100,
+	IdleConnTimeout: 90 * time.Second,
+}
+
+var (
+	transportMu      sync.Mutex
+	sharedTransport  *http.Transport
+	transportOptions TransportOptions
+
+	transportOverride http.RoundTripper
+)
+
+// SetTransportOverride makes providers send requests with rt instead of the shared transport
+// (e.g. to record or replay them). A nil rt restores the shared transport.
+func SetTransportOverride(rt http.RoundTripper) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	transportOverride = rt
+}
+
+// ProviderTransport returns the transport providers send requests with:
+// the override set by SetTransportOverride, or the shared transport
+func ProviderTransport() http.RoundTripper {
+	transportMu.Lock()
+	override := transportOverride
+	transportMu.Unlock()
+	if override != nil {
+		return override
+	}
+	return SharedTransport()
+}
+
+// SharedTransport returns the HTTP transport shared by all providers,
+// so that connections to a provider are reused across requests
+func SharedTransport() *http.Transport {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	if sharedTransport == nil {
+		sharedTransport = newTransport(DefaultTransportOptions)
+		transportOptions = DefaultTransportOptions
+	}
+	return sharedTransport
+}
+
+// ConfigureTransport replaces the shared transport with one using opts.
+// It does nothing if the shared transport already uses opts, so that its idle connections are kept.
+func ConfigureTransport(opts TransportOptions) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	if sharedTransport != nil && transportOptions == opts {
+		return
+	}
+	if sharedTransport != nil {
+		sharedTransport.CloseIdleConnections()
+	}
+	sharedTransport = newTransport(opts)
+	transportOptions = opts
+}
+
+// newTransport creates a transport with the settings of http.DefaultTransport and opts
+func newTransport(opts TransportOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = opts.MaxIdleConns
+	// Requests usually go to a single provider host
+	t.MaxIdleConnsPerHost = opts.MaxIdleConns
+	t.IdleConnTimeout = opts.IdleConnTimeout
+	t.DisableKeepAlives = opts.DisableKeepAlives
+	return t
+}
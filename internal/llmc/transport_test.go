@@ -0,0 +1,78 @@
+package llmc
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSharedTransportReusesConnections(t *testing.T) {
+	t.Cleanup(func() { ConfigureTransport(DefaultTransportOptions) })
+
+	tests := []struct {
+		name      string
+		opts      TransportOptions
+		wantConns int32
+	}{
+		{name: "keep-alive", opts: DefaultTransportOptions, wantConns: 1},
+		{name: "no keep-alive", opts: TransportOptions{MaxIdleConns: 100, IdleConnTimeout: 90 * time.Second, DisableKeepAlives: true}, wantConns: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conns atomic.Int32
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					conns.Add(1)
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			ConfigureTransport(tt.opts)
+			for i := 0; i < 3; i++ {
+				// A new client per request, as the providers create one per call
+				client := &http.Client{Transport: SharedTransport()}
+				resp, err := client.Get(server.URL)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+
+			if got := conns.Load(); got != tt.wantConns {
+				t.Errorf("connections = %d, want %d", got, tt.wantConns)
+			}
+		})
+	}
+}
+
+func TestConfigureTransportKeepsTransportForSameOptions(t *testing.T) {
+	t.Cleanup(func() { ConfigureTransport(DefaultTransportOptions) })
+
+	ConfigureTransport(DefaultTransportOptions)
+	before := SharedTransport()
+	ConfigureTransport(DefaultTransportOptions)
+	if SharedTransport() != before {
+		t.Error("ConfigureTransport() with the same options replaced the transport")
+	}
+
+	opts := TransportOptions{MaxIdleConns: 10, IdleConnTimeout: time.Minute}
+	ConfigureTransport(opts)
+	got := SharedTransport()
+	if got == before {
+		t.Fatal("ConfigureTransport() with new options kept the transport")
+	}
+	if got.MaxIdleConns != 10 || got.MaxIdleConnsPerHost != 10 || got.IdleConnTimeout != time.Minute || got.DisableKeepAlives {
+		t.Errorf("transport = {MaxIdleConns: %d, MaxIdleConnsPerHost: %d, IdleConnTimeout: %v, DisableKeepAlives: %v}, want options %+v",
+			got.MaxIdleConns, got.MaxIdleConnsPerHost, got.IdleConnTimeout, got.DisableKeepAlives, opts)
+	}
+}
@@ -0,0 +1,38 @@
+package llmc
+
+import "fmt"
+
+// UsageReporter is implemented by providers that report the token usage of their responses
+type UsageReporter interface {
+	// LastUsage returns the token usage of the last successful request (nil if the API did not report it)
+	LastUsage() *Usage
+}
+
+// LastUsage returns the token usage of the last response of provider, looking through
+// wrappers (Unwrap) and, for a fallback chain, at the provider that answered.
+// It returns nil if the usage is not known.
+func LastUsage(provider Provider) *Usage {
+	for provider != nil {
+		if r, ok := provider.(UsageReporter); ok {
+			return r.LastUsage()
+		}
+		wrapper, ok := provider.(interface{ Unwrap() Provider })
+		if !ok {
+			return nil
+		}
+		provider = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// Add adds the token counts of other to u
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// String returns the token counts for display, e.g. "12 input, 34 output (46 total)"
+func (u Usage) String() string {
+	return fmt.Sprintf("%d input, %d output (%d total)", u.InputTokens, u.OutputTokens, u.TotalTokens)
+}
@@ -0,0 +1,49 @@
+package llmc
+
+import "testing"
+
+// usageProvider is a fakeProvider that reports a fixed token usage
+type usageProvider struct {
+	fakeProvider
+	usage *Usage
+}
+
+func (p *usageProvider) LastUsage() *Usage { return p.usage }
+
+func TestLastUsage(t *testing.T) {
+	unavailable := NewAPIError("openai", 503, "", "API request failed (HTTP 503)")
+	primaryUsage, fallbackUsage := &Usage{InputTokens: 1}, &Usage{InputTokens: 2}
+	fallback := NewFallbackProvider(
+		[]string{"openai:gpt-4o", "anthropic:claude-3-5-sonnet"},
+		[]Provider{
+			&usageProvider{fakeProvider: fakeProvider{err: unavailable}, usage: primaryUsage},
+			&usageProvider{fakeProvider: fakeProvider{response: "fallback"}, usage: fallbackUsage},
+		},
+		nil,
+	)
+	if got := LastUsage(fallback); got != nil {
+		t.Errorf("LastUsage() = %v before any request, want nil", got)
+	}
+	if _, err := fallback.Chat("hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		provider Provider
+		want     *Usage
+	}{
+		{name: "not reported", provider: &fakeProvider{}, want: nil},
+		{name: "plain provider", provider: &usageProvider{usage: primaryUsage}, want: primaryUsage},
+		{name: "fallback answered", provider: fallback, want: fallbackUsage},
+		{name: "wrapped fallback", provider: NewRedactingProvider(fallback, &Redactor{}, nil), want: fallbackUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LastUsage(tt.provider); got != tt.want {
+				t.Errorf("LastUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
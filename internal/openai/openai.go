@@ -2,15 +2,19 @@ package openai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
 )
 
 const (
@@ -38,6 +42,11 @@ type ResponsesAPIRequest struct {
 	Instructions string             `json:"instructions,omitempty"` // System-level instructions (optional)
 	Input        interface{}        `json:"input"`                  // string or []InputMessage
 	Tools        []ResponsesAPITool `json:"tools,omitempty"`
+	Stream       bool               `json:"stream,omitempty"` // Stream the response as server-sent events
+
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"top_p,omitempty"`
+	MaxOutputTokens *int     `json:"max_output_tokens,omitempty"`
 }
 
 // InputMessage represents a message in the conversation history
@@ -48,7 +57,12 @@ type InputMessage struct {
 
 // ResponsesAPITool represents a tool configuration
 type ResponsesAPITool struct {
-	Type string `json:"type"`
+	Type string `json:"type"` // "web_search" or "function"
+
+	// Function tools only
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
 }
 
 // ResponsesAPIResponse represents the response from OpenAI's Responses API
@@ -57,6 +71,22 @@ type ResponsesAPIResponse struct {
 	Status string               `json:"status"`
 	Error  *ResponsesAPIError   `json:"error,omitempty"`
 	Output []ResponsesAPIOutput `json:"output"`
+	Usage  *ResponsesAPIUsage   `json:"usage,omitempty"`
+}
+
+// ResponsesAPIUsage represents token usage in the API response
+type ResponsesAPIUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// toUsage converts the usage of a response (nil if not reported)
+func (u *ResponsesAPIUsage) toUsage() *llmc.Usage {
+	if u == nil {
+		return nil
+	}
+	return &llmc.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, TotalTokens: u.TotalTokens}
 }
 
 // ResponsesAPIError represents an error in the API response
@@ -69,6 +99,11 @@ type ResponsesAPIError struct {
 type ResponsesAPIOutput struct {
 	Type    string                `json:"type"`
 	Content []ResponsesAPIContent `json:"content,omitempty"`
+
+	// Function calls only
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"` // JSON-encoded arguments
 }
 
 // ResponsesAPIContent represents content with text and annotations
@@ -89,6 +124,8 @@ type Config interface {
 	GetModel() string
 	GetBaseURL(provider string) (string, error)
 	GetToken(provider string) (string, error)
+	GetRoleMap(provider string) map[string]string
+	FormatTime(t time.Time) string
 }
 
 // Provider implements the llmc.Provider interface for OpenAI
@@ -96,6 +133,11 @@ type Provider struct {
 	config           Config
 	webSearchEnabled bool
 	debug            bool
+	timeout          time.Duration // HTTP request timeout (0 = no timeout)
+	sampling         llmc.SamplingParams
+	lastUsage        *llmc.Usage // Token usage of the last successful request
+	tools            []llmc.Tool
+	lastToolCalls    []llmc.ToolCall // Tool calls of the last successful request
 }
 
 // NewProvider creates a new OpenAI provider instance
@@ -122,7 +164,150 @@ func (p *Provider) SetDebug(enabled bool) {
 	p.debug = enabled
 }
 
-// ListModels returns the list of supported models from the API
+// SetTimeout sets the HTTP request timeout (0 = no timeout)
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// SetSampling sets the sampling parameters
+func (p *Provider) SetSampling(params llmc.SamplingParams) {
+	p.sampling = params
+}
+
+// applySampling sets the configured sampling parameters on the request
+func (p *Provider) applySampling(req *ResponsesAPIRequest) {
+	req.Temperature = p.sampling.Temperature
+	req.TopP = p.sampling.TopP
+	req.MaxOutputTokens = p.sampling.MaxTokens
+}
+
+// LastUsage returns the token usage of the last successful request (nil if not reported)
+func (p *Provider) LastUsage() *llmc.Usage {
+	return p.lastUsage
+}
+
+// SetTools sets the function tools offered to the model
+func (p *Provider) SetTools(tools []llmc.Tool) {
+	p.tools = tools
+}
+
+// LastToolCalls returns the tool calls of the last successful request (nil if the model answered with a message)
+func (p *Provider) LastToolCalls() []llmc.ToolCall {
+	return p.lastToolCalls
+}
+
+// applyTools adds the function tools set with SetTools to req
+func (p *Provider) applyTools(req *ResponsesAPIRequest) {
+	for _, tool := range p.tools {
+		req.Tools = append(req.Tools, ResponsesAPITool{
+			Type:        "function",
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+		})
+	}
+}
+
+// toolCalls returns the function calls in output
+func toolCalls(output []ResponsesAPIOutput) []llmc.ToolCall {
+	var calls []llmc.ToolCall
+	for _, o := range output {
+		if o.Type != "function_call" {
+			continue
+		}
+		arguments := json.RawMessage(o.Arguments)
+		if !json.Valid(arguments) {
+			// Keep malformed arguments as a JSON string so the call can still be printed
+			arguments, _ = json.Marshal(o.Arguments)
+		}
+		calls = append(calls, llmc.ToolCall{ID: o.CallID, Name: o.Name, Arguments: arguments})
+	}
+	return calls
+}
+
+// setAuthHeader sets the bearer token of req. Without a token (e.g. for a local
+// server behind the custom provider) no Authorization header is sent.
+func setAuthHeader(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// httpClient returns an HTTP client configured with the provider's timeout.
+// It uses the shared transport so that connections are reused across requests.
+// In debug mode, the timing of each request is printed.
+func (p *Provider) httpClient() *http.Client {
+	client := &http.Client{Timeout: p.timeout, Transport: llmc.ProviderTransport()}
+	if p.debug {
+		client.Transport = llmc.NewTimingTransport(llmc.ProviderTransport(), func(t llmc.RequestTiming) {
+			fmt.Fprintf(os.Stderr, "Timing: %s\n", t)
+		})
+	}
+	return client
+}
+
+// HealthCheck confirms that the API is reachable and the token is accepted by
+// listing the models, without generating any content
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	setAuthHeader(req, token)
+
+	// No retries: a health check should report the first failure
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		if p.debug {
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+		}
+		return llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+	return llmc.NonJSONResponseError(resp, body, p.debug)
+}
+
+// chatModelPrefixes are the ID prefixes of the model families that can be used with the Responses API
+var chatModelPrefixes = []string{"gpt", "o1", "o3", "o4"}
+
+// nonChatModelMarkers mark the models of the chat families that only serve other endpoints
+// (e.g. gpt-4o-realtime-preview, gpt-4o-mini-tts, gpt-image-1)
+var nonChatModelMarkers = []string{"realtime", "audio", "transcribe", "tts", "image"}
+
+// isChatModel reports whether the model with id can answer chat requests
+func isChatModel(id string) bool {
+	for _, marker := range nonChatModelMarkers {
+		if strings.Contains(id, marker) {
+			return false
+		}
+	}
+	for _, prefix := range chatModelPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListModels returns the list of supported models from the API, newest first
 func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 	// Get token for OpenAI
 	token, err := p.config.GetToken(ProviderName)
@@ -143,12 +328,16 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 	}
 
 	// Set headers
-	req.Header.Set("Authorization", "Bearer "+token)
+	setAuthHeader(req, token)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
+		var timeoutErr *httpretry.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return nil, fmt.Errorf("failed to connect to API: %w", err)
+		}
 		if p.debug {
 			return nil, fmt.Errorf("failed to connect to API: %v", err)
 		}
@@ -164,10 +353,16 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return nil, fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return nil, fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return nil, llmc.NewAPIError(ProviderName, resp.StatusCode, "", message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return nil, err
 	}
 
 	// Parse response
@@ -179,16 +374,28 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 		return nil, fmt.Errorf("failed to parse API response. Use --verbose for details")
 	}
 
+	// Keep only chat models, newest first (by ID for models created at the same time)
+	data := make([]ModelData, 0, len(result.Data))
+	for _, model := range result.Data {
+		if isChatModel(model.ID) {
+			data = append(data, model)
+		}
+	}
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Created != data[j].Created {
+			return data[i].Created > data[j].Created
+		}
+		return data[i].ID > data[j].ID
+	})
+
 	// Convert to ModelInfo format
-	models := make([]llmc.ModelInfo, 0)
+	models := make([]llmc.ModelInfo, 0, len(data))
 
-	for _, model := range result.Data {
+	for _, model := range data {
 		id := model.ID
 
-		// Convert created timestamp to JST and use as description
-		jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-		createdTime := time.Unix(model.Created, 0).In(jst)
-		description := fmt.Sprintf("Created: %s", createdTime.Format("2006-01-02 15:04:05 JST"))
+		// Use created timestamp (in the configured time format and timezone) as description
+		description := fmt.Sprintf("Created: %s", p.config.FormatTime(time.Unix(model.Created, 0)))
 
 		models = append(models, llmc.ModelInfo{
 			ID:          id,
@@ -197,16 +404,16 @@ func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
 		})
 	}
 
-	// Sort models by ID (descending order)
-	sort.Slice(models, func(i, j int) bool {
-		return models[i].ID > models[j].ID
-	})
-
 	return models, nil
 }
 
 // Chat sends a message to OpenAI's Responses API and returns the response
 func (p *Provider) Chat(message string) (string, error) {
+	return p.ChatContext(context.Background(), message)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (p *Provider) ChatContext(ctx context.Context, message string) (string, error) {
 	// Extract model name from provider:model format
 	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
 	if err != nil {
@@ -218,6 +425,7 @@ func (p *Provider) Chat(message string) (string, error) {
 		Model: modelName,
 		Input: message,
 	}
+	p.applySampling(&reqBody)
 
 	// Add web_search tool if enabled
 	if p.webSearchEnabled {
@@ -225,6 +433,7 @@ func (p *Provider) Chat(message string) (string, error) {
 			{Type: "web_search"},
 		}
 	}
+	p.applyTools(&reqBody)
 
 	// Convert request body to JSON
 	jsonData, err := json.Marshal(reqBody)
@@ -245,20 +454,20 @@ func (p *Provider) Chat(message string) (string, error) {
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", baseURL+"/responses", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/responses", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+	setAuthHeader(req, token)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %v", err)
+		return "", fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -270,10 +479,16 @@ func (p *Provider) Chat(message string) (string, error) {
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return "", fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return "", fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return "", llmc.NewAPIError(ProviderName, resp.StatusCode, errorCode(body), message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", err
 	}
 
 	// Parse response
@@ -287,11 +502,12 @@ func (p *Provider) Chat(message string) (string, error) {
 
 	// Check for API error in response
 	if result.Error != nil {
+		message := fmt.Sprintf("API error: %s", result.Error.Message)
 		if p.debug {
-			return "", fmt.Errorf("API error [%s]: %s (id=%s, status=%s)",
+			message = fmt.Sprintf("API error [%s]: %s (id=%s, status=%s)",
 				result.Error.Code, result.Error.Message, result.ID, result.Status)
 		}
-		return "", fmt.Errorf("API error: %s", result.Error.Message)
+		return "", llmc.NewAPIError(ProviderName, 0, result.Error.Code, message)
 	}
 
 	if len(result.Output) == 0 {
@@ -302,6 +518,13 @@ func (p *Provider) Chat(message string) (string, error) {
 		return "", fmt.Errorf("API returned empty response. Use --verbose for details")
 	}
 
+	// Return the tool calls of the model instead of a message
+	p.lastToolCalls = toolCalls(result.Output)
+	if len(p.lastToolCalls) > 0 {
+		p.lastUsage = result.Usage.toUsage()
+		return "", nil
+	}
+
 	// Find the message output (web_search returns multiple outputs)
 	var messageOutput *ResponsesAPIOutput
 	var outputTypes []string
@@ -341,44 +564,21 @@ func (p *Provider) Chat(message string) (string, error) {
 		}
 	}
 
+	p.lastUsage = result.Usage.toUsage()
 	return responseText, nil
 }
 
 // ChatWithHistory sends a conversation history with a new message to OpenAI's Responses API
 func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
-	// Extract model name from provider:model format
-	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
-	if err != nil {
-		return "", fmt.Errorf("invalid model format: %w", err)
-	}
-
-	// Convert messages to InputMessage array
-	inputMessages := make([]InputMessage, 0, len(messages)+1)
-	for _, msg := range messages {
-		inputMessages = append(inputMessages, InputMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
-	}
-
-	// Add new message
-	inputMessages = append(inputMessages, InputMessage{
-		Role:    "user",
-		Content: newMessage,
-	})
+	return p.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
 
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (p *Provider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
 	// Prepare the request body
-	reqBody := ResponsesAPIRequest{
-		Model:        modelName,
-		Instructions: systemPrompt, // Can be empty string
-		Input:        inputMessages,
-	}
-
-	// Add web_search tool if enabled
-	if p.webSearchEnabled {
-		reqBody.Tools = []ResponsesAPITool{
-			{Type: "web_search"},
-		}
+	reqBody, err := p.newHistoryRequest(systemPrompt, messages, newMessage)
+	if err != nil {
+		return "", err
 	}
 
 	// Convert request body to JSON
@@ -400,20 +600,20 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", baseURL+"/responses", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/responses", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+	setAuthHeader(req, token)
 
 	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %v", err)
+		return "", fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -425,10 +625,16 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 
 	// Check for error response
 	if resp.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
 		if p.debug {
-			return "", fmt.Errorf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
 		}
-		return "", fmt.Errorf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		return "", llmc.NewAPIError(ProviderName, resp.StatusCode, errorCode(body), message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return "", err
 	}
 
 	// Parse response
@@ -442,11 +648,12 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 
 	// Check for API error in response
 	if result.Error != nil {
+		message := fmt.Sprintf("API error: %s", result.Error.Message)
 		if p.debug {
-			return "", fmt.Errorf("API error [%s]: %s (id=%s, status=%s)",
+			message = fmt.Sprintf("API error [%s]: %s (id=%s, status=%s)",
 				result.Error.Code, result.Error.Message, result.ID, result.Status)
 		}
-		return "", fmt.Errorf("API error: %s", result.Error.Message)
+		return "", llmc.NewAPIError(ProviderName, 0, result.Error.Code, message)
 	}
 
 	if len(result.Output) == 0 {
@@ -457,6 +664,13 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 		return "", fmt.Errorf("API returned empty response. Use --verbose for details")
 	}
 
+	// Return the tool calls of the model instead of a message
+	p.lastToolCalls = toolCalls(result.Output)
+	if len(p.lastToolCalls) > 0 {
+		p.lastUsage = result.Usage.toUsage()
+		return "", nil
+	}
+
 	// Find the message output (web_search returns multiple outputs)
 	var messageOutput *ResponsesAPIOutput
 	var outputTypes []string
@@ -496,9 +710,60 @@ func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message,
 		}
 	}
 
+	p.lastUsage = result.Usage.toUsage()
 	return responseText, nil
 }
 
+// newHistoryRequest builds a Responses API request from a conversation history and a new message
+func (p *Provider) newHistoryRequest(systemPrompt string, messages []llmc.Message, newMessage string) (ResponsesAPIRequest, error) {
+	// Extract model name from provider:model format
+	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
+	if err != nil {
+		return ResponsesAPIRequest{}, fmt.Errorf("invalid model format: %w", err)
+	}
+
+	// Convert messages to InputMessage array
+	inputMessages := make([]InputMessage, 0, len(messages)+1)
+	for _, msg := range messages {
+		inputMessages = append(inputMessages, InputMessage{
+			Role:    p.wireRole(msg.Role),
+			Content: msg.Content,
+		})
+	}
+
+	// Add new message
+	inputMessages = append(inputMessages, InputMessage{
+		Role:    p.wireRole("user"),
+		Content: newMessage,
+	})
+
+	// Prepare the request body
+	reqBody := ResponsesAPIRequest{
+		Model:        modelName,
+		Instructions: systemPrompt, // Can be empty string
+		Input:        inputMessages,
+	}
+	p.applySampling(&reqBody)
+
+	// Add web_search tool if enabled
+	if p.webSearchEnabled {
+		reqBody.Tools = []ResponsesAPITool{
+			{Type: "web_search"},
+		}
+	}
+	p.applyTools(&reqBody)
+
+	return reqBody, nil
+}
+
+// wireRole returns the role string sent for a message role, as mapped by role_map
+func (p *Provider) wireRole(role string) string {
+	if wireRole, ok := p.config.GetRoleMap(ProviderName)[role]; ok {
+		return wireRole
+	}
+	return role
+}
+
 // extractCitations formats annotations into a citation list
 func extractCitations(annotations []ResponsesAPIAnnotation) string {
 	var citations []string
@@ -524,3 +789,12 @@ func extractCitations(annotations []ResponsesAPIAnnotation) string {
 
 	return strings.Join(citations, "\n")
 }
+
+// errorCode returns the error code from an error response body (empty if the body has none)
+func errorCode(body []byte) string {
+	var errResp ResponsesAPIResponse
+	if json.Unmarshal(body, &errResp) != nil || errResp.Error == nil {
+		return ""
+	}
+	return errResp.Error.Code
+}
@@ -0,0 +1,450 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+// testConfig is a Config for tests that points the provider at a local server
+type testConfig struct {
+	baseURL string
+	roleMap map[string]string
+}
+
+func (c *testConfig) GetModel() string {
+	return "openai:gpt-4.1"
+}
+
+func (c *testConfig) GetBaseURL(provider string) (string, error) {
+	return c.baseURL, nil
+}
+
+func (c *testConfig) GetToken(provider string) (string, error) {
+	return "test-token", nil
+}
+
+func (c *testConfig) GetRoleMap(provider string) map[string]string {
+	return c.roleMap
+}
+
+func (c *testConfig) FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func TestSetTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{name: "no timeout", timeout: 0},
+		{name: "30 seconds", timeout: 30 * time.Second},
+		{name: "2 minutes", timeout: 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(&testConfig{})
+			p.SetTimeout(tt.timeout)
+			if got := p.httpClient().Timeout; got != tt.timeout {
+				t.Errorf("httpClient().Timeout = %v, want %v", got, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestChatTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	p.SetTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := p.Chat("hello")
+	if err == nil {
+		t.Fatal("Chat() error = nil, want timeout error")
+	}
+	if want := "request timed out after 0.05s (increase with --timeout)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Chat() error = %q, want containing %q", err, want)
+	}
+	if llmc.ErrorKindOf(err) != llmc.ErrorKindNetwork {
+		t.Errorf("ErrorKindOf(Chat()) = %v, want ErrorKindNetwork", llmc.ErrorKindOf(err))
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Chat() took %v, want it to fail after the 50ms timeout", elapsed)
+	}
+}
+
+func TestChatContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	start := time.Now()
+	_, err := p.ChatWithHistoryContext(ctx, "", nil, "hello")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ChatWithHistoryContext() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("ChatWithHistoryContext() took %v, want it to return when cancelled", elapsed)
+	}
+	if llmc.IsTemporaryError(err) {
+		t.Error("IsTemporaryError() = true for a cancelled request, want false so that it is not retried or sent to a fallback model")
+	}
+}
+
+func TestNewHistoryRequestAssistantFirst(t *testing.T) {
+	p := NewProvider(&testConfig{})
+	seed := []llmc.Message{{Role: "assistant", Content: "I am a terse code reviewer."}}
+
+	req, err := p.newHistoryRequest("", seed, "Review: x := 1")
+	if err != nil {
+		t.Fatalf("newHistoryRequest() error = %v", err)
+	}
+
+	input, ok := req.Input.([]InputMessage)
+	if !ok {
+		t.Fatalf("newHistoryRequest() input = %T, want []InputMessage", req.Input)
+	}
+	want := []InputMessage{
+		{Role: "assistant", Content: "I am a terse code reviewer."},
+		{Role: "user", Content: "Review: x := 1"},
+	}
+	if len(input) != len(want) {
+		t.Fatalf("newHistoryRequest() input = %v, want %v", input, want)
+	}
+	for i := range want {
+		if input[i].Role != want[i].Role || input[i].Content != want[i].Content {
+			t.Errorf("newHistoryRequest() input[%d] = %+v, want %+v", i, input[i], want[i])
+		}
+	}
+}
+
+func TestChatWithHistoryRoleMap(t *testing.T) {
+	var body struct {
+		Instructions string         `json:"instructions"`
+		Input        []InputMessage `json:"input"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"completed","output":[{"type":"message","content":[{"text":"ok"}]}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		roleMap map[string]string
+		want    []string
+	}{
+		{name: "identity", roleMap: nil, want: []string{"user", "assistant", "user"}},
+		{name: "assistant only", roleMap: map[string]string{"assistant": "bot"}, want: []string{"user", "bot", "user"}},
+		{name: "both", roleMap: map[string]string{"user": "human", "assistant": "ai"}, want: []string{"human", "ai", "human"}},
+	}
+
+	history := []llmc.Message{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(&testConfig{baseURL: server.URL, roleMap: tt.roleMap})
+			if _, err := p.ChatWithHistory("be brief", history, "bye"); err != nil {
+				t.Fatalf("ChatWithHistory() error = %v", err)
+			}
+			var got []string
+			for _, msg := range body.Input {
+				got = append(got, msg.Role)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("request roles = %v, want %v", got, tt.want)
+			}
+			if body.Instructions != "be brief" {
+				t.Errorf("request instructions = %q, want %q", body.Instructions, "be brief")
+			}
+		})
+	}
+}
+
+func TestChatWithHistoryHTMLResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body>Please sign in</body></html>"))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	_, err := p.ChatWithHistory("", nil, "hello")
+	if err == nil {
+		t.Fatal("ChatWithHistory() error = nil, want an error for an HTML response")
+	}
+	if !strings.Contains(err.Error(), "non-JSON response") || strings.Contains(err.Error(), "failed to parse") {
+		t.Errorf("ChatWithHistory() error = %q, want a non-JSON response error", err)
+	}
+}
+
+func TestChatWithHistorySendsHistory(t *testing.T) {
+	var body struct {
+		Instructions string          `json:"instructions"`
+		Input        json.RawMessage `json:"input"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"completed","output":[{"type":"message","content":[{"text":"ok"}]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	history := []llmc.Message{
+		{Role: "user", Content: "What is 2+2?"},
+		{Role: "assistant", Content: "4"},
+		{Role: "user", Content: "And times 3?"},
+		{Role: "assistant", Content: "12"},
+	}
+	if _, err := p.ChatWithHistory("You are a calculator.", history, "Minus 2?"); err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+
+	var input []InputMessage
+	if err := json.Unmarshal(body.Input, &input); err != nil {
+		t.Fatalf("request input = %s, want a message array: %v", body.Input, err)
+	}
+	want := append(history, llmc.Message{Role: "user", Content: "Minus 2?"})
+	if len(input) != len(want) {
+		t.Fatalf("request input has %d messages, want %d: %+v", len(input), len(want), input)
+	}
+	for i := range want {
+		if input[i].Role != want[i].Role || input[i].Content != want[i].Content {
+			t.Errorf("request input[%d] = %+v, want role %q content %q", i, input[i], want[i].Role, want[i].Content)
+		}
+	}
+	if body.Instructions != "You are a calculator." {
+		t.Errorf("request instructions = %q, want the system prompt", body.Instructions)
+	}
+
+	// A one-shot Chat sends the message as plain input
+	if _, err := p.Chat("hello"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if string(body.Input) != `"hello"` {
+		t.Errorf("Chat() request input = %s, want \"hello\"", body.Input)
+	}
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	saved := httpretry.CurrentPolicy()
+	httpretry.Configure(httpretry.Policy{MaxRetries: 3, Backoff: time.Millisecond})
+	defer httpretry.Configure(saved)
+
+	tests := []struct {
+		name string
+		body string
+		call func(p *Provider) error
+	}{
+		{
+			name: "Chat",
+			body: `{"status":"completed","output":[{"type":"message","content":[{"text":"ok"}]}]}`,
+			call: func(p *Provider) error {
+				response, err := p.Chat("hello")
+				if err == nil && response != "ok" {
+					t.Errorf("Chat() = %q, want %q", response, "ok")
+				}
+				return err
+			},
+		},
+		{
+			name: "ListModels",
+			body: `{"data":[{"id":"gpt-4.1","created":0}]}`,
+			call: func(p *Provider) error {
+				models, err := p.ListModels()
+				if err == nil && len(models) != 1 {
+					t.Errorf("ListModels() = %v, want 1 model", models)
+				}
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Two 503 responses followed by a successful one
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewProvider(&testConfig{baseURL: server.URL})
+			if err := tt.call(p); err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if got := atomic.LoadInt32(&attempts); got != 3 {
+				t.Errorf("%s() attempts = %d, want 3", tt.name, got)
+			}
+		})
+	}
+}
+
+func TestLastUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"completed","output":[{"type":"message","content":[{"text":"ok"}]}],"usage":{"input_tokens":12,"output_tokens":3,"total_tokens":15}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	if p.LastUsage() != nil {
+		t.Errorf("LastUsage() = %v before any request, want nil", p.LastUsage())
+	}
+	if _, err := p.ChatWithHistory("", nil, "hello"); err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+	want := llmc.Usage{InputTokens: 12, OutputTokens: 3, TotalTokens: 15}
+	if got := p.LastUsage(); got == nil || *got != want {
+		t.Errorf("LastUsage() = %v, want %v", got, want)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantErr  bool
+		wantKind llmc.ErrorKind
+	}{
+		{name: "healthy", status: http.StatusOK},
+		{name: "invalid token", status: http.StatusUnauthorized, wantErr: true, wantKind: llmc.ErrorKindAuth},
+		{name: "server error", status: http.StatusServiceUnavailable, wantErr: true, wantKind: llmc.ErrorKindUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"data":[]}`))
+			}))
+			defer server.Close()
+
+			p := NewProvider(&testConfig{baseURL: server.URL})
+			err := p.HealthCheck(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HealthCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && llmc.ErrorKindOf(err) != tt.wantKind {
+				t.Errorf("ErrorKindOf(HealthCheck()) = %v, want %v", llmc.ErrorKindOf(err), tt.wantKind)
+			}
+			if gotPath != "/models" || gotAuth != "Bearer test-token" {
+				t.Errorf("request = %s with %q, want /models with the bearer token", gotPath, gotAuth)
+			}
+		})
+	}
+}
+
+func TestListModels(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","data":[
+			{"id":"gpt-4o","object":"model","created":1715367049,"owned_by":"system"},
+			{"id":"text-embedding-3-small","object":"model","created":1705948997,"owned_by":"system"},
+			{"id":"o3-mini","object":"model","created":1737146383,"owned_by":"system"},
+			{"id":"gpt-4o-realtime-preview","object":"model","created":1727659998,"owned_by":"system"},
+			{"id":"dall-e-3","object":"model","created":1698785189,"owned_by":"system"},
+			{"id":"gpt-4.1","object":"model","created":1744316542,"owned_by":"system"},
+			{"id":"o1","object":"model","created":1734375816,"owned_by":"system"}
+		]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	models, err := p.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if gotPath != "/models" || gotAuth != "Bearer test-token" {
+		t.Errorf("request = %s with %q, want /models with the bearer token", gotPath, gotAuth)
+	}
+
+	var ids []string
+	for _, model := range models {
+		ids = append(ids, model.ID)
+	}
+	if want := "gpt-4.1,o3-mini,o1,gpt-4o"; strings.Join(ids, ",") != want {
+		t.Errorf("ListModels() IDs = %v, want the chat models newest first (%s)", ids, want)
+	}
+	if want := "Created: 2025-04-10T20:22:22Z"; models[0].Description != want {
+		t.Errorf("ListModels()[0].Description = %q, want %q", models[0].Description, want)
+	}
+}
+
+func TestChatWithTools(t *testing.T) {
+	var body ResponsesAPIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"completed","output":[{"type":"function_call","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Tokyo\"}"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	p.SetTools([]llmc.Tool{{Name: "get_weather", Description: "Get the weather", Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`)}})
+
+	response, err := p.ChatWithHistory("", nil, "Weather in Tokyo?")
+	if err != nil {
+		t.Fatalf("ChatWithHistory() error = %v, want the tool call without error", err)
+	}
+	if response != "" {
+		t.Errorf("ChatWithHistory() = %q, want an empty response", response)
+	}
+
+	if len(body.Tools) != 1 || body.Tools[0].Type != "function" || body.Tools[0].Name != "get_weather" {
+		t.Fatalf("request tools = %+v, want the get_weather function", body.Tools)
+	}
+	if !strings.Contains(string(body.Tools[0].Parameters), `"city"`) {
+		t.Errorf("request tool parameters = %s, want the schema", body.Tools[0].Parameters)
+	}
+
+	calls := p.LastToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "get_weather" || string(calls[0].Arguments) != `{"city":"Tokyo"}` {
+		t.Errorf("LastToolCalls() = %+v, want the get_weather call", calls)
+	}
+}
@@ -0,0 +1,152 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+// ResponsesStreamEvent represents an event in a streaming Responses API response
+type ResponsesStreamEvent struct {
+	Type     string                `json:"type"`               // e.g., "response.output_text.delta", "response.completed"
+	Delta    string                `json:"delta,omitempty"`    // Text chunk (response.output_text.delta)
+	Response *ResponsesStreamState `json:"response,omitempty"` // Response state (response.completed, response.failed, ...)
+	Code     string                `json:"code,omitempty"`     // Error code (error)
+	Message  string                `json:"message,omitempty"`  // Error message (error)
+}
+
+// ResponsesStreamState represents the response object included in stream events
+type ResponsesStreamState struct {
+	ID                string                      `json:"id"`
+	Status            string                      `json:"status"`
+	Error             *ResponsesAPIError          `json:"error,omitempty"`
+	Usage             *ResponsesAPIUsage          `json:"usage,omitempty"`
+	IncompleteDetails *ResponsesIncompleteDetails `json:"incomplete_details,omitempty"`
+}
+
+// ResponsesIncompleteDetails explains why a response is incomplete
+type ResponsesIncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
+// ChatStream sends a conversation history with a new message to OpenAI's Responses API
+// and streams the response text through onEvent
+func (p *Provider) ChatStream(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string, onEvent func(llmc.StreamEvent) error) (string, error) {
+	// Prepare the request body
+	reqBody, err := p.newHistoryRequest(systemPrompt, messages, newMessage)
+	if err != nil {
+		return "", err
+	}
+	reqBody.Stream = true
+
+	// Convert request body to JSON
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	// Get token for OpenAI
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Get base URL for OpenAI
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/responses", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	setAuthHeader(req, token)
+
+	// Send request
+	client := p.httpClient()
+	resp, err := httpretry.Do(client, req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for error response
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		message := fmt.Sprintf("API request failed (HTTP %d). Use --verbose for details", resp.StatusCode)
+		if p.debug {
+			message = fmt.Sprintf("API request failed (HTTP %d): %s", resp.StatusCode, string(body))
+		}
+		return "", llmc.NewAPIError(ProviderName, resp.StatusCode, errorCode(body), message)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of an empty stream
+	if err := llmc.NonJSONResponseError(resp, nil, p.debug); err != nil {
+		return "", err
+	}
+
+	// Read events
+	var text strings.Builder
+	var usage *llmc.Usage
+	completed := false
+	err = llmc.ReadSSE(resp.Body, func(_ string, data string) error {
+		var event ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			if p.debug {
+				return fmt.Errorf("failed to parse stream event: %v\nRaw event: %s", err, data)
+			}
+			return fmt.Errorf("failed to parse stream event. Use --verbose for details")
+		}
+
+		switch event.Type {
+		case "response.output_text.delta":
+			text.WriteString(event.Delta)
+			return onEvent(llmc.StreamEvent{Type: llmc.StreamEventDelta, Text: event.Delta})
+
+		case "response.completed", "response.incomplete":
+			completed = true
+			done := llmc.StreamEvent{Type: llmc.StreamEventDone, FinishReason: "stop"}
+			if event.Response != nil {
+				if event.Response.IncompleteDetails != nil {
+					done.FinishReason = event.Response.IncompleteDetails.Reason
+				}
+				usage = event.Response.Usage.toUsage()
+				done.Usage = usage
+			}
+			return onEvent(done)
+
+		case "response.failed":
+			if event.Response != nil && event.Response.Error != nil {
+				return llmc.NewAPIError(ProviderName, 0, event.Response.Error.Code, fmt.Sprintf("API error: %s", event.Response.Error.Message))
+			}
+			return fmt.Errorf("API request failed")
+
+		case "error":
+			return llmc.NewAPIError(ProviderName, 0, event.Code, fmt.Sprintf("API error: %s", event.Message))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !completed {
+		return "", fmt.Errorf("stream ended before the response was completed")
+	}
+
+	p.lastUsage = usage
+	return text.String(), nil
+}
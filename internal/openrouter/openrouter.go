@@ -0,0 +1,317 @@
+package openrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/chatcompletions"
+	"github.com/longkey1/llmc/internal/llmc/httpretry"
+)
+
+const (
+	ProviderName   = "openrouter"
+	DefaultBaseURL = "https://openrouter.ai/api/v1"
+	DefaultModel   = "openai/gpt-4o"
+)
+
+// Attribution headers recommended by OpenRouter to identify the calling app
+const (
+	appReferer = "https://github.com/longkey1/llmc"
+	appTitle   = "llmc"
+)
+
+// ModelsAPIResponse represents the response from OpenRouter's models endpoint
+type ModelsAPIResponse struct {
+	Data []ModelData `json:"data"`
+}
+
+// ModelData represents a single model in the API response
+type ModelData struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	ContextLength int     `json:"context_length"`
+	Pricing       Pricing `json:"pricing"`
+}
+
+// Pricing represents the price of a model in USD per token, as decimal strings
+type Pricing struct {
+	Prompt     string `json:"prompt"`
+	Completion string `json:"completion"`
+}
+
+// Config defines the configuration interface for OpenRouter provider
+type Config interface {
+	GetModel() string
+	GetBaseURL(provider string) (string, error)
+	GetToken(provider string) (string, error)
+}
+
+// Provider implements the llmc.Provider interface for OpenRouter
+type Provider struct {
+	config           Config
+	webSearchEnabled bool
+	debug            bool
+	timeout          time.Duration // HTTP request timeout (0 = no timeout)
+	sampling         llmc.SamplingParams
+	lastUsage        *llmc.Usage // Token usage of the last successful request
+}
+
+// NewProvider creates a new OpenRouter provider instance
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config:           config,
+		webSearchEnabled: false,
+		debug:            false,
+	}
+}
+
+// SetWebSearch enables or disables web search
+// Note: web search is not supported by the OpenRouter provider; requests fail while it is enabled
+func (p *Provider) SetWebSearch(enabled bool) {
+	p.webSearchEnabled = enabled
+}
+
+// SetIgnoreWebSearchErrors is a no-op for OpenRouter (not applicable)
+func (p *Provider) SetIgnoreWebSearchErrors(enabled bool) {
+	// Not applicable for OpenRouter
+}
+
+// SetDebug enables or disables debug mode
+func (p *Provider) SetDebug(enabled bool) {
+	p.debug = enabled
+}
+
+// SetTimeout sets the HTTP request timeout (0 = no timeout)
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// SetSampling sets the sampling parameters
+func (p *Provider) SetSampling(params llmc.SamplingParams) {
+	p.sampling = params
+}
+
+// LastUsage returns the token usage of the last successful request (nil if not reported)
+func (p *Provider) LastUsage() *llmc.Usage {
+	return p.lastUsage
+}
+
+// setHeaders sets the bearer token and the attribution headers of req
+func setHeaders(req *http.Request, token string) {
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("HTTP-Referer", appReferer)
+	req.Header.Set("X-Title", appTitle)
+}
+
+// HealthCheck confirms that the API is reachable and the token is accepted by
+// fetching the key's own information, without generating any content
+func (p *Provider) HealthCheck(ctx context.Context) error {
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// The models endpoint needs no authentication, so it cannot verify the token
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/key", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	setHeaders(req, token)
+
+	// No retries: a health check should report the first failure
+	resp, err := chatcompletions.HTTPClient(p.timeout, p.debug).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return chatcompletions.APIError(ProviderName, resp.StatusCode, body, p.debug)
+	}
+	return llmc.NonJSONResponseError(resp, body, p.debug)
+}
+
+// ListModels returns the models available on OpenRouter, described by their prices
+func (p *Provider) ListModels() ([]llmc.ModelInfo, error) {
+	// Get token for OpenRouter
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Get base URL for OpenRouter
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequest("GET", baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	setHeaders(req, token)
+
+	// Send request
+	client := chatcompletions.HTTPClient(p.timeout, p.debug)
+	resp, err := httpretry.Do(client, req)
+	if err != nil {
+		var timeoutErr *httpretry.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return nil, fmt.Errorf("failed to connect to API: %w", err)
+		}
+		if p.debug {
+			return nil, fmt.Errorf("failed to connect to API: %v", err)
+		}
+		return nil, fmt.Errorf("failed to connect to API. Use --verbose for details")
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	// Check for error response
+	if resp.StatusCode != http.StatusOK {
+		return nil, chatcompletions.APIError(ProviderName, resp.StatusCode, body, p.debug)
+	}
+
+	// Report an HTML page (e.g. from a proxy or a login redirect) instead of a parse error
+	if err := llmc.NonJSONResponseError(resp, body, p.debug); err != nil {
+		return nil, err
+	}
+
+	// Parse response
+	var result ModelsAPIResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		if p.debug {
+			return nil, fmt.Errorf("failed to parse API response: %v\nRaw response: %s", err, string(body))
+		}
+		return nil, fmt.Errorf("failed to parse API response. Use --verbose for details")
+	}
+
+	// Convert to ModelInfo format
+	models := make([]llmc.ModelInfo, 0, len(result.Data))
+	for _, model := range result.Data {
+		models = append(models, llmc.ModelInfo{
+			ID:            model.ID,
+			Description:   model.Pricing.String(),
+			IsDefault:     false, // Set by caller
+			ContextWindow: model.ContextLength,
+		})
+	}
+
+	// Sort models by ID (descending order)
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].ID > models[j].ID
+	})
+
+	return models, nil
+}
+
+// String formats the prices per million tokens, e.g. "$3.00/M in, $15.00/M out".
+// It is empty if the prices are not reported.
+func (p Pricing) String() string {
+	prompt, promptErr := strconv.ParseFloat(p.Prompt, 64)
+	completion, completionErr := strconv.ParseFloat(p.Completion, 64)
+	if promptErr != nil || completionErr != nil {
+		return ""
+	}
+	if prompt == 0 && completion == 0 {
+		return "Free"
+	}
+	return fmt.Sprintf("$%.2f/M in, $%.2f/M out", prompt*1e6, completion*1e6)
+}
+
+// Chat sends a message to OpenRouter's Chat Completions API and returns the response
+func (p *Provider) Chat(message string) (string, error) {
+	return p.ChatContext(context.Background(), message)
+}
+
+// ChatContext is like Chat but aborts the request when ctx is cancelled
+func (p *Provider) ChatContext(ctx context.Context, message string) (string, error) {
+	return p.ChatWithHistoryContext(ctx, "", nil, message)
+}
+
+// ChatWithHistory sends a conversation history with a new message to OpenRouter's Chat Completions API
+func (p *Provider) ChatWithHistory(systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	return p.ChatWithHistoryContext(context.Background(), systemPrompt, messages, newMessage)
+}
+
+// ChatWithHistoryContext is like ChatWithHistory but aborts the request when ctx is cancelled
+func (p *Provider) ChatWithHistoryContext(ctx context.Context, systemPrompt string, messages []llmc.Message, newMessage string) (string, error) {
+	reqBody, err := p.newChatRequest(systemPrompt, messages, newMessage)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert request body to JSON
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling request: %v", err)
+	}
+
+	// Get token for OpenRouter
+	token, err := p.config.GetToken(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// Get base URL for OpenRouter
+	baseURL, err := p.config.GetBaseURL(ProviderName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base URL: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setHeaders(req, token)
+
+	// Send request and parse the response
+	response, usage, err := chatcompletions.Send(chatcompletions.HTTPClient(p.timeout, p.debug), req, ProviderName, p.debug)
+	if err != nil {
+		return "", err
+	}
+	p.lastUsage = usage
+	return response, nil
+}
+
+// newChatRequest builds a Chat Completions request from a conversation history and a new message
+func (p *Provider) newChatRequest(systemPrompt string, messages []llmc.Message, newMessage string) (chatcompletions.Request, error) {
+	// Check if web search is enabled (not supported by OpenRouter)
+	if p.webSearchEnabled {
+		return chatcompletions.Request{}, fmt.Errorf("web search is not supported by OpenRouter provider")
+	}
+
+	// Extract model name from provider:model format; the model name itself may
+	// contain slashes (e.g. "anthropic/claude-3.5-sonnet")
+	_, modelName, err := llmc.ParseModelString(p.config.GetModel())
+	if err != nil {
+		return chatcompletions.Request{}, fmt.Errorf("invalid model format: %w", err)
+	}
+
+	return chatcompletions.NewRequest(modelName, systemPrompt, messages, newMessage, p.sampling, nil), nil
+}
@@ -0,0 +1,119 @@
+package openrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/longkey1/llmc/internal/llmc"
+	"github.com/longkey1/llmc/internal/llmc/chatcompletions"
+)
+
+// testConfig is a Config for tests that points the provider at a local server
+type testConfig struct {
+	baseURL string
+}
+
+func (c *testConfig) GetModel() string {
+	return "openrouter:anthropic/claude-3.5-sonnet"
+}
+
+func (c *testConfig) GetBaseURL(provider string) (string, error) {
+	return c.baseURL, nil
+}
+
+func (c *testConfig) GetToken(provider string) (string, error) {
+	return "test-token", nil
+}
+
+func TestChatWithHistory(t *testing.T) {
+	var body chatcompletions.Request
+	var header http.Header
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, header = r.URL.Path, r.Header
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"gen-1","model":"anthropic/claude-3.5-sonnet","choices":[{"message":{"role":"assistant","content":"It is 10."},"finish_reason":"stop"}],"usage":{"prompt_tokens":30,"completion_tokens":4,"total_tokens":34}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	history := []llmc.Message{
+		{Role: "user", Content: "What is 2+3?"},
+		{Role: "assistant", Content: "5"},
+	}
+	response, err := p.ChatWithHistory("You are a calculator.", history, "And times 2?")
+	if err != nil {
+		t.Fatalf("ChatWithHistory() error = %v", err)
+	}
+	if response != "It is 10." {
+		t.Errorf("ChatWithHistory() = %q, want the content of the first choice", response)
+	}
+
+	if path != "/chat/completions" || header.Get("Authorization") != "Bearer test-token" {
+		t.Errorf("request path = %q, authorization = %q, want /chat/completions with a bearer token", path, header.Get("Authorization"))
+	}
+	if header.Get("HTTP-Referer") != appReferer || header.Get("X-Title") != appTitle {
+		t.Errorf("HTTP-Referer = %q, X-Title = %q, want the attribution headers", header.Get("HTTP-Referer"), header.Get("X-Title"))
+	}
+	want := chatcompletions.Request{
+		Model: "anthropic/claude-3.5-sonnet",
+		Messages: []chatcompletions.Message{
+			{Role: "system", Content: "You are a calculator."},
+			{Role: "user", Content: "What is 2+3?"},
+			{Role: "assistant", Content: "5"},
+			{Role: "user", Content: "And times 2?"},
+		},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request = %+v, want %+v", body, want)
+	}
+
+	wantUsage := llmc.Usage{InputTokens: 30, OutputTokens: 4, TotalTokens: 34}
+	if got := p.LastUsage(); got == nil || *got != wantUsage {
+		t.Errorf("LastUsage() = %v, want %v", got, wantUsage)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[
+			{"id":"anthropic/claude-3.5-sonnet","name":"Claude 3.5 Sonnet","context_length":200000,"pricing":{"prompt":"0.000003","completion":"0.000015"}},
+			{"id":"meta-llama/llama-3-8b-instruct:free","context_length":8192,"pricing":{"prompt":"0","completion":"0"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(&testConfig{baseURL: server.URL})
+	models, err := p.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	want := []llmc.ModelInfo{
+		{ID: "meta-llama/llama-3-8b-instruct:free", Description: "Free", ContextWindow: 8192},
+		{ID: "anthropic/claude-3.5-sonnet", Description: "$3.00/M in, $15.00/M out", ContextWindow: 200000},
+	}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("ListModels() = %+v, want %+v", models, want)
+	}
+}
+
+func TestPricingString(t *testing.T) {
+	tests := []struct {
+		pricing Pricing
+		want    string
+	}{
+		{Pricing{Prompt: "0.0000025", Completion: "0.00001"}, "$2.50/M in, $10.00/M out"},
+		{Pricing{Prompt: "0", Completion: "0"}, "Free"},
+		{Pricing{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.pricing.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.pricing, got, tt.want)
+		}
+	}
+}